@@ -0,0 +1,47 @@
+// Command jwt-keygen creates a new private key for signing access tokens and
+// writes it to <dir>/<kid>.pem, printing the key id. See the backend README
+// for how to rotate keys with it.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+func main() {
+	algorithm := flag.String("alg", "EdDSA", "signing algorithm, EdDSA or RS256")
+	dir := flag.String("dir", "keys", "directory to write the key to")
+	kid := flag.String("kid", time.Now().UTC().Format("20060102-150405"), "key id, used as the file name")
+	flag.Parse()
+
+	key, err := utils.GeneratePrivateKey(*algorithm)
+	if err != nil {
+		log.Fatal("Failed to generate key:", err)
+	}
+
+	if err := os.MkdirAll(*dir, 0o700); err != nil {
+		log.Fatal("Failed to create key directory:", err)
+	}
+
+	// O_EXCL keeps an existing key with the same id from being overwritten
+	path := filepath.Join(*dir, *kid+".pem")
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		log.Fatal("Failed to create key file:", err)
+	}
+	if _, err := file.Write(key); err != nil {
+		file.Close()
+		log.Fatal("Failed to write key:", err)
+	}
+	if err := file.Close(); err != nil {
+		log.Fatal("Failed to write key:", err)
+	}
+
+	fmt.Println(*kid)
+}
@@ -0,0 +1,35 @@
+// Command repair-favorites recomputes articles.favorites_count from the
+// favorites table. Run it after restoring data or editing favorites by hand.
+package main
+
+import (
+	"log"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/config"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+)
+
+func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	database, err := db.NewDatabase(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	fixed, err := repository.NewArticleRepository(database).RecomputeFavoritesCounts()
+	if err != nil {
+		log.Fatal("Failed to repair favorites counts:", err)
+	}
+
+	log.Printf("Repaired favorites count of %d articles", fixed)
+}
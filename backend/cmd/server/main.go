@@ -10,9 +10,14 @@ import (
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/config"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/handler"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/oidc"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/scheduler"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 func main() {
@@ -36,12 +41,33 @@ func main() {
 	}
 	log.Println("Database migrations completed successfully")
 
+	// Load the keys access tokens are signed with
+	keys, err := loadSigningKeys(cfg)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+
+	// Set up email delivery
+	mail, err := newMailer(cfg)
+	if err != nil {
+		log.Fatal("Failed to set up mailer:", err)
+	}
+
+	// Set up password hashing
+	hasher, err := utils.NewPasswordHasher(cfg.PasswordParams)
+	if err != nil {
+		log.Fatal("Invalid password hashing configuration:", err)
+	}
+
 	// Create router
 	router := mux.NewRouter()
 
 	// Apply CORS middleware
 	router.Use(middleware.CORS)
 
+	// Take the client address from X-Forwarded-For behind trusted proxies only
+	router.Use(middleware.RealIP(cfg.TrustedProxies))
+
 	// Health check endpoint
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -49,30 +75,70 @@ func main() {
 		fmt.Fprintf(w, `{"status":"ok","service":"realworld-backend"}`)
 	}).Methods("GET")
 
+	// Public keys for other services to verify access tokens with
+	jwksHandler := handler.NewJWKSHandler(keys)
+	router.HandleFunc("/.well-known/jwks.json", jwksHandler.GetJWKS).Methods("GET")
+
 	// Initialize repositories
-	userRepo := repository.NewUserRepository(database.DB)
-	articleRepo := repository.NewArticleRepository(database.DB)
-	tagRepo := repository.NewTagRepository(database.DB)
-	commentRepo := repository.NewCommentRepository(database.DB)
+	userRepo := repository.NewUserRepository(database)
+	articleRepo := repository.NewArticleRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	commentRepo := repository.NewCommentRepository(database)
+	jobRepo := repository.NewJobRepository(database)
+	revisionRepo := repository.NewRevisionRepository(database)
+	articleLoader := repository.NewArticleLoader(database)
+	sessionRepo := repository.NewSessionRepository(database)
+	passwordResetRepo := repository.NewPasswordResetRepository(database)
+	emailVerificationRepo := repository.NewEmailVerificationRepository(database)
+	twoFactorRepo := repository.NewTwoFactorRepository(database)
+	apiTokenRepo := repository.NewAPITokenRepository(database)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(database)
+	oidcRepo := repository.NewOIDCRepository(database)
+	moderationRepo := repository.NewModerationRepository(database)
+	exportRepo := repository.NewExportRepository(database)
 
 	// Initialize services
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(userRepo, passwordResetRepo, emailVerificationRepo, loginAttemptRepo, hasher, mail, cfg.AppURL)
+	sessionService := service.NewSessionService(sessionRepo, userRepo, keys, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	twoFactorService := service.NewTwoFactorService(twoFactorRepo, userRepo, userService)
+	apiTokenService := service.NewAPITokenService(apiTokenRepo, userRepo)
+	verificationPolicy := service.NewEmailVerificationPolicy(userRepo, cfg.VerifiedEmailRequiredFor)
 	tagService := service.NewTagService(tagRepo)
-	articleService := service.NewArticleService(articleRepo, userRepo, tagService)
-	commentService := service.NewCommentService(commentRepo, userRepo)
+	moderationService := service.NewModerationService(moderationRepo)
+	articleService := service.NewArticleService(articleRepo, userRepo, jobRepo, revisionRepo, articleLoader, tagService, verificationPolicy, moderationService)
+	commentService := service.NewCommentService(commentRepo, articleRepo, userRepo, verificationPolicy, moderationService)
 	profileService := service.NewProfileService(userRepo)
+	adminService := service.NewAdminService(userRepo, userService, moderationService)
+	accountService := service.NewAccountService(userRepo, exportRepo, jobRepo, hasher, mail, cfg.AppURL, cfg.AccountDeletionGracePeriod)
+
+	// Start background job scheduler
+	jobScheduler := scheduler.NewScheduler(jobRepo, cfg.JobPollInterval)
+	jobScheduler.Register(model.JobTypePublishArticle, articleService.PublishScheduledArticle)
+	jobScheduler.Register(model.JobTypeDeleteAccount, accountService.DeleteScheduledAccount)
+	jobScheduler.Start()
+	defer jobScheduler.Stop()
 
 	// Initialize handlers
-	authHandler := handler.NewAuthHandler(cfg.JWTSecret)
-	userHandler := handler.NewUserHandler(userService, cfg.JWTSecret)
+	authHandler := handler.NewAuthHandler(userService, sessionService)
+	userHandler := handler.NewUserHandler(userService, sessionService, twoFactorService)
+	twoFactorHandler := handler.NewTwoFactorHandler(twoFactorService, userService, sessionService)
+	apiTokenHandler := handler.NewAPITokenHandler(apiTokenService)
 	articleHandler := handler.NewArticleHandler(articleService)
 	tagHandler := handler.NewTagHandler(tagService)
 	commentHandler := handler.NewCommentHandler(commentService)
 	profileHandler := handler.NewProfileHandler(profileService)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	adminHandler := handler.NewAdminHandler(adminService)
+	accountHandler := handler.NewAccountHandler(accountService)
 
 	// Create JWT middleware
-	jwtMiddleware := middleware.JWTMiddleware(cfg.JWTSecret)
-	optionalJwtMiddleware := middleware.OptionalJWTMiddleware(cfg.JWTSecret)
+	jwtMiddleware := middleware.JWTMiddleware(keys, sessionService, apiTokenService)
+	optionalJwtMiddleware := middleware.OptionalJWTMiddleware(keys, sessionService, apiTokenService)
+
+	// API tokens only reach routes wrapped with the scope they need
+	readScope := middleware.RequireScope(model.ScopeRead)
+	articlesWriteScope := middleware.RequireScope(model.ScopeArticlesWrite)
+	commentsWriteScope := middleware.RequireScope(model.ScopeCommentsWrite)
 
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
@@ -84,47 +150,118 @@ func main() {
 		fmt.Fprintf(w, `{"message":"pong"}`)
 	}).Methods("GET")
 
-	// Auth test endpoints (for development/testing)
-	api.HandleFunc("/auth/test-token", authHandler.GenerateTestToken).Methods("POST")
+	// Auth test endpoint (for development/testing only). It starts a session
+	// without a password, so it is off unless explicitly enabled.
+	if cfg.TestTokensEnabled {
+		log.Println("WARNING: ENABLE_TEST_TOKENS is set; POST /api/auth/test-token issues sessions without a password")
+		api.HandleFunc("/auth/test-token", authHandler.GenerateTestToken).Methods("POST")
+	}
+
+	// Token refresh authenticates with the refresh token in the body
+	api.HandleFunc("/auth/refresh", authHandler.RefreshToken).Methods("POST", "OPTIONS")
+
+	// Logging in through an OpenID Connect issuer, when one is configured
+	if cfg.OIDCIssuerURL != "" {
+		provider := oidc.NewProvider(oidc.Config{
+			IssuerURL:    cfg.OIDCIssuerURL,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+			Scopes:       cfg.OIDCScopes,
+		}, nil)
+		oidcService := service.NewOIDCService(provider, oidcRepo, userRepo, userService)
+		oidcHandler := handler.NewOIDCHandler(oidcService, userService, sessionService, twoFactorService, cfg.AppURL)
+
+		api.HandleFunc("/auth/oidc/login", oidcHandler.Login).Methods("GET")
+		api.HandleFunc("/auth/oidc/callback", oidcHandler.Callback).Methods("GET")
+		api.HandleFunc("/auth/oidc/token", oidcHandler.Token).Methods("POST", "OPTIONS")
+	}
 
 	// RealWorld API endpoints
 	// User registration and authentication
 	api.HandleFunc("/users", userHandler.Register).Methods("POST", "OPTIONS")
 	api.HandleFunc("/users/login", userHandler.Login).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/login/two-factor", twoFactorHandler.CompleteLogin).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/password-reset", userHandler.RequestPasswordReset).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/password-reset/confirm", userHandler.ConfirmPasswordReset).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/account-unlock/confirm", userHandler.ConfirmAccountUnlock).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/email-verification/confirm", userHandler.ConfirmEmailVerification).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/logout", func(w http.ResponseWriter, r *http.Request) {
+		jwtMiddleware(http.HandlerFunc(userHandler.Logout)).ServeHTTP(w, r)
+	}).Methods("POST", "OPTIONS")
 
 	// Protected user endpoints (require authentication)
 	userProtected := api.PathPrefix("/user").Subrouter()
 	userProtected.Use(jwtMiddleware)
-	userProtected.HandleFunc("", userHandler.GetCurrentUser).Methods("GET", "OPTIONS")
+	userProtected.Handle("", readScope(http.HandlerFunc(userHandler.GetCurrentUser))).Methods("GET", "OPTIONS")
 	userProtected.HandleFunc("", userHandler.UpdateUser).Methods("PUT", "OPTIONS")
+	userProtected.HandleFunc("", accountHandler.DeleteAccount).Methods("DELETE", "OPTIONS")
+	userProtected.HandleFunc("/deletion", accountHandler.CancelDeletion).Methods("DELETE", "OPTIONS")
+	userProtected.HandleFunc("/export", accountHandler.Export).Methods("GET", "OPTIONS")
+	userProtected.HandleFunc("/email-verification", userHandler.ResendEmailVerification).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/two-factor", twoFactorHandler.BeginEnrollment).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/two-factor/confirm", twoFactorHandler.ConfirmEnrollment).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/two-factor/disable", twoFactorHandler.Disable).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/two-factor/recovery-codes", twoFactorHandler.RegenerateRecoveryCodes).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/sessions", userHandler.GetSessions).Methods("GET", "OPTIONS")
+	userProtected.HandleFunc("/sessions/{id:[0-9]+}", userHandler.DeleteSession).Methods("DELETE", "OPTIONS")
+	userProtected.HandleFunc("/tokens", apiTokenHandler.GetTokens).Methods("GET", "OPTIONS")
+	userProtected.HandleFunc("/tokens", apiTokenHandler.CreateToken).Methods("POST", "OPTIONS")
+	userProtected.HandleFunc("/tokens/{id:[0-9]+}", apiTokenHandler.DeleteToken).Methods("DELETE", "OPTIONS")
+	userProtected.Handle("/drafts", readScope(http.HandlerFunc(articleHandler.GetDrafts))).Methods("GET", "OPTIONS")
 
 	// Article endpoints
 	// Feed endpoint (requires authentication) - specific route first
 	api.HandleFunc("/articles/feed", func(w http.ResponseWriter, r *http.Request) {
-		jwtMiddleware(http.HandlerFunc(articleHandler.GetArticlesFeed)).ServeHTTP(w, r)
+		jwtMiddleware(readScope(http.HandlerFunc(articleHandler.GetArticlesFeed))).ServeHTTP(w, r)
+	}).Methods("GET", "OPTIONS")
+
+	// Search endpoint (optional auth) - must precede /articles/{slug}
+	api.HandleFunc("/articles/search", func(w http.ResponseWriter, r *http.Request) {
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.SearchArticles))).ServeHTTP(w, r)
 	}).Methods("GET", "OPTIONS")
 
 	// Public article endpoints (optional auth) - general routes
 	api.HandleFunc("/articles", func(w http.ResponseWriter, r *http.Request) {
-		optionalJwtMiddleware(http.HandlerFunc(articleHandler.GetArticles)).ServeHTTP(w, r)
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.GetArticles))).ServeHTTP(w, r)
 	}).Methods("GET", "OPTIONS")
 	api.HandleFunc("/articles/{slug}", func(w http.ResponseWriter, r *http.Request) {
-		optionalJwtMiddleware(http.HandlerFunc(articleHandler.GetArticle)).ServeHTTP(w, r)
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.GetArticle))).ServeHTTP(w, r)
+	}).Methods("GET", "OPTIONS")
+
+	// Article revision history (optional auth)
+	api.HandleFunc("/articles/{slug}/revisions", func(w http.ResponseWriter, r *http.Request) {
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.GetRevisions))).ServeHTTP(w, r)
+	}).Methods("GET", "OPTIONS")
+	api.HandleFunc("/articles/{slug}/revisions/diff", func(w http.ResponseWriter, r *http.Request) {
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.DiffRevisions))).ServeHTTP(w, r)
+	}).Methods("GET", "OPTIONS")
+	api.HandleFunc("/articles/{slug}/revisions/{n:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		optionalJwtMiddleware(readScope(http.HandlerFunc(articleHandler.GetRevision))).ServeHTTP(w, r)
 	}).Methods("GET", "OPTIONS")
 
 	// Article creation and modification (requires authentication)
 	api.HandleFunc("/articles", func(w http.ResponseWriter, r *http.Request) {
-		jwtMiddleware(http.HandlerFunc(articleHandler.CreateArticle)).ServeHTTP(w, r)
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.CreateArticle))).ServeHTTP(w, r)
 	}).Methods("POST", "OPTIONS")
 	api.HandleFunc("/articles/{slug}", func(w http.ResponseWriter, r *http.Request) {
-		jwtMiddleware(http.HandlerFunc(articleHandler.UpdateArticle)).ServeHTTP(w, r)
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.UpdateArticle))).ServeHTTP(w, r)
 	}).Methods("PUT", "OPTIONS")
 	api.HandleFunc("/articles/{slug}", func(w http.ResponseWriter, r *http.Request) {
-		jwtMiddleware(http.HandlerFunc(articleHandler.DeleteArticle)).ServeHTTP(w, r)
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.DeleteArticle))).ServeHTTP(w, r)
+	}).Methods("DELETE", "OPTIONS")
+	api.HandleFunc("/articles/{slug}/publish", func(w http.ResponseWriter, r *http.Request) {
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.PublishArticle))).ServeHTTP(w, r)
+	}).Methods("POST", "OPTIONS")
+	api.HandleFunc("/articles/{slug}/publish", func(w http.ResponseWriter, r *http.Request) {
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.UnpublishArticle))).ServeHTTP(w, r)
 	}).Methods("DELETE", "OPTIONS")
 	api.HandleFunc("/articles/{slug}/favorite", func(w http.ResponseWriter, r *http.Request) {
 		jwtMiddleware(http.HandlerFunc(articleHandler.FavoriteArticle)).ServeHTTP(w, r)
 	}).Methods("POST", "OPTIONS")
+	api.HandleFunc("/articles/{slug}/revisions/{n:[0-9]+}/restore", func(w http.ResponseWriter, r *http.Request) {
+		jwtMiddleware(articlesWriteScope(http.HandlerFunc(articleHandler.RestoreRevision))).ServeHTTP(w, r)
+	}).Methods("POST", "OPTIONS")
 	api.HandleFunc("/articles/{slug}/favorite", func(w http.ResponseWriter, r *http.Request) {
 		jwtMiddleware(http.HandlerFunc(articleHandler.UnfavoriteArticle)).ServeHTTP(w, r)
 	}).Methods("DELETE", "OPTIONS")
@@ -136,13 +273,13 @@ func main() {
 	// Protected comment endpoints (require authentication)
 	commentProtected := api.PathPrefix("/articles/{slug}/comments").Subrouter()
 	commentProtected.Use(jwtMiddleware)
-	commentProtected.HandleFunc("", commentHandler.CreateComment).Methods("POST")
-	commentProtected.HandleFunc("/{id}", commentHandler.DeleteComment).Methods("DELETE")
+	commentProtected.Handle("", commentsWriteScope(http.HandlerFunc(commentHandler.CreateComment))).Methods("POST")
+	commentProtected.Handle("/{id}", commentsWriteScope(http.HandlerFunc(commentHandler.DeleteComment))).Methods("DELETE")
 
 	// Public comment endpoints (optional auth)
 	commentPublic := api.PathPrefix("/articles/{slug}/comments").Subrouter()
 	commentPublic.Use(optionalJwtMiddleware)
-	commentPublic.HandleFunc("", commentHandler.GetComments).Methods("GET")
+	commentPublic.Handle("", readScope(http.HandlerFunc(commentHandler.GetComments))).Methods("GET")
 
 	// Profile endpoints
 	// Protected profile endpoints (require authentication)
@@ -150,17 +287,36 @@ func main() {
 	profileProtected.Use(jwtMiddleware)
 	profileProtected.HandleFunc("/follow", profileHandler.FollowUser).Methods("POST")
 	profileProtected.HandleFunc("/follow", profileHandler.UnfollowUser).Methods("DELETE")
+	profileProtected.HandleFunc("/block", profileHandler.BlockUser).Methods("POST")
+	profileProtected.HandleFunc("/block", profileHandler.UnblockUser).Methods("DELETE")
+	profileProtected.HandleFunc("/mute", profileHandler.MuteUser).Methods("POST")
+	profileProtected.HandleFunc("/mute", profileHandler.UnmuteUser).Methods("DELETE")
 
 	// Public profile endpoints (optional auth)
 	profilePublic := api.PathPrefix("/profiles/{username}").Subrouter()
 	profilePublic.Use(optionalJwtMiddleware)
-	profilePublic.HandleFunc("", profileHandler.GetProfile).Methods("GET")
+	profilePublic.Handle("", readScope(http.HandlerFunc(profileHandler.GetProfile))).Methods("GET")
+
+	// Moderation log (requires the moderation:read permission)
+	moderation := api.PathPrefix("/moderation").Subrouter()
+	moderation.Use(jwtMiddleware, middleware.RequirePermission(model.PermissionModerationRead))
+	moderation.HandleFunc("/actions", moderationHandler.GetActions).Methods("GET", "OPTIONS")
+
+	// User administration (requires the users:manage permission)
+	admin := api.PathPrefix("/admin/users").Subrouter()
+	admin.Use(jwtMiddleware, middleware.RequirePermission(model.PermissionUsersManage))
+	admin.HandleFunc("", adminHandler.GetUsers).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}", adminHandler.GetUser).Methods("GET", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}", adminHandler.DeleteUser).Methods("DELETE", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}/suspension", adminHandler.SuspendUser).Methods("POST", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}/suspension", adminHandler.ReinstateUser).Methods("DELETE", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}/password-reset", adminHandler.ResetPassword).Methods("POST", "OPTIONS")
+	admin.HandleFunc("/{id:[0-9]+}/role", adminHandler.UpdateRole).Methods("PUT", "OPTIONS")
 
 	// Protected auth test endpoints (require authentication)
 	protected := api.PathPrefix("/auth").Subrouter()
 	protected.Use(jwtMiddleware)
 	protected.HandleFunc("/validate", authHandler.ValidateToken).Methods("GET")
-	protected.HandleFunc("/refresh", authHandler.RefreshToken).Methods("POST")
 	protected.HandleFunc("/protected", authHandler.ProtectedEndpoint).Methods("GET")
 
 	// Optional auth endpoints (work with or without auth)
@@ -193,3 +349,32 @@ func main() {
 		log.Fatal("Server failed to start:", err)
 	}
 }
+
+// loadSigningKeys loads the JWT keys from JWT_KEYS_DIR, falling back to a
+// temporary key for local development
+func loadSigningKeys(cfg *config.Config) (*utils.KeySet, error) {
+	if cfg.JWTKeysDir == "" {
+		log.Println("JWT_KEYS_DIR is not set, signing tokens with a temporary key that is lost on restart")
+		return utils.NewEphemeralKeySet()
+	}
+
+	keys, err := utils.LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKeyID)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Signing JWTs with key %q", keys.SigningKeyID())
+
+	return keys, nil
+}
+
+// newMailer creates the mailer selected by MAIL_DRIVER
+func newMailer(cfg *config.Config) (mailer.Mailer, error) {
+	switch cfg.MailDriver {
+	case "smtp":
+		return mailer.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case "file":
+		return mailer.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	default:
+		return mailer.NewLogMailer(cfg.MailFrom), nil
+	}
+}
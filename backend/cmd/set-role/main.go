@@ -0,0 +1,53 @@
+// Command set-role gives a user the user, moderator or admin role, signing
+// them out so that the new role applies from their next login. It is how the
+// first admin is appointed; admins manage roles through the API after that.
+//
+//	go run ./cmd/set-role -email jane@example.com -role moderator
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/config"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+)
+
+func main() {
+	email := flag.String("email", "", "email of the user")
+	role := flag.String("role", "", "role to give: user, moderator or admin")
+	flag.Parse()
+
+	if *email == "" || !model.IsValidRole(*role) {
+		flag.Usage()
+		log.Fatal("-email and a valid -role are required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal("Failed to load configuration:", err)
+	}
+
+	database, err := db.NewDatabase(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+	defer database.Close()
+
+	if err := database.Migrate(); err != nil {
+		log.Fatal("Failed to run migrations:", err)
+	}
+
+	userRepo := repository.NewUserRepository(database)
+	user, err := userRepo.GetByEmail(*email)
+	if err != nil {
+		log.Fatal("Failed to find user:", err)
+	}
+	if err := userRepo.UpdateRole(user.ID, *role); err != nil {
+		log.Fatal("Failed to set role:", err)
+	}
+
+	log.Printf("%s is now %s", user.Username, *role)
+}
@@ -10,3 +10,5 @@ require (
 )
 
 require github.com/lib/pq v1.10.9
+
+require golang.org/x/sys v0.15.0 // indirect
@@ -2,15 +2,78 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 // Config holds the application configuration
 type Config struct {
 	Port        string
 	DatabaseURL string
-	JWTSecret   string
 	Environment string
+
+	// JWTKeysDir is the directory holding the PEM keys access tokens are
+	// signed and verified with
+	JWTKeysDir string
+	// JWTSigningKeyID names the key in JWTKeysDir new tokens are signed with
+	JWTSigningKeyID string
+
+	// AppURL is the frontend URL that links in emails point to
+	AppURL string
+	// MailDriver selects how emails are delivered: smtp, file or log
+	MailDriver string
+	// MailFrom is the sender of emails
+	MailFrom string
+	// MailDir is where the file driver writes emails
+	MailDir      string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+
+	// PasswordParams configures how new password hashes are made. Hashes made
+	// with other parameters are upgraded when their user logs in.
+	PasswordParams utils.PasswordParams
+
+	// OIDCIssuerURL enables logging in through an OpenID Connect issuer
+	OIDCIssuerURL string
+	// OIDCClientID and OIDCClientSecret identify the app at the issuer. The
+	// secret may be empty for public clients, which rely on PKCE alone.
+	OIDCClientID     string
+	OIDCClientSecret string
+	// OIDCRedirectURL is the /api/auth/oidc/callback URL registered with the
+	// issuer
+	OIDCRedirectURL string
+	// OIDCScopes are the scopes requested from the issuer
+	OIDCScopes []string
+
+	// VerifiedEmailRequiredFor lists the actions (publish, comment) only
+	// users with a verified email may take
+	VerifiedEmailRequiredFor []string
+
+	// JobPollInterval is how often the background scheduler looks for due jobs
+	JobPollInterval time.Duration
+	// AccessTokenTTL is how long an access token is valid
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL is how long an unused refresh token stays valid
+	RefreshTokenTTL time.Duration
+	// AccountDeletionGracePeriod is how long after asking for it an account
+	// is deleted, during which the user can change their mind
+	AccountDeletionGracePeriod time.Duration
+
+	// TrustedProxies are the networks of the proxies, such as the load
+	// balancer, whose X-Forwarded-For header is believed
+	TrustedProxies []*net.IPNet
+
+	// TestTokensEnabled exposes POST /api/auth/test-token, which starts a
+	// session for any user by id and email. Only for local development and
+	// tests; it is refused in production.
+	TestTokensEnabled bool
 }
 
 // Load loads configuration from environment variables
@@ -18,8 +81,131 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		Port:        getEnv("PORT", "8080"),
 		DatabaseURL: buildDatabaseURL(),
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
 		Environment: getEnv("ENVIRONMENT", "development"),
+
+		JWTKeysDir:      os.Getenv("JWT_KEYS_DIR"),
+		JWTSigningKeyID: os.Getenv("JWT_SIGNING_KEY_ID"),
+
+		AppURL:       strings.TrimSuffix(getEnv("APP_URL", "http://localhost:5173"), "/"),
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailFrom:     getEnv("MAIL_FROM", "RealWorld <no-reply@realworld.local>"),
+		MailDir:      getEnv("MAIL_DIR", "./mail"),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getEnv("SMTP_PORT", "1025"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+
+		PasswordParams: utils.DefaultPasswordParams(),
+
+		OIDCIssuerURL:    strings.TrimSuffix(os.Getenv("OIDC_ISSUER_URL"), "/"),
+		OIDCClientID:     os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		OIDCRedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+		OIDCScopes:       strings.Fields(getEnv("OIDC_SCOPES", "openid email profile")),
+
+		TestTokensEnabled: os.Getenv("ENABLE_TEST_TOKENS") == "true",
+	}
+	cfg.PasswordParams.Algorithm = getEnv("PASSWORD_HASH_ALGORITHM", cfg.PasswordParams.Algorithm)
+
+	durations := []struct {
+		key      string
+		fallback string
+		target   *time.Duration
+	}{
+		{"JOB_POLL_INTERVAL", "15s", &cfg.JobPollInterval},
+		{"ACCESS_TOKEN_TTL", "15m", &cfg.AccessTokenTTL},
+		{"REFRESH_TOKEN_TTL", "720h", &cfg.RefreshTokenTTL},
+		{"ACCOUNT_DELETION_GRACE_PERIOD", "336h", &cfg.AccountDeletionGracePeriod},
+	}
+	for _, d := range durations {
+		value, err := time.ParseDuration(getEnv(d.key, d.fallback))
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", d.key, os.Getenv(d.key))
+		}
+		*d.target = value
+	}
+
+	// Password hashing costs; NewPasswordHasher checks their ranges
+	costs := []struct {
+		key    string
+		bits   int
+		target func(uint64)
+	}{
+		{"BCRYPT_COST", 8, func(v uint64) { cfg.PasswordParams.BcryptCost = int(v) }},
+		{"ARGON2_MEMORY_KIB", 32, func(v uint64) { cfg.PasswordParams.Argon2Memory = uint32(v) }},
+		{"ARGON2_ITERATIONS", 32, func(v uint64) { cfg.PasswordParams.Argon2Iterations = uint32(v) }},
+		{"ARGON2_PARALLELISM", 8, func(v uint64) { cfg.PasswordParams.Argon2Parallelism = uint8(v) }},
+	}
+	for _, c := range costs {
+		raw := os.Getenv(c.key)
+		if raw == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(raw, 10, c.bits)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %q", c.key, raw)
+		}
+		c.target(value)
+	}
+
+	// Without a keys directory tokens are signed with a key that only lives
+	// as long as the process, which breaks as soon as there are two of them
+	if cfg.Environment == "production" && cfg.JWTKeysDir == "" {
+		return nil, fmt.Errorf("JWT_KEYS_DIR is required in production")
+	}
+
+	// Test tokens skip the password, two-factor and login throttling
+	if cfg.Environment == "production" && cfg.TestTokensEnabled {
+		return nil, fmt.Errorf("ENABLE_TEST_TOKENS is not allowed in production")
+	}
+
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		proxy := strings.TrimSpace(entry)
+		if proxy == "" {
+			continue
+		}
+		// A bare address trusts just that host
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, network, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TRUSTED_PROXIES entry: %q", strings.TrimSpace(entry))
+		}
+		cfg.TrustedProxies = append(cfg.TrustedProxies, network)
+	}
+
+	for _, action := range strings.Split(os.Getenv("REQUIRE_VERIFIED_EMAIL"), ",") {
+		switch action = strings.TrimSpace(action); action {
+		case "":
+		case "publish", "comment":
+			cfg.VerifiedEmailRequiredFor = append(cfg.VerifiedEmailRequiredFor, action)
+		default:
+			return nil, fmt.Errorf("invalid REQUIRE_VERIFIED_EMAIL action: %q", action)
+		}
+	}
+
+	if cfg.OIDCIssuerURL != "" {
+		if cfg.OIDCClientID == "" || cfg.OIDCRedirectURL == "" {
+			return nil, fmt.Errorf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required with OIDC_ISSUER_URL")
+		}
+		hasOpenID := false
+		for _, scope := range cfg.OIDCScopes {
+			hasOpenID = hasOpenID || scope == "openid"
+		}
+		if !hasOpenID {
+			return nil, fmt.Errorf("invalid OIDC_SCOPES: %q must include openid", os.Getenv("OIDC_SCOPES"))
+		}
+	}
+
+	switch cfg.MailDriver {
+	case "smtp", "file", "log":
+	default:
+		return nil, fmt.Errorf("invalid MAIL_DRIVER: %q", cfg.MailDriver)
 	}
 
 	return cfg, nil
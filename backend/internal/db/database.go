@@ -1,29 +1,41 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"strings"
+	"path/filepath"
+	"sync/atomic"
 )
 
-// Database wraps the database connection and provides helper methods
+// Database wraps the database connection and provides helper methods.
+// Exec, Query and QueryRow rebind `?` placeholders for the active dialect,
+// so repositories can share one set of queries across engines.
 type Database struct {
 	*sql.DB
+	dialect          Dialect
 	migrationManager *MigrationManager
+	queries          *atomic.Int64
 }
 
 // NewDatabase creates a new database connection
 func NewDatabase(databaseURL string) (*Database, error) {
+	dialect := DialectForURL(databaseURL)
+
 	var db *sql.DB
 	var err error
 
 	// Determine database type based on URL
-	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+	switch dialect.(type) {
+	case PostgresDialect:
 		// PostgreSQL connection
 		db, err = sql.Open("postgres", databaseURL)
-	} else {
+	default:
 		// SQLite connection (default) - only available in dev/sqlite builds
-		db, err = sql.Open("sqlite3", databaseURL)
+		if !SQLiteFTS5 {
+			return nil, fmt.Errorf("SQLite needs FTS5: build with -tags sqlite_fts5")
+		}
+		db, err = sql.Open(sqliteDriverName, databaseURL)
 	}
 
 	if err != nil {
@@ -35,30 +47,138 @@ func NewDatabase(databaseURL string) (*Database, error) {
 		return nil, fmt.Errorf("failed to ping database: %v", err)
 	}
 
-	// Enable foreign key constraints for SQLite
-	if !strings.HasPrefix(databaseURL, "postgres") {
-		if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-			return nil, fmt.Errorf("failed to enable foreign keys: %v", err)
-		}
-	}
+	// SQLite connections enable foreign key constraints as they are opened,
+	// see drivers_sqlite.go
 
-	migrationManager := NewMigrationManager(db)
+	migrationManager := NewMigrationManager(db, dialect)
 
 	return &Database{
 		DB:               db,
+		dialect:          dialect,
 		migrationManager: migrationManager,
+		queries:          new(atomic.Int64),
 	}, nil
 }
 
+// Dialect returns the SQL dialect of the connected engine
+func (d *Database) Dialect() Dialect {
+	return d.dialect
+}
+
+// QueryCount returns the number of statements run through this Database and
+// its transactions, which makes N+1 query patterns easy to spot in tests
+func (d *Database) QueryCount() int64 {
+	return d.queries.Load()
+}
+
 // Migrate runs database migrations
 func (d *Database) Migrate() error {
-	// Use absolute path for migrations directory
-	migrationsDir := "migrations"
+	return d.MigrateFrom("migrations")
+}
+
+// MigrateFrom runs the migrations for the active dialect found under baseDir
+func (d *Database) MigrateFrom(baseDir string) error {
+	return d.migrationManager.RunMigrations(filepath.Join(baseDir, d.dialect.Name()))
+}
+
+// Exec executes a query without returning any rows
+func (d *Database) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return d.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a query without returning any rows
+func (d *Database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	d.queries.Add(1)
+	return d.DB.ExecContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (d *Database) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return d.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query that returns rows
+func (d *Database) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	d.queries.Add(1)
+	return d.DB.QueryContext(ctx, d.dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row
+func (d *Database) QueryRow(query string, args ...interface{}) *sql.Row {
+	return d.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row
+func (d *Database) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	d.queries.Add(1)
+	return d.DB.QueryRowContext(ctx, d.dialect.Rebind(query), args...)
+}
 
-	return d.migrationManager.RunMigrations(migrationsDir)
+// InsertReturningID runs an INSERT statement and returns the generated id
+func (d *Database) InsertReturningID(query string, args ...interface{}) (int64, error) {
+	return d.dialect.InsertReturningID(d, query, args...)
+}
+
+// Begin starts a transaction
+func (d *Database) Begin() (*Tx, error) {
+	return d.BeginTx(context.Background(), nil)
+}
+
+// BeginTx starts a transaction with the given options
+func (d *Database) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := d.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: d.dialect, queries: d.queries}, nil
 }
 
 // Close closes the database connection
 func (d *Database) Close() error {
 	return d.DB.Close()
 }
+
+// Tx wraps a transaction and rebinds placeholders like Database does
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+	queries *atomic.Int64
+}
+
+// Exec executes a query without returning any rows
+func (t *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return t.ExecContext(context.Background(), query, args...)
+}
+
+// ExecContext executes a query without returning any rows
+func (t *Tx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	t.queries.Add(1)
+	return t.Tx.ExecContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows
+func (t *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return t.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext executes a query that returns rows
+func (t *Tx) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	t.queries.Add(1)
+	return t.Tx.QueryContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that is expected to return at most one row
+func (t *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return t.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext executes a query that is expected to return at most one row
+func (t *Tx) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	t.queries.Add(1)
+	return t.Tx.QueryRowContext(ctx, t.dialect.Rebind(query), args...)
+}
+
+// InsertReturningID runs an INSERT statement and returns the generated id
+func (t *Tx) InsertReturningID(query string, args ...interface{}) (int64, error) {
+	return t.dialect.InsertReturningID(t, query, args...)
+}
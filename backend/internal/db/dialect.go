@@ -0,0 +1,144 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Execer is the subset of Database and Tx used by dialects to run statements
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Dialect hides the SQL differences between the supported database engines.
+// Repository queries are written with `?` placeholders and rewritten by the
+// dialect before they reach the driver.
+type Dialect interface {
+	// Name returns the engine name, which is also the migrations subdirectory
+	Name() string
+	// Rebind rewrites `?` placeholders into the engine's native form
+	Rebind(query string) string
+	// InsertReturningID runs an INSERT statement and returns the new row's id
+	InsertReturningID(e Execer, query string, args ...interface{}) (int64, error)
+	// Upsert builds an INSERT statement that resolves conflicts on the given
+	// columns by updating the listed columns, or ignores the row when update is empty
+	Upsert(table string, columns, conflict, update []string) string
+}
+
+// DialectForURL picks the dialect matching a database URL
+func DialectForURL(databaseURL string) Dialect {
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		return PostgresDialect{}
+	}
+	return SQLiteDialect{}
+}
+
+// SQLiteDialect implements Dialect for SQLite
+type SQLiteDialect struct{}
+
+// Name returns "sqlite"
+func (SQLiteDialect) Name() string {
+	return "sqlite"
+}
+
+// Rebind returns the query unchanged since SQLite understands `?`
+func (SQLiteDialect) Rebind(query string) string {
+	return query
+}
+
+// InsertReturningID executes the insert and reads the id via LastInsertId
+func (SQLiteDialect) InsertReturningID(e Execer, query string, args ...interface{}) (int64, error) {
+	result, err := e.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// Upsert builds an INSERT OR IGNORE or INSERT ... ON CONFLICT DO UPDATE statement
+func (SQLiteDialect) Upsert(table string, columns, conflict, update []string) string {
+	if len(update) == 0 {
+		return fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) VALUES (%s)",
+			table, strings.Join(columns, ", "), placeholders(len(columns)))
+	}
+	return insertOnConflict(table, columns, conflict, update)
+}
+
+// PostgresDialect implements Dialect for PostgreSQL
+type PostgresDialect struct{}
+
+// Name returns "postgres"
+func (PostgresDialect) Name() string {
+	return "postgres"
+}
+
+// Rebind rewrites `?` placeholders into `$1`, `$2`, ... skipping quoted text
+func (PostgresDialect) Rebind(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			fmt.Fprintf(&b, "$%d", n)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// InsertReturningID appends RETURNING id to the insert and scans the result
+func (PostgresDialect) InsertReturningID(e Execer, query string, args ...interface{}) (int64, error) {
+	var id int64
+	query = strings.TrimRight(strings.TrimSpace(query), ";") + " RETURNING id"
+	if err := e.QueryRow(query, args...).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// Upsert builds an INSERT ... ON CONFLICT statement
+func (PostgresDialect) Upsert(table string, columns, conflict, update []string) string {
+	return insertOnConflict(table, columns, conflict, update)
+}
+
+// insertOnConflict builds the ON CONFLICT form shared by SQLite (3.24+) and PostgreSQL
+func insertOnConflict(table string, columns, conflict, update []string) string {
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT",
+		table, strings.Join(columns, ", "), placeholders(len(columns)))
+
+	if len(conflict) > 0 {
+		query += fmt.Sprintf(" (%s)", strings.Join(conflict, ", "))
+	}
+
+	if len(update) == 0 {
+		return query + " DO NOTHING"
+	}
+
+	sets := make([]string, 0, len(update))
+	for _, column := range update {
+		sets = append(sets, fmt.Sprintf("%s = excluded.%s", column, column))
+	}
+	return query + " DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// placeholders returns n comma separated `?` placeholders
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.Repeat("?, ", n-1) + "?"
+}
@@ -0,0 +1,90 @@
+package db
+
+import "testing"
+
+func TestPostgresRebind(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "no placeholders",
+			input:    "SELECT 1",
+			expected: "SELECT 1",
+		},
+		{
+			name:     "sequential placeholders",
+			input:    "SELECT id FROM users WHERE email = ? AND username = ?",
+			expected: "SELECT id FROM users WHERE email = $1 AND username = $2",
+		},
+		{
+			name:     "question mark inside string literal",
+			input:    "SELECT '?' FROM users WHERE id = ?",
+			expected: "SELECT '?' FROM users WHERE id = $1",
+		},
+		{
+			name:     "question mark inside quoted identifier",
+			input:    `SELECT "what?" FROM t LIMIT ? OFFSET ?`,
+			expected: `SELECT "what?" FROM t LIMIT $1 OFFSET $2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := PostgresDialect{}.Rebind(tt.input)
+			if result != tt.expected {
+				t.Errorf("Rebind(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	columns := []string{"article_id", "tag_id"}
+
+	tests := []struct {
+		name     string
+		dialect  Dialect
+		update   []string
+		expected string
+	}{
+		{
+			name:     "sqlite ignore",
+			dialect:  SQLiteDialect{},
+			expected: "INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)",
+		},
+		{
+			name:     "postgres ignore",
+			dialect:  PostgresDialect{},
+			expected: "INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?) ON CONFLICT (article_id, tag_id) DO NOTHING",
+		},
+		{
+			name:     "sqlite update",
+			dialect:  SQLiteDialect{},
+			update:   []string{"tag_id"},
+			expected: "INSERT INTO article_tags (article_id, tag_id) VALUES (?, ?) ON CONFLICT (article_id, tag_id) DO UPDATE SET tag_id = excluded.tag_id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.dialect.Upsert("article_tags", columns, columns, tt.update)
+			if result != tt.expected {
+				t.Errorf("Upsert() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDialectForURL(t *testing.T) {
+	if name := DialectForURL("postgres://u:p@localhost/db").Name(); name != "postgres" {
+		t.Errorf("DialectForURL(postgres://) = %q, want postgres", name)
+	}
+	if name := DialectForURL("postgresql://u:p@localhost/db").Name(); name != "postgres" {
+		t.Errorf("DialectForURL(postgresql://) = %q, want postgres", name)
+	}
+	if name := DialectForURL("realworld.db").Name(); name != "sqlite" {
+		t.Errorf("DialectForURL(realworld.db) = %q, want sqlite", name)
+	}
+}
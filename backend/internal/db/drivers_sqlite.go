@@ -1,5 +1,21 @@
 package db
 
 import (
-	_ "github.com/mattn/go-sqlite3"
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3"
 )
+
+// sqliteDriverName is the SQLite driver with the application's connection settings
+const sqliteDriverName = "sqlite3_realworld"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// Foreign keys are enforced per connection, so every connection
+			// of the pool enables them for deletes to cascade
+			_, err := conn.Exec("PRAGMA foreign_keys = ON", nil)
+			return err
+		},
+	})
+}
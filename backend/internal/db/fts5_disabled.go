@@ -0,0 +1,7 @@
+//go:build !sqlite_fts5 && !fts5
+
+package db
+
+// SQLiteFTS5 reports whether go-sqlite3 was built with FTS5, which the
+// article search index needs
+const SQLiteFTS5 = false
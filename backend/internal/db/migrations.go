@@ -19,12 +19,13 @@ type Migration struct {
 
 // MigrationManager handles database migrations
 type MigrationManager struct {
-	db *sql.DB
+	db      *sql.DB
+	dialect Dialect
 }
 
 // NewMigrationManager creates a new migration manager
-func NewMigrationManager(db *sql.DB) *MigrationManager {
-	return &MigrationManager{db: db}
+func NewMigrationManager(db *sql.DB, dialect Dialect) *MigrationManager {
+	return &MigrationManager{db: db, dialect: dialect}
 }
 
 // CreateMigrationsTable creates the migrations table to track applied migrations
@@ -33,7 +34,7 @@ func (m *MigrationManager) CreateMigrationsTable() error {
 	CREATE TABLE IF NOT EXISTS migrations (
 		id INTEGER PRIMARY KEY,
 		filename TEXT NOT NULL,
-		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 	`
 	_, err := m.db.Exec(query)
@@ -127,7 +128,7 @@ func (m *MigrationManager) ApplyMigration(migration Migration) error {
 	}
 
 	// Record migration as applied
-	if _, err := tx.Exec("INSERT INTO migrations (id, filename) VALUES (?, ?)", migration.ID, migration.Filename); err != nil {
+	if _, err := tx.Exec(m.dialect.Rebind("INSERT INTO migrations (id, filename) VALUES (?, ?)"), migration.ID, migration.Filename); err != nil {
 		return fmt.Errorf("failed to record migration %s: %v", migration.Filename, err)
 	}
 
@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+)
+
+// AccountHandler handles the personal data export and deletion of the
+// account of the current user
+type AccountHandler struct {
+	accountService *service.AccountService
+}
+
+// NewAccountHandler creates a new account handler
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Export handles downloading the personal data of the current user, as a ZIP
+// archive of JSON files or, with format=json, as a single JSON document
+func (h *AccountHandler) Export(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "zip" && format != "json" {
+		http.Error(w, `{"error":"format must be zip or json"}`, http.StatusBadRequest)
+		return
+	}
+
+	export, err := h.accountService.Export(claims.UserID)
+	if err != nil {
+		http.Error(w, `{"error":"Failed to export personal data"}`, http.StatusInternalServerError)
+		return
+	}
+
+	filename := fmt.Sprintf("realworld-%s-%s", export.Profile.Username, export.ExportedAt.Format("2006-01-02"))
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.json"`)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(export)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.zip"`)
+	w.WriteHeader(http.StatusOK)
+	writeExportArchive(w, export)
+}
+
+// writeExportArchive writes an export as a ZIP archive with a JSON file per
+// part. Errors can only be logged by then, as the response has started.
+func writeExportArchive(w http.ResponseWriter, export *model.UserExport) {
+	archive := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"articles.json", export.Articles},
+		{"comments.json", export.Comments},
+		{"favorites.json", export.Favorites},
+		{"following.json", export.Following},
+		{"followers.json", export.Followers},
+	}
+
+	for _, file := range files {
+		f, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return
+		}
+	}
+
+	archive.Close()
+}
+
+// DeleteAccount handles scheduling the deletion of the account of the current
+// user after the grace period
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var req model.DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	deletion, err := h.accountService.ScheduleDeletion(claims.UserID, req)
+	if err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "invalid password":
+			statusCode = http.StatusForbidden
+		case err.Error() == "account deletion already scheduled":
+			statusCode = http.StatusConflict
+		case err.Error() == "password is required" || strings.HasPrefix(err.Error(), "mode must be"):
+			statusCode = http.StatusBadRequest
+		case err.Error() == "user not found":
+			statusCode = http.StatusNotFound
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(model.AccountDeletionResponse{Deletion: *deletion})
+}
+
+// CancelDeletion handles keeping the account of the current user after all
+func (h *AccountHandler) CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(claims.UserID); err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "account deletion not scheduled" {
+			statusCode = http.StatusNotFound
+		}
+		http.Error(w, `{"error":"`+err.Error()+`"}`, statusCode)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Account deletion cancelled"}`))
+}
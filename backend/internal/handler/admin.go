@@ -0,0 +1,260 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+)
+
+// AdminHandler handles the administration of user accounts
+type AdminHandler struct {
+	adminService *service.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// GetUsers handles listing users, optionally searching usernames and emails
+// with the q parameter
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+
+	// Parse limit
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	// Parse offset
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
+			offset = parsed
+		}
+	}
+
+	response, err := h.adminService.ListUsers(query.Get("q"), limit, offset)
+	if err != nil {
+		http.Error(w, `{"error":"Failed to list users"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetUser handles getting a user by ID
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(userID)
+	writeAdminUser(w, user, err)
+}
+
+// SuspendUser handles suspending a user
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	// The reason is optional, so is the body
+	var req model.SuspendUserRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	user, err := h.adminService.SuspendUser(claims, userID, req.Reason)
+	writeAdminUser(w, user, err)
+}
+
+// ReinstateUser handles lifting the suspension of a user
+func (h *AdminHandler) ReinstateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.ReinstateUser(claims, userID)
+	writeAdminUser(w, user, err)
+}
+
+// ResetPassword handles forcing a user to choose a new password
+func (h *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ForcePasswordReset(claims, userID); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Password cleared and reset link sent"}`))
+}
+
+// UpdateRole handles changing the role of a user
+func (h *AdminHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateUserRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.adminService.ChangeRole(claims, userID, req.Role)
+	writeAdminUser(w, user, err)
+}
+
+// DeleteUser handles deleting a user and everything they own
+func (h *AdminHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, ok := adminUserID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.DeleteUser(claims, userID); err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"User deleted successfully"}`))
+}
+
+// adminUserID reads the ID of the user acted on from the path, reporting
+// invalid ones
+func adminUserID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	userID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"Invalid user ID"}`, http.StatusBadRequest)
+		return 0, false
+	}
+	return userID, true
+}
+
+// writeAdminUser writes a user acted on, or the error acting on them
+func writeAdminUser(w http.ResponseWriter, user *model.AdminUser, err error) {
+	if err != nil {
+		writeAdminError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.AdminUserResponse{User: *user})
+}
+
+// writeAdminError maps an error of the admin service to its status code
+func writeAdminError(w http.ResponseWriter, err error) {
+	var statusCode int
+	switch {
+	case err.Error() == "user not found":
+		statusCode = http.StatusNotFound
+	case err.Error() == "cannot administer your own account" || err.Error() == "cannot administer the deleted user account":
+		statusCode = http.StatusForbidden
+	case err.Error() == "user is not suspended":
+		statusCode = http.StatusConflict
+	case err.Error() == "invalid role" || strings.HasPrefix(err.Error(), "reason must"):
+		statusCode = http.StatusBadRequest
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	errorResponse := map[string]interface{}{
+		"error": err.Error(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(errorResponse)
+}
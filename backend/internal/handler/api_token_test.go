@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// createAPIToken creates a personal access token with scopes from a session
+// and returns its value
+func (s *testServer) createAPIToken(t *testing.T, accessToken string, scopes ...string) string {
+	t.Helper()
+
+	var req model.CreateAPITokenRequest
+	req.Token.Name = "ci"
+	req.Token.Scopes = scopes
+
+	rec := s.do(t, http.MethodPost, "/api/user/tokens", "Bearer "+accessToken, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /api/user/tokens = %d %s", rec.Code, rec.Body)
+	}
+
+	var response model.APITokenResponse
+	decode(t, rec, &response)
+	return response.Token.Token
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser(t, "alice")
+	accessToken, _ := s.login(t, alice)
+	reader := "Token " + s.createAPIToken(t, accessToken, model.ScopeRead)
+	writer := "Token " + s.createAPIToken(t, accessToken, model.ScopeArticlesWrite)
+
+	tests := []struct {
+		name          string
+		method, path  string
+		authorization string
+		body          interface{}
+		want          int
+	}{
+		{"read scope reads the user", http.MethodGet, "/api/user", reader, nil, http.StatusOK},
+		{"missing scope", http.MethodGet, "/api/user", writer, nil, http.StatusForbidden},
+		{"unknown token", http.MethodGet, "/api/user", "Token rwpat_unknown", nil, http.StatusUnauthorized},
+
+		// Routes without a scope are for sessions only, whatever a token grants
+		{"update user", http.MethodPut, "/api/user", reader, map[string]interface{}{"user": map[string]string{"bio": "pwned"}}, http.StatusUnauthorized},
+		{"list tokens", http.MethodGet, "/api/user/tokens", reader, nil, http.StatusUnauthorized},
+		{"create token", http.MethodPost, "/api/user/tokens", writer, map[string]interface{}{"token": map[string]interface{}{"name": "more", "scopes": model.APIScopes}}, http.StatusUnauthorized},
+		{"list sessions", http.MethodGet, "/api/user/sessions", reader, nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rec := s.do(t, tt.method, tt.path, tt.authorization, tt.body); rec.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, rec.Code, rec.Body, tt.want)
+			}
+		})
+	}
+
+	// Nothing was changed through the rejected requests
+	rec := s.do(t, http.MethodGet, "/api/user/tokens", "Bearer "+accessToken, nil)
+	var response model.APITokensResponse
+	decode(t, rec, &response)
+	if len(response.Tokens) != 2 {
+		t.Errorf("GET /api/user/tokens listed %d tokens, want 2", len(response.Tokens))
+	}
+	rec = s.do(t, http.MethodGet, "/api/user", "Bearer "+accessToken, nil)
+	var user struct {
+		User model.UserResponse `json:"user"`
+	}
+	decode(t, rec, &user)
+	if user.User.Bio != "" {
+		t.Errorf("bio = %q, want it unchanged", user.User.Bio)
+	}
+}
@@ -3,12 +3,15 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 // ArticleHandler handles article HTTP requests
@@ -51,6 +54,10 @@ func (h *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request) {
 		switch {
 		case err.Error() == "title is required" || err.Error() == "description is required" || err.Error() == "body is required":
 			statusCode = http.StatusBadRequest
+		case err.Error() == "invalid article status":
+			statusCode = http.StatusBadRequest
+		case err.Error() == "email verification required":
+			statusCode = http.StatusForbidden
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -114,6 +121,12 @@ func (h *ArticleHandler) GetArticle(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The article was found under an old slug
+	if article.Slug != slug {
+		redirectToSlug(w, r, slug, article.Slug)
+		return
+	}
+
 	// Prepare response
 	response := model.ArticleResponseWrapper{
 		Article: *article,
@@ -154,7 +167,7 @@ func (h *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Update article
-	article, err := h.articleService.UpdateArticle(slug, req, claims.UserID)
+	article, err := h.articleService.UpdateArticle(slug, req, claims)
 	if err != nil {
 		var statusCode int
 		switch {
@@ -164,6 +177,10 @@ func (h *ArticleHandler) UpdateArticle(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusForbidden
 		case err.Error() == "title cannot be empty" || err.Error() == "description cannot be empty" || err.Error() == "body cannot be empty":
 			statusCode = http.StatusBadRequest
+		case err.Error() == "invalid article status":
+			statusCode = http.StatusBadRequest
+		case err.Error() == "email verification required":
+			statusCode = http.StatusForbidden
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -210,7 +227,7 @@ func (h *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete article
-	err := h.articleService.DeleteArticle(slug, claims.UserID)
+	err := h.articleService.DeleteArticle(slug, claims)
 	if err != nil {
 		var statusCode int
 		switch {
@@ -236,6 +253,117 @@ func (h *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`{"message":"Article deleted successfully"}`))
 }
 
+// PublishArticle handles publishing a draft or unlisted article
+func (h *ArticleHandler) PublishArticle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.changeArticleStatus(w, r, h.articleService.PublishArticle)
+}
+
+// UnpublishArticle handles turning an article back into a draft
+func (h *ArticleHandler) UnpublishArticle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.changeArticleStatus(w, r, h.articleService.UnpublishArticle)
+}
+
+// changeArticleStatus runs a status change for the article in the URL and writes the result
+func (h *ArticleHandler) changeArticleStatus(w http.ResponseWriter, r *http.Request, change func(slug string, actor *utils.Claims) (*model.ArticleResponse, error)) {
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	article, err := change(slug, claims)
+	if err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "article not found":
+			statusCode = http.StatusNotFound
+		case err.Error() == "unauthorized: you can only publish your own articles" || err.Error() == "email verification required":
+			statusCode = http.StatusForbidden
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// Prepare response
+	response := model.ArticleResponseWrapper{
+		Article: *article,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetDrafts handles listing the current user's drafts
+func (h *ArticleHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Authentication required for drafts
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse query parameters
+	params := service.ArticleListParams{}
+
+	// Parse limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			params.Limit = limit
+		}
+	}
+
+	// Parse offset
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			params.Offset = offset
+		}
+	}
+
+	// Get drafts
+	response, err := h.articleService.GetDrafts(params, claims.UserID)
+	if err != nil {
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
 // GetArticles handles global article list retrieval with filtering and pagination
 func (h *ArticleHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -265,6 +393,11 @@ func (h *ArticleHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
 	params.Author = r.URL.Query().Get("author")
 	params.Favorited = r.URL.Query().Get("favorited")
 
+	// Parse ordering
+	params.Sort = r.URL.Query().Get("sort")
+	params.Since = r.URL.Query().Get("since")
+	params.Cursor = r.URL.Query().Get("cursor")
+
 	// Get current user ID (optional for this endpoint)
 	var currentUserID int
 	if claims, ok := middleware.GetUserFromContext(r); ok {
@@ -274,11 +407,19 @@ func (h *ArticleHandler) GetArticles(w http.ResponseWriter, r *http.Request) {
 	// Get articles
 	response, err := h.articleService.GetArticles(params, currentUserID)
 	if err != nil {
+		var statusCode int
+		switch err.Error() {
+		case "invalid sort", "invalid since", "invalid cursor", "cursor requires sort=recent":
+			statusCode = http.StatusBadRequest
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
 		errorResponse := map[string]interface{}{
 			"error": err.Error(),
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode)
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -319,14 +460,80 @@ func (h *ArticleHandler) GetArticlesFeed(w http.ResponseWriter, r *http.Request)
 		}
 	}
 
+	// Parse cursor
+	params.Cursor = r.URL.Query().Get("cursor")
+
 	// Get feed articles
 	response, err := h.articleService.GetArticlesFeed(params, claims.UserID)
 	if err != nil {
+		var statusCode int
+		if err.Error() == "invalid cursor" {
+			statusCode = http.StatusBadRequest
+		} else {
+			statusCode = http.StatusInternalServerError
+		}
+
 		errorResponse := map[string]interface{}{
 			"error": err.Error(),
 		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// SearchArticles handles full-text article search
+func (h *ArticleHandler) SearchArticles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse query parameters
+	params := service.ArticleSearchParams{
+		Query: r.URL.Query().Get("q"),
+	}
+
+	// Parse limit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			params.Limit = limit
+		}
+	}
+
+	// Parse offset
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			params.Offset = offset
+		}
+	}
+
+	// Get current user ID (optional for this endpoint)
+	var currentUserID int
+	if claims, ok := middleware.GetUserFromContext(r); ok {
+		currentUserID = claims.UserID
+	}
+
+	// Search articles
+	response, err := h.articleService.SearchArticles(params, currentUserID)
+	if err != nil {
+		var statusCode int
+		if err.Error() == "search query is required" {
+			statusCode = http.StatusBadRequest
+		} else {
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
 		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
@@ -362,6 +569,8 @@ func (h *ArticleHandler) FavoriteArticle(w http.ResponseWriter, r *http.Request)
 			statusCode = http.StatusNotFound
 		case err.Error() == "article already favorited":
 			statusCode = http.StatusConflict
+		case err.Error() == "blocked by the author":
+			statusCode = http.StatusForbidden
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -417,3 +626,195 @@ func (h *ArticleHandler) UnfavoriteArticle(w http.ResponseWriter, r *http.Reques
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// GetRevisions handles listing an article's revision history
+func (h *ArticleHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get current user ID (optional for this endpoint)
+	var currentUserID int
+	if claims, ok := middleware.GetUserFromContext(r); ok {
+		currentUserID = claims.UserID
+	}
+
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	response, err := h.articleService.GetRevisions(slug, currentUserID)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetRevision handles retrieving a single revision of an article
+func (h *ArticleHandler) GetRevision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get current user ID (optional for this endpoint)
+	var currentUserID int
+	if claims, ok := middleware.GetUserFromContext(r); ok {
+		currentUserID = claims.UserID
+	}
+
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+	n, err := strconv.Atoi(vars["n"])
+	if err != nil || n < 1 {
+		http.Error(w, `{"error":"Invalid revision number"}`, http.StatusBadRequest)
+		return
+	}
+
+	revision, err := h.articleService.GetRevision(slug, n, currentUserID)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+
+	// Prepare response
+	response := model.ArticleRevisionWrapper{
+		Revision: *revision,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DiffRevisions handles a line-level diff between two revisions of an article.
+// ?from= defaults to the revision before ?to=, which defaults to the latest.
+func (h *ArticleHandler) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get current user ID (optional for this endpoint)
+	var currentUserID int
+	if claims, ok := middleware.GetUserFromContext(r); ok {
+		currentUserID = claims.UserID
+	}
+
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+
+	// Parse revision numbers
+	var from, to int
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		n, err := strconv.Atoi(fromStr)
+		if err != nil || n < 1 {
+			http.Error(w, `{"error":"Invalid revision number"}`, http.StatusBadRequest)
+			return
+		}
+		from = n
+	}
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		n, err := strconv.Atoi(toStr)
+		if err != nil || n < 1 {
+			http.Error(w, `{"error":"Invalid revision number"}`, http.StatusBadRequest)
+			return
+		}
+		to = n
+	}
+
+	diff, err := h.articleService.DiffRevisions(slug, from, to, currentUserID)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+
+	// Prepare response
+	response := model.ArticleRevisionDiffWrapper{
+		Diff: *diff,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// RestoreRevision handles restoring an article to one of its revisions
+func (h *ArticleHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	slug := vars["slug"]
+	n, err := strconv.Atoi(vars["n"])
+	if err != nil || n < 1 {
+		http.Error(w, `{"error":"Invalid revision number"}`, http.StatusBadRequest)
+		return
+	}
+
+	article, err := h.articleService.RestoreRevision(slug, n, claims)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+
+	// Prepare response
+	response := model.ArticleResponseWrapper{
+		Article: *article,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeRevisionError maps revision endpoint errors to HTTP responses
+func writeRevisionError(w http.ResponseWriter, err error) {
+	var statusCode int
+	switch {
+	case err.Error() == "article not found" || err.Error() == "revision not found":
+		statusCode = http.StatusNotFound
+	case err.Error() == "unauthorized: you can only restore your own articles":
+		statusCode = http.StatusForbidden
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	errorResponse := map[string]interface{}{
+		"error": err.Error(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(errorResponse)
+}
+
+// redirectToSlug answers a request made with an old article slug with a
+// permanent redirect to the same path under the article's current slug
+func redirectToSlug(w http.ResponseWriter, r *http.Request, oldSlug, newSlug string) {
+	location := strings.Replace(r.URL.EscapedPath(),
+		"/articles/"+url.PathEscape(oldSlug), "/articles/"+url.PathEscape(newSlug), 1)
+	if r.URL.RawQuery != "" {
+		location += "?" + r.URL.RawQuery
+	}
+
+	response := map[string]interface{}{
+		"slug": newSlug,
+	}
+	w.Header().Set("Location", location)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMovedPermanently)
+	json.NewEncoder(w).Encode(response)
+}
@@ -8,18 +8,21 @@ import (
 	"strings"
 
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
-	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
 )
 
 // AuthHandler handles authentication-related endpoints
 type AuthHandler struct {
-	jwtSecret string
+	userService    *service.UserService
+	sessionService *service.SessionService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(jwtSecret string) *AuthHandler {
+func NewAuthHandler(userService *service.UserService, sessionService *service.SessionService) *AuthHandler {
 	return &AuthHandler{
-		jwtSecret: jwtSecret,
+		userService:    userService,
+		sessionService: sessionService,
 	}
 }
 
@@ -31,8 +34,9 @@ type TestTokenRequest struct {
 
 // TokenResponse represents the response for token-related endpoints
 type TokenResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	User         User   `json:"user"`
 }
 
 // User represents a user in responses
@@ -41,7 +45,8 @@ type User struct {
 	Email string `json:"email"`
 }
 
-// GenerateTestToken generates a test JWT token (for development/testing purposes)
+// GenerateTestToken generates a test JWT token (for development/testing
+// purposes). It is only routed when ENABLE_TEST_TOKENS is set.
 func (h *AuthHandler) GenerateTestToken(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
@@ -60,8 +65,15 @@ func (h *AuthHandler) GenerateTestToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// Generate token
-	token, err := utils.GenerateToken(req.UserID, req.Email, h.jwtSecret)
+	// Tokens belong to a session of an existing user
+	user, err := h.userService.GetUserByID(req.UserID)
+	if err != nil || user.Email != req.Email {
+		http.Error(w, `{"error":"User not found"}`, http.StatusNotFound)
+		return
+	}
+
+	// Generate tokens
+	tokens, err := h.sessionService.StartSession(user, clientInfo(r))
 	if err != nil {
 		http.Error(w, fmt.Sprintf(`{"error":"Failed to generate token: %s"}`, err.Error()), http.StatusInternalServerError)
 		return
@@ -69,10 +81,11 @@ func (h *AuthHandler) GenerateTestToken(w http.ResponseWriter, r *http.Request)
 
 	// Prepare response
 	response := TokenResponse{
-		Token: token,
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		User: User{
-			ID:    req.UserID,
-			Email: req.Email,
+			ID:    user.ID,
+			Email: user.Email,
 		},
 	}
 
@@ -111,34 +124,46 @@ func (h *AuthHandler) ValidateToken(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// RefreshToken refreshes the JWT token
+// RefreshToken exchanges a refresh token for a new access token and refresh
+// token. Each refresh token works once; reusing one revokes its session.
 func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Get current user from context
-	claims, ok := middleware.GetUserFromContext(r)
-	if !ok {
-		http.Error(w, `{"error":"No authentication found"}`, http.StatusUnauthorized)
+	var req model.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
 		return
 	}
 
-	// Generate new token
-	newToken, err := utils.GenerateToken(claims.UserID, claims.Email, h.jwtSecret)
+	tokens, err := h.sessionService.Refresh(req.RefreshToken)
 	if err != nil {
-		http.Error(w, fmt.Sprintf(`{"error":"Failed to refresh token: %s"}`, err.Error()), http.StatusInternalServerError)
+		var statusCode int
+		switch err.Error() {
+		case "refresh token is required":
+			statusCode = http.StatusBadRequest
+		case "invalid refresh token", "refresh token expired", "refresh token reused", "session revoked", "user not found":
+			statusCode = http.StatusUnauthorized
+		case "account suspended":
+			statusCode = http.StatusForbidden
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
 		return
 	}
 
-	// Prepare response
-	response := TokenResponse{
-		Token: newToken,
-		User: User{
-			ID:    claims.UserID,
-			Email: claims.Email,
-		},
+	response := map[string]interface{}{
+		"token":        tokens.AccessToken,
+		"refreshToken": tokens.RefreshToken,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// refresh exchanges a refresh token at /api/auth/refresh
+func (s *testServer) refresh(t *testing.T, refreshToken string) (int, map[string]string) {
+	t.Helper()
+
+	rec := s.do(t, http.MethodPost, "/api/auth/refresh", "", model.RefreshTokenRequest{RefreshToken: refreshToken})
+	var response map[string]string
+	decode(t, rec, &response)
+	return rec.Code, response
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser(t, "alice")
+	_, refreshToken := s.login(t, alice)
+
+	// Each refresh hands out a new refresh token for the next one
+	for i := 0; i < 3; i++ {
+		code, response := s.refresh(t, refreshToken)
+		if code != http.StatusOK {
+			t.Fatalf("refresh #%d = %d %v", i+1, code, response)
+		}
+		if response["refreshToken"] == "" || response["refreshToken"] == refreshToken {
+			t.Fatalf("refresh #%d did not rotate the refresh token: %v", i+1, response)
+		}
+		refreshToken = response["refreshToken"]
+
+		// The new access token belongs to the same, still active session
+		if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+response["token"], nil); rec.Code != http.StatusOK {
+			t.Fatalf("GET /api/user with refreshed token = %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	if code, response := s.refresh(t, "not-a-refresh-token"); code != http.StatusUnauthorized || response["error"] != "invalid refresh token" {
+		t.Errorf("refresh with unknown token = %d %v, want 401 invalid refresh token", code, response)
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser(t, "alice")
+	accessToken, stolen := s.login(t, alice)
+
+	// The client refreshes, then the old refresh token shows up again
+	code, rotated := s.refresh(t, stolen)
+	if code != http.StatusOK {
+		t.Fatalf("refresh = %d %v", code, rotated)
+	}
+	if code, response := s.refresh(t, stolen); code != http.StatusUnauthorized || response["error"] != "refresh token reused" {
+		t.Fatalf("refresh with used token = %d %v, want 401 refresh token reused", code, response)
+	}
+
+	// The whole session is revoked: its access tokens and its newest
+	// refresh token stop working
+	for _, token := range []string{accessToken, rotated["token"]} {
+		if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+token, nil); rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET /api/user after reuse = %d %s, want 401", rec.Code, rec.Body)
+		}
+	}
+	if code, response := s.refresh(t, rotated["refreshToken"]); code != http.StatusUnauthorized {
+		t.Errorf("refresh with rotated token after reuse = %d %v, want 401", code, response)
+	}
+
+	// Other sessions of the user are not affected
+	otherToken, _ := s.login(t, alice)
+	if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+otherToken, nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/user with another session = %d %s, want 200", rec.Code, rec.Body)
+	}
+}
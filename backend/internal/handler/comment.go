@@ -42,12 +42,36 @@ func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 		currentUserID = claims.UserID
 	}
 
-	comments, err := h.commentService.GetCommentsByArticleSlug(slug, currentUserID)
+	// Resolve the slug first, so that an old one is answered with the
+	// article's current URL before any comments are listed
+	article, err := h.commentService.ResolveArticle(slug, currentUserID)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if err.Error() == "article not found" {
+			statusCode = http.StatusNotFound
+		}
+		http.Error(w, `{"error":"`+err.Error()+`"}`, statusCode)
+		return
+	}
+	if article.Slug != slug {
+		redirectToSlug(w, r, slug, article.Slug)
+		return
+	}
+
+	// Parse pagination
+	params := service.CommentListParams{Cursor: r.URL.Query().Get("cursor")}
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			params.Limit = limit
+		}
+	}
+
+	response, err := h.commentService.GetCommentsByArticle(article, params, currentUserID)
 	if err != nil {
 		var statusCode int
 		switch err.Error() {
-		case "article not found":
-			statusCode = http.StatusNotFound
+		case "invalid cursor":
+			statusCode = http.StatusBadRequest
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -55,10 +79,6 @@ func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := model.CommentsResponse{
-		Comments: comments,
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
@@ -101,6 +121,8 @@ func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusNotFound
 		case err.Error() == "comment body cannot be empty":
 			statusCode = http.StatusBadRequest
+		case err.Error() == "email verification required" || err.Error() == "blocked by the author":
+			statusCode = http.StatusForbidden
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -140,7 +162,7 @@ func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Delete comment
-	err = h.commentService.DeleteComment(commentID, claims.UserID)
+	err = h.commentService.DeleteComment(commentID, claims)
 	if err != nil {
 		var statusCode int
 		switch {
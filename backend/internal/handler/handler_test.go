@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// migrationsDir is the migrations root relative to this package
+const migrationsDir = "../../migrations"
+
+// testServer routes the account endpoints as cmd/server does, over a freshly
+// migrated SQLite database
+type testServer struct {
+	database  *db.Database
+	router    *mux.Router
+	users     *service.UserService
+	sessions  *service.SessionService
+	twoFactor *service.TwoFactorService
+}
+
+// newTestServer creates the account services with a cheap password hasher and
+// routes their handlers under /api
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.MigrateFrom(migrationsDir); err != nil {
+		t.Fatalf("failed to migrate sqlite: %v", err)
+	}
+
+	keys, err := utils.NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("NewEphemeralKeySet() error = %v", err)
+	}
+	hasher, err := utils.NewPasswordHasher(utils.PasswordParams{
+		Algorithm:         utils.PasswordAlgorithmArgon2id,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewPasswordHasher() error = %v", err)
+	}
+
+	userRepo := repository.NewUserRepository(database)
+	users := service.NewUserService(
+		userRepo,
+		repository.NewPasswordResetRepository(database),
+		repository.NewEmailVerificationRepository(database),
+		repository.NewLoginAttemptRepository(database),
+		hasher,
+		discardMailer{},
+		"http://app.test",
+	)
+	sessions := service.NewSessionService(repository.NewSessionRepository(database), userRepo, keys, 15*time.Minute, 24*time.Hour)
+	twoFactor := service.NewTwoFactorService(repository.NewTwoFactorRepository(database), userRepo, users)
+	apiTokens := service.NewAPITokenService(repository.NewAPITokenRepository(database), userRepo)
+
+	authHandler := NewAuthHandler(users, sessions)
+	userHandler := NewUserHandler(users, sessions, twoFactor)
+	apiTokenHandler := NewAPITokenHandler(apiTokens)
+
+	jwtMiddleware := middleware.JWTMiddleware(keys, sessions, apiTokens)
+	readScope := middleware.RequireScope(model.ScopeRead)
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/auth/refresh", authHandler.RefreshToken).Methods("POST")
+	api.HandleFunc("/users/login", userHandler.Login).Methods("POST")
+	api.Handle("/users/logout", jwtMiddleware(http.HandlerFunc(userHandler.Logout))).Methods("POST")
+
+	userProtected := api.PathPrefix("/user").Subrouter()
+	userProtected.Use(jwtMiddleware)
+	userProtected.Handle("", readScope(http.HandlerFunc(userHandler.GetCurrentUser))).Methods("GET")
+	userProtected.HandleFunc("", userHandler.UpdateUser).Methods("PUT")
+	userProtected.HandleFunc("/sessions", userHandler.GetSessions).Methods("GET")
+	userProtected.HandleFunc("/sessions/{id:[0-9]+}", userHandler.DeleteSession).Methods("DELETE")
+	userProtected.HandleFunc("/tokens", apiTokenHandler.GetTokens).Methods("GET")
+	userProtected.HandleFunc("/tokens", apiTokenHandler.CreateToken).Methods("POST")
+
+	return &testServer{
+		database:  database,
+		router:    router,
+		users:     users,
+		sessions:  sessions,
+		twoFactor: twoFactor,
+	}
+}
+
+// createUser signs up a user with the password "password123"
+func (s *testServer) createUser(t *testing.T, username string) *model.User {
+	t.Helper()
+
+	var req model.CreateUserRequest
+	req.User.Username = username
+	req.User.Email = username + "@example.com"
+	req.User.Password = "password123"
+
+	user, err := s.users.CreateUser(req)
+	if err != nil {
+		t.Fatalf("CreateUser(%s) error = %v", username, err)
+	}
+	return user
+}
+
+// login logs user in with their password and returns the access and refresh
+// token of the new session
+func (s *testServer) login(t *testing.T, user *model.User) (string, string) {
+	t.Helper()
+
+	var req model.LoginRequest
+	req.User.Email = user.Email
+	req.User.Password = "password123"
+
+	rec := s.do(t, http.MethodPost, "/api/users/login", "", req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/users/login = %d %s", rec.Code, rec.Body)
+	}
+
+	var response struct {
+		User model.UserResponse `json:"user"`
+	}
+	decode(t, rec, &response)
+	return response.User.Token, response.User.RefreshToken
+}
+
+// do serves a request with the given Authorization header, when not empty,
+// and body encoded as JSON, when not nil
+func (s *testServer) do(t *testing.T, method, path, authorization string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatalf("failed to encode request body: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, &payload)
+	req.Header.Set("Content-Type", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decode reads the JSON body of a response into v
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body, err)
+	}
+}
+
+// discardMailer drops every message
+type discardMailer struct{}
+
+func (discardMailer) Send(mailer.Message) error { return nil }
@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+)
+
+// oidcStateCookie keeps the state of a login in the browser that started it
+const oidcStateCookie = "oidc_state"
+
+// OIDCHandler handles logging in through an OpenID Connect issuer
+type OIDCHandler struct {
+	oidcService      *service.OIDCService
+	userService      *service.UserService
+	sessionService   *service.SessionService
+	twoFactorService *service.TwoFactorService
+	appURL           string
+}
+
+// NewOIDCHandler creates a new OIDC handler. Browsers are sent back to the
+// /oauth/callback page of the frontend at appURL.
+func NewOIDCHandler(oidcService *service.OIDCService, userService *service.UserService, sessionService *service.SessionService, twoFactorService *service.TwoFactorService, appURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:      oidcService,
+		userService:      userService,
+		sessionService:   sessionService,
+		twoFactorService: twoFactorService,
+		appURL:           appURL,
+	}
+}
+
+// Login handles sending the browser to the issuer to log in. The state of the
+// login is also set as a cookie, which the callback must come back with.
+func (h *OIDCHandler) Login(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	authURL, state, err := h.oidcService.StartLogin()
+	if err != nil {
+		log.Printf("oidc: %v", err)
+		http.Error(w, `{"error":"Identity provider unavailable"}`, http.StatusBadGateway)
+		return
+	}
+
+	h.setStateCookie(w, state, int(service.OIDCStateTTL.Seconds()))
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// Callback handles the browser returning from the issuer. It is sent on to
+// the frontend with a login code to exchange for tokens, or with an error.
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The state cookie is only good for one callback
+	var browserState string
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		browserState = cookie.Value
+	}
+	h.setStateCookie(w, "", -1)
+
+	query := r.URL.Query()
+	if issuerError := query.Get("error"); issuerError != "" {
+		log.Printf("oidc: issuer returned %s: %s", issuerError, query.Get("error_description"))
+		h.redirectToApp(w, r, "error", "access_denied")
+		return
+	}
+
+	code, err := h.oidcService.HandleCallback(query.Get("state"), browserState, query.Get("code"))
+	if err != nil {
+		// Only errors the user can act on are passed to the frontend
+		log.Printf("oidc: %v", err)
+		switch {
+		case err.Error() == "email already exists":
+			h.redirectToApp(w, r, "error", "email_exists")
+		case err.Error() == "login state expired":
+			h.redirectToApp(w, r, "error", "login_expired")
+		case err.Error() == "account suspended":
+			h.redirectToApp(w, r, "error", "account_suspended")
+		default:
+			h.redirectToApp(w, r, "error", "login_failed")
+		}
+		return
+	}
+
+	h.redirectToApp(w, r, "code", code)
+}
+
+// Token handles exchanging a login code for a session, or for a two-factor
+// challenge when the user has two-factor authentication enabled
+func (h *OIDCHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.OIDCTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.oidcService.ExchangeLoginCode(req.Code)
+	if err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "invalid login code" || err.Error() == "login code expired":
+			statusCode = http.StatusUnauthorized
+		case err.Error() == "login code is required":
+			statusCode = http.StatusBadRequest
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	// The issuer stands in for the password, so two-factor authentication
+	// still applies
+	enabled, err := h.twoFactorService.IsEnabled(user.ID)
+	if err != nil {
+		http.Error(w, `{"error":"Failed to check two-factor authentication"}`, http.StatusInternalServerError)
+		return
+	}
+	if enabled {
+		challengeToken, err := h.twoFactorService.StartChallenge(user.ID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to start two-factor login"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(model.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: challengeToken})
+		return
+	}
+
+	// Start a session with an access and refresh token
+	tokens, err := h.sessionService.StartSession(user, clientInfo(r))
+	if err != nil {
+		writeStartSessionError(w, err)
+		return
+	}
+
+	// Prepare response
+	userResponse := h.userService.ToUserResponse(user, tokens.AccessToken)
+	userResponse.RefreshToken = tokens.RefreshToken
+	response := map[string]interface{}{
+		"user": userResponse,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// setStateCookie sets the state cookie for maxAge seconds, or deletes it when
+// maxAge is negative. It is sent back on the redirect from the issuer but not
+// to scripts.
+func (h *OIDCHandler) setStateCookie(w http.ResponseWriter, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     "/api/auth/oidc",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(h.appURL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// redirectToApp sends the browser to the callback page of the frontend with a
+// single query parameter
+func (h *OIDCHandler) redirectToApp(w http.ResponseWriter, r *http.Request, key, value string) {
+	target := strings.TrimSuffix(h.appURL, "/") + "/oauth/callback?" + url.Values{key: {value}}.Encode()
+	http.Redirect(w, r, target, http.StatusFound)
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/oidc"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+)
+
+// mockIssuer is an in-process identity provider that lets every authorization
+// request through for the same identity
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	// codes maps authorization codes to the PKCE challenge and nonce of
+	// their authorization request
+	codes map[string][2]string
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	m := &mockIssuer{t: t, key: key, codes: map[string][2]string{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "test",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		request, ok := m.codes[r.Form.Get("code")]
+		if !ok || oidc.CodeChallenge(r.Form.Get("code_verifier")) != request[0] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(m.codes, r.Form.Get("code"))
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.idToken(request[1])})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// authorize plays the user consenting at the authorization endpoint and
+// returns the callback the browser would be redirected back to
+func (m *mockIssuer) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil || !strings.HasPrefix(authURL, m.server.URL+"/authorize?") {
+		m.t.Fatalf("unexpected authorization URL %q", authURL)
+	}
+	q := u.Query()
+
+	code := "code-" + q.Get("state")
+	m.codes[code] = [2]string{q.Get("code_challenge"), q.Get("nonce")}
+	return q.Get("redirect_uri") + "?" + url.Values{"state": {q.Get("state")}, "code": {code}}.Encode()
+}
+
+func (m *mockIssuer) idToken(nonce string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":                m.server.URL,
+		"sub":                "user-123",
+		"aud":                "realworld",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"email":              "jane@example.com",
+		"email_verified":     true,
+		"preferred_username": "jane.doe",
+	})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+// routeOIDC routes logging in through issuer as cmd/server does
+func (s *testServer) routeOIDC(issuer *mockIssuer) {
+	provider := oidc.NewProvider(oidc.Config{
+		IssuerURL:   issuer.server.URL,
+		ClientID:    "realworld",
+		RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}, issuer.server.Client())
+	oidcService := service.NewOIDCService(provider, repository.NewOIDCRepository(s.database), repository.NewUserRepository(s.database), s.users)
+	oidcHandler := NewOIDCHandler(oidcService, s.users, s.sessions, s.twoFactor, "http://app.test")
+
+	api := s.router.PathPrefix("/api").Subrouter()
+	api.HandleFunc("/auth/oidc/login", oidcHandler.Login).Methods("GET")
+	api.HandleFunc("/auth/oidc/callback", oidcHandler.Callback).Methods("GET")
+	api.HandleFunc("/auth/oidc/token", oidcHandler.Token).Methods("POST")
+}
+
+// startOIDCLogin starts a login and returns the issuer URL it redirects to
+// and the state cookie it sets
+func (s *testServer) startOIDCLogin(t *testing.T) (string, *http.Cookie) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("GET /api/auth/oidc/login = %d %s", rec.Code, rec.Body)
+	}
+
+	for _, cookie := range rec.Result().Cookies() {
+		if cookie.Name == oidcStateCookie {
+			return rec.Header().Get("Location"), cookie
+		}
+	}
+	t.Fatalf("GET /api/auth/oidc/login set no %s cookie", oidcStateCookie)
+	return "", nil
+}
+
+// oidcCallback follows the redirect back from the issuer with cookie, when
+// not nil, and returns the query the browser is sent on to the app with
+func (s *testServer) oidcCallback(t *testing.T, callbackURL string, cookie *http.Cookie) url.Values {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, callbackURL, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusFound {
+		t.Fatalf("GET /api/auth/oidc/callback = %d %s", rec.Code, rec.Body)
+	}
+
+	// The state cookie is cleared either way
+	cleared := false
+	for _, c := range rec.Result().Cookies() {
+		cleared = cleared || (c.Name == oidcStateCookie && c.MaxAge < 0)
+	}
+	if !cleared {
+		t.Errorf("GET /api/auth/oidc/callback did not clear the %s cookie", oidcStateCookie)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil || !strings.HasPrefix(location.String(), "http://app.test/oauth/callback?") {
+		t.Fatalf("GET /api/auth/oidc/callback redirected to %q", rec.Header().Get("Location"))
+	}
+	return location.Query()
+}
+
+func TestOIDCLogin(t *testing.T) {
+	s := newTestServer(t)
+	issuer := newMockIssuer(t)
+	s.routeOIDC(issuer)
+
+	authURL, cookie := s.startOIDCLogin(t)
+	if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/api/auth/oidc" || cookie.MaxAge <= 0 {
+		t.Errorf("state cookie = %+v, want a short-lived HttpOnly SameSite=Lax cookie on /api/auth/oidc", cookie)
+	}
+
+	query := s.oidcCallback(t, issuer.authorize(authURL), cookie)
+	if query.Get("code") == "" {
+		t.Fatalf("callback sent the app %v, want a login code", query)
+	}
+
+	// The app exchanges the login code for a session, once
+	body := model.OIDCTokenRequest{Code: query.Get("code")}
+	rec := s.do(t, http.MethodPost, "/api/auth/oidc/token", "", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/auth/oidc/token = %d %s", rec.Code, rec.Body)
+	}
+	var response struct {
+		User model.UserResponse `json:"user"`
+	}
+	decode(t, rec, &response)
+	if response.User.Email != "jane@example.com" || response.User.Username != "jane_doe" || response.User.RefreshToken == "" {
+		t.Errorf("POST /api/auth/oidc/token user = %+v", response.User)
+	}
+	if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+response.User.Token, nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/user with OIDC session = %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := s.do(t, http.MethodPost, "/api/auth/oidc/token", "", body); rec.Code != http.StatusUnauthorized {
+		t.Errorf("POST /api/auth/oidc/token with used code = %d %s, want 401", rec.Code, rec.Body)
+	}
+}
+
+func TestOIDCCallbackRequiresStateCookie(t *testing.T) {
+	s := newTestServer(t)
+	issuer := newMockIssuer(t)
+	s.routeOIDC(issuer)
+
+	// An attacker starts a login and hands their callback URL to a victim
+	// whose browser has no cookie, or the cookie of its own login
+	authURL, cookie := s.startOIDCLogin(t)
+	callbackURL := issuer.authorize(authURL)
+	_, victimCookie := s.startOIDCLogin(t)
+
+	for name, c := range map[string]*http.Cookie{"no cookie": nil, "other login": victimCookie} {
+		if query := s.oidcCallback(t, callbackURL, c); query.Get("code") != "" || query.Get("error") != "login_failed" {
+			t.Errorf("callback with %s sent the app %v, want error login_failed", name, query)
+		}
+	}
+
+	// The browser that started the login can still finish it
+	if query := s.oidcCallback(t, callbackURL, cookie); query.Get("code") == "" {
+		t.Errorf("callback with matching cookie sent the app %v, want a login code", query)
+	}
+}
@@ -3,9 +3,11 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
 )
 
@@ -85,6 +87,8 @@ func (h *ProfileHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
 			statusCode = http.StatusBadRequest
 		case err.Error() == "already following this user":
 			statusCode = http.StatusConflict
+		case err.Error() == "blocked by this user" || err.Error() == "cannot follow a user you blocked":
+			statusCode = http.StatusForbidden
 		default:
 			statusCode = http.StatusInternalServerError
 		}
@@ -138,3 +142,63 @@ func (h *ProfileHandler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
+
+// BlockUser handles blocking a user
+func (h *ProfileHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRelationship(w, r, http.MethodPost, h.profileService.BlockUser)
+}
+
+// UnblockUser handles lifting a block
+func (h *ProfileHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRelationship(w, r, http.MethodDelete, h.profileService.UnblockUser)
+}
+
+// MuteUser handles muting a user
+func (h *ProfileHandler) MuteUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRelationship(w, r, http.MethodPost, h.profileService.MuteUser)
+}
+
+// UnmuteUser handles lifting a mute
+func (h *ProfileHandler) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	h.changeRelationship(w, r, http.MethodDelete, h.profileService.UnmuteUser)
+}
+
+// changeRelationship handles the block and mute endpoints, applying change
+// from the current user to the user in the path
+func (h *ProfileHandler) changeRelationship(w http.ResponseWriter, r *http.Request, method string, change func(int, string) (*model.ProfileResponse, error)) {
+	if r.Method != method {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	profile, err := change(claims.UserID, mux.Vars(r)["username"])
+	if err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "user not found: user not found" || strings.HasSuffix(err.Error(), "relationship not found"):
+			statusCode = http.StatusNotFound
+		case err.Error() == "cannot block yourself" || err.Error() == "cannot mute yourself":
+			statusCode = http.StatusBadRequest
+		case err.Error() == "already blocking this user" || err.Error() == "already muting this user":
+			statusCode = http.StatusConflict
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+		http.Error(w, `{"error":"`+err.Error()+`"}`, statusCode)
+		return
+	}
+
+	response := ProfileResponse{
+		Profile: profile,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
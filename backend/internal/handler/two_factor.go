@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
+)
+
+// TwoFactorHandler handles two-factor enrollment and the second step of
+// logging in
+type TwoFactorHandler struct {
+	twoFactorService *service.TwoFactorService
+	userService      *service.UserService
+	sessionService   *service.SessionService
+}
+
+// NewTwoFactorHandler creates a new two-factor handler
+func NewTwoFactorHandler(twoFactorService *service.TwoFactorService, userService *service.UserService, sessionService *service.SessionService) *TwoFactorHandler {
+	return &TwoFactorHandler{
+		twoFactorService: twoFactorService,
+		userService:      userService,
+		sessionService:   sessionService,
+	}
+}
+
+// CompleteLogin handles exchanging a login challenge and a code for a session
+func (h *TwoFactorHandler) CompleteLogin(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.TwoFactorLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.twoFactorService.CompleteChallenge(req.ChallengeToken, req.Code, clientInfo(r).IPAddress)
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			http.Error(w, `{"error":"Too many login attempts, try again later"}`, http.StatusTooManyRequests)
+			return
+		}
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	// Start a session with an access and refresh token
+	tokens, err := h.sessionService.StartSession(user, clientInfo(r))
+	if err != nil {
+		writeStartSessionError(w, err)
+		return
+	}
+
+	// Prepare response
+	userResponse := h.userService.ToUserResponse(user, tokens.AccessToken)
+	userResponse.RefreshToken = tokens.RefreshToken
+	response := map[string]interface{}{
+		"user": userResponse,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// BeginEnrollment handles creating a TOTP secret for the current user
+func (h *TwoFactorHandler) BeginEnrollment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.twoFactorService.BeginEnrollment(claims.UserID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ConfirmEnrollment handles enabling two-factor authentication with a first
+// code, answering with the recovery codes
+func (h *TwoFactorHandler) ConfirmEnrollment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactorService.ConfirmEnrollment(claims.UserID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable handles turning two-factor authentication off with a code
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.twoFactorService.Disable(claims.UserID, req.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Two-factor authentication disabled"}`))
+}
+
+// RegenerateRecoveryCodes handles replacing the recovery codes of the current
+// user with a code
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	// Parse request body
+	var req model.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactorService.RegenerateRecoveryCodes(claims.UserID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// writeTwoFactorError writes the error response of a two-factor operation
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	var statusCode int
+	switch {
+	case err.Error() == "invalid two-factor code" || err.Error() == "invalid challenge token" || err.Error() == "challenge token expired":
+		statusCode = http.StatusUnauthorized
+	case err.Error() == "two-factor authentication already enabled" || err.Error() == "two-factor authentication not enabled":
+		statusCode = http.StatusConflict
+	case err.Error() == "user not found" || err.Error() == "two-factor authentication not set up":
+		statusCode = http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "failed to"):
+		statusCode = http.StatusInternalServerError
+	default:
+		statusCode = http.StatusBadRequest
+	}
+
+	errorResponse := map[string]interface{}{
+		"error": err.Error(),
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(errorResponse)
+}
@@ -2,25 +2,32 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 
+	"github.com/gorilla/mux"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/middleware"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/service"
-	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 // UserHandler handles user-related HTTP requests
 type UserHandler struct {
-	userService *service.UserService
-	jwtSecret   string
+	userService      *service.UserService
+	sessionService   *service.SessionService
+	twoFactorService *service.TwoFactorService
 }
 
 // NewUserHandler creates a new user handler
-func NewUserHandler(userService *service.UserService, jwtSecret string) *UserHandler {
+func NewUserHandler(userService *service.UserService, sessionService *service.SessionService, twoFactorService *service.TwoFactorService) *UserHandler {
 	return &UserHandler{
-		userService: userService,
-		jwtSecret:   jwtSecret,
+		userService:      userService,
+		sessionService:   sessionService,
+		twoFactorService: twoFactorService,
 	}
 }
 
@@ -59,15 +66,16 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, h.jwtSecret)
+	// Start a session with an access and refresh token
+	tokens, err := h.sessionService.StartSession(user, clientInfo(r))
 	if err != nil {
-		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
+		writeStartSessionError(w, err)
 		return
 	}
 
 	// Prepare response
-	userResponse := h.userService.ToUserResponse(user, token)
+	userResponse := h.userService.ToUserResponse(user, tokens.AccessToken)
+	userResponse.RefreshToken = tokens.RefreshToken
 	response := map[string]interface{}{
 		"user": userResponse,
 	}
@@ -98,21 +106,53 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Authenticate user
-	user, err := h.userService.AuthenticateUser(req.User.Email, req.User.Password)
+	user, err := h.userService.AuthenticateUser(req.User.Email, req.User.Password, clientInfo(r).IPAddress)
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			http.Error(w, `{"error":"Too many login attempts, try again later"}`, http.StatusTooManyRequests)
+			return
+		}
+		if err.Error() == "account suspended" {
+			http.Error(w, `{"error":"Account suspended"}`, http.StatusForbidden)
+			return
+		}
 		http.Error(w, `{"error":"Invalid email or password"}`, http.StatusUnauthorized)
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user.ID, user.Email, h.jwtSecret)
+	// With two-factor authentication the password only earns a challenge,
+	// exchanged for a session at /api/users/login/two-factor
+	enabled, err := h.twoFactorService.IsEnabled(user.ID)
 	if err != nil {
-		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
+		http.Error(w, `{"error":"Failed to check two-factor authentication"}`, http.StatusInternalServerError)
+		return
+	}
+	if enabled {
+		challengeToken, err := h.twoFactorService.StartChallenge(user.ID)
+		if err != nil {
+			http.Error(w, `{"error":"Failed to start two-factor login"}`, http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(model.TwoFactorChallengeResponse{TwoFactorRequired: true, ChallengeToken: challengeToken})
+		return
+	}
+
+	// Start a session with an access and refresh token
+	tokens, err := h.sessionService.StartSession(user, clientInfo(r))
+	if err != nil {
+		writeStartSessionError(w, err)
 		return
 	}
+	h.userService.RecordLoginSuccess(user)
 
 	// Prepare response
-	userResponse := h.userService.ToUserResponse(user, token)
+	userResponse := h.userService.ToUserResponse(user, tokens.AccessToken)
+	userResponse.RefreshToken = tokens.RefreshToken
 	response := map[string]interface{}{
 		"user": userResponse,
 	}
@@ -122,6 +162,192 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// RequestPasswordReset handles mailing a password reset link
+func (h *UserHandler) RequestPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.RequestPasswordReset(req.User.Email); err != nil {
+		if err.Error() == "email is required" {
+			http.Error(w, `{"error":"Email is required"}`, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, `{"error":"Failed to send password reset email"}`, http.StatusInternalServerError)
+		return
+	}
+
+	// Answer the same whether or not the email is registered
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"If the email is registered, a password reset link has been sent"}`))
+}
+
+// ConfirmPasswordReset handles setting a new password with a reset token
+func (h *UserHandler) ConfirmPasswordReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.PasswordResetConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.ResetPassword(req.Token, req.Password); err != nil {
+		var statusCode int
+		switch {
+		case strings.HasPrefix(err.Error(), "failed to"):
+			statusCode = http.StatusInternalServerError
+		default:
+			// Missing, invalid or expired token, or a too weak password
+			statusCode = http.StatusBadRequest
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Password has been reset"}`))
+}
+
+// ConfirmEmailVerification handles verifying an email with a mailed token
+func (h *UserHandler) ConfirmEmailVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.EmailVerificationConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.VerifyEmail(req.Token); err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "email already exists":
+			statusCode = http.StatusConflict
+		case strings.HasPrefix(err.Error(), "failed to"):
+			statusCode = http.StatusInternalServerError
+		default:
+			// Missing, invalid or expired token
+			statusCode = http.StatusBadRequest
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Email has been verified"}`))
+}
+
+// ConfirmAccountUnlock handles unlocking a locked out account with a mailed
+// token
+func (h *UserHandler) ConfirmAccountUnlock(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Parse request body
+	var req model.AccountUnlockConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"Invalid JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.userService.UnlockAccount(req.Token); err != nil {
+		var statusCode int
+		switch {
+		case strings.HasPrefix(err.Error(), "failed to"):
+			statusCode = http.StatusInternalServerError
+		default:
+			// Missing, invalid or expired token
+			statusCode = http.StatusBadRequest
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Account has been unlocked"}`))
+}
+
+// ResendEmailVerification handles mailing a new verification link to the
+// current user
+func (h *UserHandler) ResendEmailVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.userService.ResendVerificationEmail(claims.UserID); err != nil {
+		var statusCode int
+		switch {
+		case err.Error() == "email already verified":
+			statusCode = http.StatusConflict
+		case err.Error() == "user not found":
+			statusCode = http.StatusNotFound
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Verification email sent"}`))
+}
+
 // GetCurrentUser handles getting current user information
 func (h *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -143,12 +369,9 @@ func (h *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate new token (refresh)
-	token, err := utils.GenerateToken(user.ID, user.Email, h.jwtSecret)
-	if err != nil {
-		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
-		return
-	}
+	// Return the token the request was made with; new tokens come from refresh
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	token = strings.TrimPrefix(token, "Token ")
 
 	// Prepare response
 	userResponse := h.userService.ToUserResponse(user, token)
@@ -205,8 +428,8 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Generate new token
-	token, err := utils.GenerateToken(user.ID, user.Email, h.jwtSecret)
+	// Reissue the token so that it carries the updated email
+	token, err := h.sessionService.ReissueAccessToken(user, claims)
 	if err != nil {
 		http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
 		return
@@ -222,3 +445,126 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
 }
+
+// Logout handles ending the current session
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(claims.SessionID); err != nil {
+		var statusCode int
+		if err.Error() == "session not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Logged out successfully"}`))
+}
+
+// GetSessions handles listing the active sessions of the current user
+func (h *UserHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	response, err := h.sessionService.ListSessions(claims.UserID, claims.SessionID)
+	if err != nil {
+		http.Error(w, `{"error":"Failed to get sessions"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// DeleteSession handles signing out one of the sessions of the current user
+func (h *UserHandler) DeleteSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, `{"error":"Method not allowed"}`, http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Get user from JWT middleware context
+	claims, ok := middleware.GetUserFromContext(r)
+	if !ok {
+		http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, `{"error":"Invalid session ID"}`, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.sessionService.RevokeUserSession(claims.UserID, sessionID); err != nil {
+		var statusCode int
+		if err.Error() == "session not found" {
+			statusCode = http.StatusNotFound
+		} else {
+			statusCode = http.StatusInternalServerError
+		}
+
+		errorResponse := map[string]interface{}{
+			"error": err.Error(),
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"message":"Session revoked successfully"}`))
+}
+
+// clientInfo describes the client a request comes from. Its address is
+// RemoteAddr, which middleware.RealIP resolves behind trusted proxies.
+func clientInfo(r *http.Request) model.ClientInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	return model.ClientInfo{UserAgent: r.UserAgent(), IPAddress: ip}
+}
+
+// writeStartSessionError reports a session that could not be started
+func writeStartSessionError(w http.ResponseWriter, err error) {
+	if err.Error() == "account suspended" {
+		http.Error(w, `{"error":"Account suspended"}`, http.StatusForbidden)
+		return
+	}
+	http.Error(w, `{"error":"Failed to generate token"}`, http.StatusInternalServerError)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestDeleteSessionSignsOutThatDevice(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser(t, "alice")
+	laptop, laptopRefresh := s.login(t, alice)
+	phone, _ := s.login(t, alice)
+
+	rec := s.do(t, http.MethodGet, "/api/user/sessions", "Bearer "+laptop, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /api/user/sessions = %d %s", rec.Code, rec.Body)
+	}
+	var response model.SessionsResponse
+	decode(t, rec, &response)
+	if len(response.Sessions) != 2 {
+		t.Fatalf("GET /api/user/sessions listed %d sessions, want 2", len(response.Sessions))
+	}
+	var phoneSession int
+	for _, session := range response.Sessions {
+		if !session.Current {
+			phoneSession = session.ID
+		}
+	}
+
+	// Others cannot sign the phone out
+	bob := s.createUser(t, "bob")
+	bobToken, _ := s.login(t, bob)
+	path := "/api/user/sessions/" + strconv.Itoa(phoneSession)
+	if rec := s.do(t, http.MethodDelete, path, "Bearer "+bobToken, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE %s by another user = %d %s, want 404", path, rec.Code, rec.Body)
+	}
+
+	if rec := s.do(t, http.MethodDelete, path, "Bearer "+laptop, nil); rec.Code != http.StatusOK {
+		t.Fatalf("DELETE %s = %d %s", path, rec.Code, rec.Body)
+	}
+
+	// Only the phone is signed out
+	if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+phone, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/user from revoked session = %d %s, want 401", rec.Code, rec.Body)
+	}
+	if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+laptop, nil); rec.Code != http.StatusOK {
+		t.Errorf("GET /api/user from other session = %d %s, want 200", rec.Code, rec.Body)
+	}
+	if code, response := s.refresh(t, laptopRefresh); code != http.StatusOK {
+		t.Errorf("refresh of other session = %d %v, want 200", code, response)
+	}
+}
+
+func TestLogoutRevokesSession(t *testing.T) {
+	s := newTestServer(t)
+	alice := s.createUser(t, "alice")
+	accessToken, refreshToken := s.login(t, alice)
+
+	if rec := s.do(t, http.MethodPost, "/api/users/logout", "Bearer "+accessToken, nil); rec.Code != http.StatusOK {
+		t.Fatalf("POST /api/users/logout = %d %s", rec.Code, rec.Body)
+	}
+
+	if rec := s.do(t, http.MethodGet, "/api/user", "Bearer "+accessToken, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("GET /api/user after logout = %d %s, want 401", rec.Code, rec.Body)
+	}
+	if code, response := s.refresh(t, refreshToken); code != http.StatusUnauthorized || response["error"] != "session revoked" {
+		t.Errorf("refresh after logout = %d %v, want 401 session revoked", code, response)
+	}
+}
@@ -0,0 +1,133 @@
+package mailer
+
+import (
+	"bufio"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// smtpStandIn is a minimal SMTP server that records the envelope and data of
+// the messages it receives
+type smtpStandIn struct {
+	listener net.Listener
+	received chan string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	s := &smtpStandIn{listener: listener, received: make(chan string, 1)}
+	go s.serve()
+	return s
+}
+
+func (s *smtpStandIn) serve() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost ESMTP stand-in")
+
+	var transcript strings.Builder
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch command {
+		case "EHLO", "HELO":
+			text.PrintfLine("250 localhost")
+		case "MAIL", "RCPT":
+			transcript.WriteString(line + "\n")
+			text.PrintfLine("250 OK")
+		case "DATA":
+			text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			transcript.Write(data)
+			text.PrintfLine("250 OK")
+		case "QUIT":
+			text.PrintfLine("221 Bye")
+			s.received <- transcript.String()
+			return
+		default:
+			text.PrintfLine("502 Command not implemented")
+		}
+	}
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := newSMTPStandIn(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+
+	m := NewSMTPMailer(host, port, "", "", "RealWorld <no-reply@example.com>")
+	err := m.Send(Message{To: "alice@example.com", Subject: "Hello", Body: "line one\nline two"})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	got := <-server.received
+	for _, want := range []string{
+		"MAIL FROM:<no-reply@example.com>",
+		"RCPT TO:<alice@example.com>",
+		"Subject: Hello",
+		"line one\nline two",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("received message missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFileMailerSend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m, err := NewFileMailer(dir, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("NewFileMailer() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := m.Send(Message{To: "alice@example.com", Subject: "Hello", Body: "body"}); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 2 {
+		t.Fatalf("mail files = %v, %v; want two", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatalf("Failed to read mail: %v", err)
+	}
+	message, err := textproto.NewReader(bufio.NewReader(strings.NewReader(string(data)))).ReadMIMEHeader()
+	if err != nil {
+		t.Fatalf("Failed to parse mail: %v", err)
+	}
+	if message.Get("To") != "alice@example.com" || message.Get("Subject") != "Hello" {
+		t.Errorf("mail headers = %v, want To alice and Subject Hello", message)
+	}
+}
+
+func TestMessageRejectsHeaderInjection(t *testing.T) {
+	m := NewLogMailer("no-reply@example.com")
+	err := m.Send(Message{To: "alice@example.com\r\nBcc: mallory@example.com", Subject: "Hello"})
+	if err == nil {
+		t.Fatal("Send() with CRLF in a header = nil error, want error")
+	}
+}
@@ -14,10 +14,26 @@ type ContextKey string
 const (
 	// UserContextKey is the key for storing user claims in request context
 	UserContextKey ContextKey = "user"
+	// scopeCheckedKey marks requests whose route checked the API token scope
+	scopeCheckedKey ContextKey = "scopeChecked"
 )
 
-// JWTMiddleware creates a middleware that validates JWT tokens
-func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
+// SessionChecker reports whether the session an access token belongs to is
+// still active, recording that it was used
+type SessionChecker interface {
+	TouchSession(sessionID int) (bool, error)
+}
+
+// APITokenAuthenticator resolves the personal access token of a request to
+// its claims, returning nil claims for unusable tokens
+type APITokenAuthenticator interface {
+	AuthenticateAPIToken(token string) (*utils.Claims, error)
+}
+
+// JWTMiddleware creates a middleware that validates JWT tokens, rejecting
+// those of revoked sessions and suspended users. API tokens are accepted as
+// "Authorization: Token ..." on routes wrapped with RequireScope.
+func JWTMiddleware(keys *utils.KeySet, sessions SessionChecker, apiTokens APITokenAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the Authorization header
@@ -27,9 +43,30 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 				return
 			}
 
+			// API tokens are looked up instead of validated as a JWT
+			if strings.HasPrefix(authHeader, "Token ") {
+				claims, err := apiTokens.AuthenticateAPIToken(strings.TrimPrefix(authHeader, "Token "))
+				if err != nil {
+					if err.Error() == "account suspended" {
+						http.Error(w, `{"error":"Account suspended"}`, http.StatusForbidden)
+						return
+					}
+					http.Error(w, `{"error":"Failed to check API token"}`, http.StatusInternalServerError)
+					return
+				}
+				if claims == nil {
+					http.Error(w, `{"error":"Invalid, expired or revoked API token"}`, http.StatusUnauthorized)
+					return
+				}
+
+				ctx := context.WithValue(r.Context(), UserContextKey, claims)
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
 			// Check if header has "Bearer " prefix
 			if !strings.HasPrefix(authHeader, "Bearer ") {
-				http.Error(w, `{"error":"Authorization header must start with Bearer"}`, http.StatusUnauthorized)
+				http.Error(w, `{"error":"Authorization header must start with Bearer or Token"}`, http.StatusUnauthorized)
 				return
 			}
 
@@ -41,12 +78,27 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 			}
 
 			// Validate the token
-			claims, err := utils.ValidateToken(tokenString, secretKey)
+			claims, err := utils.ValidateToken(tokenString, keys)
 			if err != nil {
 				http.Error(w, `{"error":"Invalid or expired token"}`, http.StatusUnauthorized)
 				return
 			}
 
+			// Check that the session was not logged out
+			active, err := sessions.TouchSession(claims.SessionID)
+			if err != nil {
+				if err.Error() == "account suspended" {
+					http.Error(w, `{"error":"Account suspended"}`, http.StatusForbidden)
+					return
+				}
+				http.Error(w, `{"error":"Failed to check session"}`, http.StatusInternalServerError)
+				return
+			}
+			if !active {
+				http.Error(w, `{"error":"Session has been revoked"}`, http.StatusUnauthorized)
+				return
+			}
+
 			// Add user claims to request context
 			ctx := context.WithValue(r.Context(), UserContextKey, claims)
 			r = r.WithContext(ctx)
@@ -59,7 +111,7 @@ func JWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 
 // OptionalJWTMiddleware creates a middleware that validates JWT tokens if present
 // but doesn't require authentication (useful for endpoints that work with or without auth)
-func OptionalJWTMiddleware(secretKey string) func(http.Handler) http.Handler {
+func OptionalJWTMiddleware(keys *utils.KeySet, sessions SessionChecker, apiTokens APITokenAuthenticator) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the Authorization header
@@ -72,11 +124,16 @@ func OptionalJWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 			}
 
 			// If header exists, validate it
-			if strings.HasPrefix(authHeader, "Bearer ") {
+			if strings.HasPrefix(authHeader, "Token ") {
+				if claims, err := apiTokens.AuthenticateAPIToken(strings.TrimPrefix(authHeader, "Token ")); err == nil && claims != nil {
+					ctx := context.WithValue(r.Context(), UserContextKey, claims)
+					r = r.WithContext(ctx)
+				}
+			} else if strings.HasPrefix(authHeader, "Bearer ") {
 				tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 				if tokenString != "" {
 					// Try to validate the token
-					if claims, err := utils.ValidateToken(tokenString, secretKey); err == nil {
+					if claims, err := utils.ValidateToken(tokenString, keys); err == nil && isActive(sessions, claims) {
 						// Add user claims to request context if token is valid
 						ctx := context.WithValue(r.Context(), UserContextKey, claims)
 						r = r.WithContext(ctx)
@@ -91,7 +148,53 @@ func OptionalJWTMiddleware(secretKey string) func(http.Handler) http.Handler {
 	}
 }
 
-// GetUserFromContext extracts user claims from request context
+// isActive reports whether the session of claims is active, treating lookup
+// failures as inactive
+func isActive(sessions SessionChecker, claims *utils.Claims) bool {
+	active, err := sessions.TouchSession(claims.SessionID)
+	return err == nil && active
+}
+
+// RequireScope creates a middleware that lets API tokens with scope through
+// to a route authenticated by JWTMiddleware or OptionalJWTMiddleware. API
+// tokens are not authenticated on routes without it, so that they can only
+// reach what a scope grants; sessions are not affected.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if claims, ok := r.Context().Value(UserContextKey).(*utils.Claims); ok && !claims.HasScope(scope) {
+				http.Error(w, `{"error":"API token lacks the `+scope+` scope"}`, http.StatusForbidden)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), scopeCheckedKey, true)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequirePermission creates a middleware that only lets requests through when
+// the role of the user grants permission. It must run after JWTMiddleware.
+func RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := GetUserFromContext(r)
+			if !ok {
+				http.Error(w, `{"error":"Authentication required"}`, http.StatusUnauthorized)
+				return
+			}
+			if !claims.HasPermission(permission) {
+				http.Error(w, `{"error":"Missing the `+permission+` permission"}`, http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// GetUserFromContext extracts user claims from request context. Claims of an
+// API token are only returned on routes wrapped with RequireScope.
 func GetUserFromContext(r *http.Request) (*utils.Claims, bool) {
 	user := r.Context().Value(UserContextKey)
 	if user == nil {
@@ -99,6 +202,9 @@ func GetUserFromContext(r *http.Request) (*utils.Claims, bool) {
 	}
 
 	claims, ok := user.(*utils.Claims)
+	if ok && claims.TokenID != 0 && r.Context().Value(scopeCheckedKey) != true {
+		return nil, false
+	}
 	return claims, ok
 }
 
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/16")
+	trusted := []*net.IPNet{proxies}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"direct without header", "203.0.113.7:5000", "", "203.0.113.7:5000"},
+		{"direct with forged header", "203.0.113.7:5000", "198.51.100.1", "203.0.113.7:5000"},
+		{"proxy", "10.0.1.5:5000", "198.51.100.1", "198.51.100.1"},
+		{"proxy with forged entry", "10.0.1.5:5000", "192.0.2.9, 198.51.100.1", "198.51.100.1"},
+		{"proxy chain", "10.0.1.5:5000", "198.51.100.1, 10.0.2.8", "198.51.100.1"},
+		{"proxy without header", "10.0.1.5:5000", "", "10.0.1.5:5000"},
+		{"proxy with garbage", "10.0.1.5:5000", "not-an-ip", "10.0.1.5:5000"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.RemoteAddr
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package model
+
+import "time"
+
+// DeleteAccountRequest represents the request body for deleting the account
+// of the current user. Password is required unless the account has none.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	// Mode is DeletionModeDelete or DeletionModeAnonymize
+	Mode string `json:"mode"`
+}
+
+// AccountDeletion describes the scheduled deletion of an account
+type AccountDeletion struct {
+	ScheduledAt time.Time `json:"scheduledAt"`
+	Mode        string    `json:"mode"`
+}
+
+// AccountDeletionResponse represents the response for scheduling the deletion
+// of an account
+type AccountDeletionResponse struct {
+	Deletion AccountDeletion `json:"deletion"`
+}
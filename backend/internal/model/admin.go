@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// AdminUser represents a user as seen by admins
+type AdminUser struct {
+	ID               int        `json:"id"`
+	Email            string     `json:"email"`
+	Username         string     `json:"username"`
+	Role             string     `json:"role"`
+	EmailVerified    bool       `json:"emailVerified"`
+	PendingEmail     string     `json:"pendingEmail,omitempty"`
+	HasPassword      bool       `json:"hasPassword"`
+	SuspendedAt      *time.Time `json:"suspendedAt"`
+	SuspensionReason string     `json:"suspensionReason,omitempty"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	// DeletionScheduledAt is when the user asked for their account to be
+	// deleted
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt"`
+}
+
+// AdminUserResponse represents the response for a single user of the admin API
+type AdminUserResponse struct {
+	User AdminUser `json:"user"`
+}
+
+// AdminUsersResponse represents the response for a page of users of the admin
+// API
+type AdminUsersResponse struct {
+	Users      []AdminUser `json:"users"`
+	UsersCount int         `json:"usersCount"`
+}
+
+// SuspendUserRequest represents the request body for suspending a user
+type SuspendUserRequest struct {
+	Reason string `json:"reason"`
+}
+
+// UpdateUserRoleRequest represents the request body for changing the role of
+// a user
+type UpdateUserRoleRequest struct {
+	Role string `json:"role"`
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Scopes an API token can be granted
+const (
+	ScopeRead          = "read"
+	ScopeArticlesWrite = "articles:write"
+	ScopeCommentsWrite = "comments:write"
+)
+
+// APIScopes lists every scope an API token can be granted
+var APIScopes = []string{ScopeRead, ScopeArticlesWrite, ScopeCommentsWrite}
+
+// APIToken represents a named personal access token used by scripts and CI
+// in place of a password. Only the SHA-256 hash of the token is stored.
+type APIToken struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	TokenHash  string     `json:"-" db:"token_hash"`
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt" db:"expires_at"`
+	LastUsedAt *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	RevokedAt  *time.Time `json:"-" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	// Token is the token itself, only set in the response creating it
+	Token string `json:"token,omitempty" db:"-"`
+}
+
+// CreateAPITokenRequest represents the request body for creating an API token
+type CreateAPITokenRequest struct {
+	Token struct {
+		Name          string   `json:"name"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	} `json:"token"`
+}
+
+// APITokenResponse represents the response for a single API token
+type APITokenResponse struct {
+	Token APIToken `json:"token"`
+}
+
+// APITokensResponse represents the response for the API tokens of a user
+type APITokensResponse struct {
+	Tokens []APIToken `json:"tokens"`
+}
@@ -4,17 +4,34 @@ import (
 	"time"
 )
 
+// Article publication statuses
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusPublished = "published"
+	ArticleStatusUnlisted  = "unlisted" // reachable by slug but left out of listings
+)
+
+// Article list orders
+const (
+	ArticleSortRecent   = "recent"   // newest first
+	ArticleSortTop      = "top"      // most favorited first
+	ArticleSortTrending = "trending" // highest decayed activity score first
+	ArticleSortUpdated  = "updated"  // most recently edited first
+)
+
 // Article represents an article in the database
 type Article struct {
-	ID             int       `json:"id" db:"id"`
-	Slug           string    `json:"slug" db:"slug"`
-	Title          string    `json:"title" db:"title"`
-	Description    string    `json:"description" db:"description"`
-	Body           string    `json:"body" db:"body"`
-	AuthorID       int       `json:"author_id" db:"author_id"`
-	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt      time.Time `json:"updatedAt" db:"updated_at"`
-	FavoritesCount int       `json:"favoritesCount" db:"favorites_count"`
+	ID             int        `json:"id" db:"id"`
+	Slug           string     `json:"slug" db:"slug"`
+	Title          string     `json:"title" db:"title"`
+	Description    string     `json:"description" db:"description"`
+	Body           string     `json:"body" db:"body"`
+	AuthorID       int        `json:"author_id" db:"author_id"`
+	Status         string     `json:"status" db:"status"`
+	PublishAt      *time.Time `json:"publishAt" db:"publish_at"`
+	CreatedAt      time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
+	FavoritesCount int        `json:"favoritesCount" db:"favorites_count"`
 }
 
 // ArticleResponse represents an article response for API
@@ -24,6 +41,8 @@ type ArticleResponse struct {
 	Description    string        `json:"description"`
 	Body           string        `json:"body"`
 	TagList        []string      `json:"tagList"`
+	Status         string        `json:"status"`
+	PublishAt      *time.Time    `json:"publishAt"`
 	CreatedAt      time.Time     `json:"createdAt"`
 	UpdatedAt      time.Time     `json:"updatedAt"`
 	Favorited      bool          `json:"favorited"`
@@ -42,20 +61,24 @@ type AuthorProfile struct {
 // CreateArticleRequest represents a request to create an article
 type CreateArticleRequest struct {
 	Article struct {
-		Title       string   `json:"title" validate:"required,min=1"`
-		Description string   `json:"description" validate:"required,min=1"`
-		Body        string   `json:"body" validate:"required,min=1"`
-		TagList     []string `json:"tagList"`
+		Title       string     `json:"title" validate:"required,min=1"`
+		Description string     `json:"description" validate:"required,min=1"`
+		Body        string     `json:"body" validate:"required,min=1"`
+		TagList     []string   `json:"tagList"`
+		Status      string     `json:"status,omitempty"`
+		PublishAt   *time.Time `json:"publishAt,omitempty"`
 	} `json:"article"`
 }
 
 // UpdateArticleRequest represents a request to update an article
 type UpdateArticleRequest struct {
 	Article struct {
-		Title       *string  `json:"title,omitempty"`
-		Description *string  `json:"description,omitempty"`
-		Body        *string  `json:"body,omitempty"`
-		TagList     []string `json:"tagList,omitempty"`
+		Title       *string    `json:"title,omitempty"`
+		Description *string    `json:"description,omitempty"`
+		Body        *string    `json:"body,omitempty"`
+		TagList     []string   `json:"tagList,omitempty"`
+		Status      *string    `json:"status,omitempty"`
+		PublishAt   *time.Time `json:"publishAt,omitempty"`
 	} `json:"article"`
 }
 
@@ -68,4 +91,26 @@ type ArticleResponseWrapper struct {
 type ArticlesResponse struct {
 	Articles      []ArticleResponse `json:"articles"`
 	ArticlesCount int               `json:"articlesCount"`
+	NextCursor    string            `json:"nextCursor,omitempty"`
+}
+
+// ArticleSearchResult represents an article matched by a full-text search
+type ArticleSearchResult struct {
+	Article        Article
+	TitleHighlight string
+	Snippet        string
+}
+
+// ArticleSearchResponse represents a search hit for API with its highlighted
+// title and snippet
+type ArticleSearchResponse struct {
+	ArticleResponse
+	TitleHighlight string `json:"titleHighlight"`
+	Snippet        string `json:"snippet"`
+}
+
+// ArticleSearchResultsResponse represents multiple search hits response
+type ArticleSearchResultsResponse struct {
+	Articles      []ArticleSearchResponse `json:"articles"`
+	ArticlesCount int                     `json:"articlesCount"`
 }
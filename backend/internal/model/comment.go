@@ -20,5 +20,6 @@ type CommentResponse struct {
 
 // CommentsResponse represents the comments list response format for the API
 type CommentsResponse struct {
-	Comments []*Comment `json:"comments"`
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"nextCursor,omitempty"`
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// EmailVerificationToken represents a single-use token mailed to an address
+// to prove the user owns it. Only the SHA-256 hash of the token is stored.
+type EmailVerificationToken struct {
+	ID        int        `json:"-" db:"id"`
+	UserID    int        `json:"-" db:"user_id"`
+	Email     string     `json:"-" db:"email"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// EmailVerificationConfirmRequest represents the request body for verifying
+// an email with a mailed token
+type EmailVerificationConfirmRequest struct {
+	Token string `json:"token"`
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// UserExport is the personal data of a user, as downloaded from
+// /api/user/export
+type UserExport struct {
+	ExportedAt time.Time         `json:"exportedAt"`
+	Profile    ExportedProfile   `json:"profile"`
+	Articles   []ExportedArticle `json:"articles"`
+	Comments   []ExportedComment `json:"comments"`
+	Favorites  []ExportedLink    `json:"favorites"`
+	Following  []ExportedLink    `json:"following"`
+	Followers  []ExportedLink    `json:"followers"`
+}
+
+// ExportedProfile is the account part of a UserExport
+type ExportedProfile struct {
+	Email         string    `json:"email"`
+	PendingEmail  string    `json:"pendingEmail,omitempty"`
+	EmailVerified bool      `json:"emailVerified"`
+	Username      string    `json:"username"`
+	Bio           string    `json:"bio"`
+	Image         string    `json:"image"`
+	Role          string    `json:"role"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// ExportedArticle is an article of a UserExport, drafts included
+type ExportedArticle struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    string     `json:"description"`
+	Body           string     `json:"body"`
+	TagList        []string   `json:"tagList"`
+	Status         string     `json:"status"`
+	PublishAt      *time.Time `json:"publishAt,omitempty"`
+	FavoritesCount int        `json:"favoritesCount"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// ExportedComment is a comment of a UserExport with the slug of its article
+type ExportedComment struct {
+	Article   string    `json:"article"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ExportedLink is an article favorited, or a user followed or following, in a
+// UserExport: Name is the slug of the article or the username
+type ExportedLink struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Job types handled by the background scheduler
+const (
+	JobTypePublishArticle = "publish_article"
+	JobTypeDeleteAccount  = "delete_account"
+)
+
+// Job statuses
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+// Job represents a persisted unit of background work
+type Job struct {
+	ID        int       `json:"id" db:"id"`
+	Type      string    `json:"type" db:"type"`
+	Payload   string    `json:"payload" db:"payload"`
+	Status    string    `json:"status" db:"status"`
+	RunAt     time.Time `json:"runAt" db:"run_at"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	LastError string    `json:"lastError" db:"last_error"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// PublishArticlePayload is the payload of a publish_article job
+type PublishArticlePayload struct {
+	ArticleID int `json:"articleId"`
+}
+
+// DeleteAccountPayload is the payload of a delete_account job
+type DeleteAccountPayload struct {
+	UserID int `json:"userId"`
+}
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// Scopes of a lockout event
+const (
+	LockoutScopeAccount = "account"
+	LockoutScopeIP      = "ip"
+)
+
+// AccountLoginState tracks the failed password logins of an account
+type AccountLoginState struct {
+	UserID       int        `json:"-" db:"user_id"`
+	FailedLogins int        `json:"-" db:"failed_logins"`
+	LastFailedAt *time.Time `json:"-" db:"last_failed_at"`
+	LockedUntil  *time.Time `json:"-" db:"locked_until"`
+}
+
+// LockoutEvent records that logins to an account or from an IP address were
+// stopped after too many failures
+type LockoutEvent struct {
+	ID          int       `json:"-" db:"id"`
+	UserID      *int      `json:"-" db:"user_id"`
+	IPAddress   string    `json:"-" db:"ip_address"`
+	Scope       string    `json:"-" db:"scope"`
+	LockedUntil time.Time `json:"-" db:"locked_until"`
+	CreatedAt   time.Time `json:"-" db:"created_at"`
+}
+
+// AccountUnlockToken represents a single-use token mailed to a locked out
+// user to unlock their account. Only the SHA-256 hash of the token is stored.
+type AccountUnlockToken struct {
+	ID        int        `json:"-" db:"id"`
+	UserID    int        `json:"-" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// AccountUnlockConfirmRequest represents the request body for unlocking an
+// account with a mailed token
+type AccountUnlockConfirmRequest struct {
+	Token string `json:"token"`
+}
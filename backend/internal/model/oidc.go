@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// UserIdentity links a user to their account at an OpenID Connect issuer
+type UserIdentity struct {
+	ID        int       `json:"-" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	Issuer    string    `json:"-" db:"issuer"`
+	Subject   string    `json:"-" db:"subject"`
+	Email     string    `json:"-" db:"email"`
+	CreatedAt time.Time `json:"-" db:"created_at"`
+}
+
+// OIDCState represents an authorization request sent to the issuer. Only the
+// SHA-256 hash of the state parameter is stored.
+type OIDCState struct {
+	ID           int        `json:"-" db:"id"`
+	StateHash    string     `json:"-" db:"state_hash"`
+	CodeVerifier string     `json:"-" db:"code_verifier"`
+	Nonce        string     `json:"-" db:"nonce"`
+	ExpiresAt    time.Time  `json:"-" db:"expires_at"`
+	UsedAt       *time.Time `json:"-" db:"used_at"`
+	CreatedAt    time.Time  `json:"-" db:"created_at"`
+}
+
+// OIDCLoginCode represents a single-use code the frontend exchanges for tokens
+// after logging in with the issuer. Only the SHA-256 hash of the code is
+// stored.
+type OIDCLoginCode struct {
+	ID        int        `json:"-" db:"id"`
+	UserID    int        `json:"-" db:"user_id"`
+	CodeHash  string     `json:"-" db:"code_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// OIDCTokenRequest represents the request body for exchanging a login code
+// for tokens
+type OIDCTokenRequest struct {
+	Code string `json:"code"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// PasswordResetToken represents a single-use token mailed to a user to set a
+// new password. Only the SHA-256 hash of the token is stored.
+type PasswordResetToken struct {
+	ID        int        `json:"-" db:"id"`
+	UserID    int        `json:"-" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// PasswordResetRequest represents the request body for requesting a password reset
+type PasswordResetRequest struct {
+	User struct {
+		Email string `json:"email"`
+	} `json:"user"`
+}
+
+// PasswordResetConfirmRequest represents the request body for setting a new
+// password with a reset token
+type PasswordResetConfirmRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// ArticleRevision represents an immutable snapshot of an article's content
+type ArticleRevision struct {
+	ID             int       `json:"id" db:"id"`
+	ArticleID      int       `json:"articleId" db:"article_id"`
+	Revision       int       `json:"revision" db:"revision"`
+	Title          string    `json:"title" db:"title"`
+	Description    string    `json:"description" db:"description"`
+	Body           string    `json:"body" db:"body"`
+	EditorID       int       `json:"editorId" db:"editor_id"`
+	EditorUsername string    `json:"editor" db:"-"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ArticleRevisionResponse represents a full revision for API
+type ArticleRevisionResponse struct {
+	Revision    int       `json:"revision"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Body        string    `json:"body"`
+	Editor      string    `json:"editor"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// ArticleRevisionSummary represents a revision in a history listing, without its content
+type ArticleRevisionSummary struct {
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Editor    string    `json:"editor"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArticleRevisionWrapper wraps a revision response
+type ArticleRevisionWrapper struct {
+	Revision ArticleRevisionResponse `json:"revision"`
+}
+
+// ArticleRevisionsResponse represents an article's revision history
+type ArticleRevisionsResponse struct {
+	Revisions      []ArticleRevisionSummary `json:"revisions"`
+	RevisionsCount int                      `json:"revisionsCount"`
+}
+
+// DiffLine is one line of a line-level diff
+type DiffLine struct {
+	Op   string `json:"op"` // "equal", "insert" or "delete"
+	Text string `json:"text"`
+}
+
+// ArticleRevisionDiff represents a line-level diff between two revisions
+type ArticleRevisionDiff struct {
+	From        int        `json:"from"`
+	To          int        `json:"to"`
+	Title       []DiffLine `json:"title"`
+	Description []DiffLine `json:"description"`
+	Body        []DiffLine `json:"body"`
+}
+
+// ArticleRevisionDiffWrapper wraps a revision diff response
+type ArticleRevisionDiffWrapper struct {
+	Diff ArticleRevisionDiff `json:"diff"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import "time"
+
+// Roles of a user
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Permissions granted by roles
+const (
+	// PermissionArticlesModerate allows editing, unpublishing and deleting
+	// articles of other users
+	PermissionArticlesModerate = "articles:moderate"
+	// PermissionCommentsModerate allows deleting comments of other users
+	PermissionCommentsModerate = "comments:moderate"
+	// PermissionModerationRead allows reading the moderation log
+	PermissionModerationRead = "moderation:read"
+	// PermissionUsersManage allows administering user accounts
+	PermissionUsersManage = "users:manage"
+)
+
+// rolePermissions lists the permissions of each role
+var rolePermissions = map[string][]string{
+	RoleUser:      {},
+	RoleModerator: {PermissionArticlesModerate, PermissionCommentsModerate, PermissionModerationRead},
+	RoleAdmin:     {PermissionArticlesModerate, PermissionCommentsModerate, PermissionModerationRead, PermissionUsersManage},
+}
+
+// IsValidRole reports whether role is a known role
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// PermissionsFor returns the permissions of a role. Unknown roles have none.
+func PermissionsFor(role string) []string {
+	return append([]string{}, rolePermissions[role]...)
+}
+
+// Moderation actions
+const (
+	ModerationArticleUpdate  = "article.update"
+	ModerationArticleStatus  = "article.status"
+	ModerationArticleRestore = "article.restore"
+	ModerationArticleDelete  = "article.delete"
+	ModerationCommentDelete  = "comment.delete"
+
+	ModerationUserSuspend       = "user.suspend"
+	ModerationUserReinstate     = "user.reinstate"
+	ModerationUserPasswordReset = "user.password_reset"
+	ModerationUserRole          = "user.role"
+	ModerationUserDelete        = "user.delete"
+)
+
+// ModerationAction records a moderator acting on content of another user, or
+// an admin acting on their account
+type ModerationAction struct {
+	ID            int       `json:"id" db:"id"`
+	ActorID       *int      `json:"-" db:"actor_id"`
+	Actor         string    `json:"actor"`
+	Action        string    `json:"action" db:"action"`
+	TargetType    string    `json:"targetType" db:"target_type"`
+	TargetID      int       `json:"targetId" db:"target_id"`
+	TargetOwnerID *int      `json:"-" db:"target_owner_id"`
+	TargetOwner   string    `json:"targetOwner"`
+	Details       string    `json:"details" db:"details"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+}
+
+// ModerationActionsResponse represents the moderation log response format
+type ModerationActionsResponse struct {
+	Actions []ModerationAction `json:"actions"`
+}
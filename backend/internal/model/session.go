@@ -0,0 +1,50 @@
+package model
+
+import "time"
+
+// Session represents a login of a user on one client. Access tokens carry the
+// session id and stop working once the session is revoked.
+type Session struct {
+	ID         int        `json:"id" db:"id"`
+	UserID     int        `json:"-" db:"user_id"`
+	UserAgent  string     `json:"userAgent" db:"user_agent"`
+	IPAddress  string     `json:"ipAddress" db:"ip_address"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+	LastSeenAt *time.Time `json:"lastSeenAt" db:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty" db:"revoked_at"`
+	Current    bool       `json:"current"`
+}
+
+// ClientInfo describes the client a session is started from
+type ClientInfo struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionsResponse represents the response for the active sessions of a user
+type SessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// RefreshToken represents a single-use refresh token of a session. Only the
+// SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	ID        int        `json:"-" db:"id"`
+	SessionID int        `json:"-" db:"session_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// TokenPair is the access and refresh token issued at login and on refresh
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    int
+}
+
+// RefreshTokenRequest represents the request body for token refresh
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// TOTPCredential represents the TOTP secret of a user. Two-factor login is
+// enabled once it is confirmed with a first code.
+type TOTPCredential struct {
+	UserID       int        `json:"-" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	ConfirmedAt  *time.Time `json:"-" db:"confirmed_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"-" db:"created_at"`
+}
+
+// TwoFactorChallenge represents the second step of a login, proven with a
+// code. Only the SHA-256 hash of its token is stored.
+type TwoFactorChallenge struct {
+	ID        int        `json:"-" db:"id"`
+	UserID    int        `json:"-" db:"user_id"`
+	TokenHash string     `json:"-" db:"token_hash"`
+	ExpiresAt time.Time  `json:"-" db:"expires_at"`
+	UsedAt    *time.Time `json:"-" db:"used_at"`
+	Attempts  int        `json:"-" db:"attempts"`
+	CreatedAt time.Time  `json:"-" db:"created_at"`
+}
+
+// TwoFactorSetupResponse represents a new TOTP secret to add to an
+// authenticator app
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+// RecoveryCodesResponse represents newly generated recovery codes, shown once
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// TwoFactorChallengeResponse represents the response to a password login of
+// a user with two-factor authentication
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+}
+
+// TwoFactorCodeRequest represents a request body carrying a TOTP or
+// recovery code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TwoFactorLoginRequest represents the request body for completing a login
+// with a challenge token and a TOTP or recovery code
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken"`
+	Code           string `json:"code"`
+}
@@ -2,25 +2,61 @@ package model
 
 import "time"
 
-// User represents a user in the system
+// UnusablePasswordHash is the password hash of users who have no password,
+// such as those who signed up through an OpenID Connect issuer or whose
+// password was cleared by an admin. It matches no password, but a password
+// reset can set one.
+const UnusablePasswordHash = "!"
+
+// DeletedUsername is the username of the account that keeps the articles and
+// comments of users deleted with DeletionModeAnonymize. It is not a valid
+// username, so no one can sign up as it.
+const DeletedUsername = "deleted user"
+
+// Ways of deleting an account
+const (
+	// DeletionModeDelete deletes the articles and comments of the user
+	DeletionModeDelete = "delete"
+	// DeletionModeAnonymize keeps them as those of DeletedUsername
+	DeletionModeAnonymize = "anonymize"
+)
+
+// User represents a user in the system. A requested new email is kept in
+// PendingEmail until it is verified. Suspended users have SuspendedAt set, and
+// users who asked to delete their account have DeletionScheduledAt set.
 type User struct {
-	ID           int       `json:"id" db:"id"`
-	Email        string    `json:"email" db:"email"`
-	Username     string    `json:"username" db:"username"`
-	PasswordHash string    `json:"-" db:"password_hash"`
-	Bio          string    `json:"bio" db:"bio"`
-	Image        string    `json:"image" db:"image"`
-	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt    time.Time `json:"updatedAt" db:"updated_at"`
+	ID            int       `json:"id" db:"id"`
+	Email         string    `json:"email" db:"email"`
+	Username      string    `json:"username" db:"username"`
+	PasswordHash  string    `json:"-" db:"password_hash"`
+	Bio           string    `json:"bio" db:"bio"`
+	Image         string    `json:"image" db:"image"`
+	EmailVerified bool      `json:"emailVerified" db:"email_verified"`
+	PendingEmail  string    `json:"-" db:"pending_email"`
+	Role          string    `json:"role" db:"role"`
+	CreatedAt     time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt     time.Time `json:"updatedAt" db:"updated_at"`
+
+	SuspendedAt      *time.Time `json:"-" db:"suspended_at"`
+	SuspensionReason string     `json:"-" db:"suspension_reason"`
+
+	DeletionScheduledAt *time.Time `json:"-" db:"deletion_scheduled_at"`
+	DeletionMode        string     `json:"-" db:"deletion_mode"`
 }
 
 // UserResponse represents the user response format for the API
 type UserResponse struct {
-	Email    string `json:"email"`
-	Token    string `json:"token"`
-	Username string `json:"username"`
-	Bio      string `json:"bio"`
-	Image    string `json:"image"`
+	Email         string `json:"email"`
+	Token         string `json:"token"`
+	RefreshToken  string `json:"refreshToken,omitempty"`
+	Username      string `json:"username"`
+	Bio           string `json:"bio"`
+	Image         string `json:"image"`
+	EmailVerified bool   `json:"emailVerified"`
+	PendingEmail  string `json:"pendingEmail,omitempty"`
+	Role          string `json:"role"`
+	// DeletionScheduledAt is when the account is deleted, if the user asked
+	DeletionScheduledAt *time.Time `json:"deletionScheduledAt,omitempty"`
 }
 
 // ProfileResponse represents the profile response format for the API
@@ -29,6 +65,9 @@ type ProfileResponse struct {
 	Bio       string `json:"bio"`
 	Image     string `json:"image"`
 	Following bool   `json:"following"`
+	// Blocking and Muting are only set on the profile endpoints
+	Blocking bool `json:"blocking,omitempty"`
+	Muting   bool `json:"muting,omitempty"`
 }
 
 // CreateUserRequest represents the request body for user registration
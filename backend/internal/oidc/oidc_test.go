@@ -0,0 +1,235 @@
+package oidc
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIssuer is an in-process identity provider issuing ID tokens for the
+// codes it hands out
+type mockIssuer struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	// codes maps authorization codes to the PKCE challenge and nonce of
+	// their authorization request
+	codes map[string][2]string
+	// claims are merged into every ID token
+	claims jwt.MapClaims
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	m := &mockIssuer{t: t, codes: map[string][2]string{}, claims: jwt.MapClaims{}}
+	m.rotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": m.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		request, ok := m.codes[r.Form.Get("code")]
+		if !ok || CodeChallenge(r.Form.Get("code_verifier")) != request[0] {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		delete(m.codes, r.Form.Get("code"))
+		json.NewEncoder(w).Encode(map[string]string{"id_token": m.idToken(request[1])})
+	})
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// rotateKey replaces the signing key of the issuer
+func (m *mockIssuer) rotateKey() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		m.t.Fatalf("failed to generate key: %v", err)
+	}
+	m.key = key
+	m.kid = base64.RawURLEncoding.EncodeToString(key.N.Bytes()[:8])
+}
+
+// authorize plays the user consenting at the authorization endpoint and
+// returns the code the browser would be redirected back with
+func (m *mockIssuer) authorize(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		m.t.Fatalf("invalid authorization URL: %v", err)
+	}
+	q := u.Query()
+	if q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "realworld" {
+		m.t.Fatalf("unexpected authorization request: %s", authURL)
+	}
+
+	code := "code-" + q.Get("state")
+	m.codes[code] = [2]string{q.Get("code_challenge"), q.Get("nonce")}
+	return code
+}
+
+func (m *mockIssuer) idToken(nonce string) string {
+	claims := jwt.MapClaims{
+		"iss":                m.server.URL,
+		"sub":                "user-123",
+		"aud":                "realworld",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              nonce,
+		"email":              "jane@example.com",
+		"email_verified":     true,
+		"preferred_username": "jane.doe",
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(m.key)
+	if err != nil {
+		m.t.Fatalf("failed to sign ID token: %v", err)
+	}
+	return signed
+}
+
+func (m *mockIssuer) provider() *Provider {
+	return NewProvider(Config{
+		IssuerURL:   m.server.URL,
+		ClientID:    "realworld",
+		RedirectURL: "http://localhost:8080/api/auth/oidc/callback",
+		Scopes:      []string{"openid", "email", "profile"},
+	}, m.server.Client())
+}
+
+// login runs the authorization code flow and returns the verified claims
+func login(t *testing.T, m *mockIssuer, p *Provider, verifierOverride string) (*Claims, error) {
+	t.Helper()
+
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("GenerateCodeVerifier() error = %v", err)
+	}
+	authURL, err := p.AuthCodeURL("state", "nonce", CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("AuthCodeURL() error = %v", err)
+	}
+	code := m.authorize(authURL)
+
+	if verifierOverride != "" {
+		verifier = verifierOverride
+	}
+	return p.Exchange(code, verifier, "nonce")
+}
+
+func TestProviderExchange(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	claims, err := login(t, m, p, "")
+	if err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "jane@example.com" || !bool(claims.EmailVerified) || claims.PreferredUsername != "jane.doe" {
+		t.Errorf("Exchange() claims = %+v", claims)
+	}
+
+	// A wrong verifier is refused by the issuer
+	if _, err := login(t, m, p, "wrong-verifier"); err == nil {
+		t.Error("Exchange() with wrong code verifier succeeded")
+	}
+}
+
+func TestProviderKeyRotation(t *testing.T) {
+	m := newMockIssuer(t)
+	p := m.provider()
+
+	if _, err := login(t, m, p, ""); err != nil {
+		t.Fatalf("Exchange() error = %v", err)
+	}
+
+	// Tokens signed with a new key are accepted once the keys are refetched
+	m.rotateKey()
+	p.keysFetchedAt = time.Time{}
+	if _, err := login(t, m, p, ""); err != nil {
+		t.Errorf("Exchange() after key rotation error = %v", err)
+	}
+}
+
+func TestProviderVerifyIDTokenRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		nonce  string
+	}{
+		{"wrong nonce", nil, "other"},
+		{"wrong audience", jwt.MapClaims{"aud": "someone-else"}, "nonce"},
+		{"wrong issuer", jwt.MapClaims{"iss": "https://evil.example.com"}, "nonce"},
+		{"expired", jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}, "nonce"},
+		{"no subject", jwt.MapClaims{"sub": ""}, "nonce"},
+		{"other authorized party", jwt.MapClaims{"aud": []string{"realworld", "other"}, "azp": "other"}, "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMockIssuer(t)
+			m.claims = tt.claims
+			p := m.provider()
+
+			if _, err := p.VerifyIDToken(m.idToken("nonce"), tt.nonce); err == nil {
+				t.Error("VerifyIDToken() succeeded, want error")
+			}
+		})
+	}
+
+	// Unsigned tokens are refused
+	m := newMockIssuer(t)
+	p := m.provider()
+	unsigned, _ := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": m.server.URL, "sub": "user-123", "aud": "realworld", "nonce": "nonce",
+		"exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if _, err := p.VerifyIDToken(unsigned, "nonce"); err == nil || !strings.Contains(err.Error(), "invalid ID token") {
+		t.Errorf("VerifyIDToken(alg none) error = %v, want invalid ID token", err)
+	}
+}
+
+func TestFlexibleBool(t *testing.T) {
+	for input, want := range map[string]bool{`true`: true, `"true"`: true, `false`: false, `"false"`: false, `null`: false} {
+		var b flexibleBool
+		if err := json.Unmarshal([]byte(input), &b); err != nil || bool(b) != want {
+			t.Errorf("Unmarshal(%s) = %v, %v; want %v", input, b, err, want)
+		}
+	}
+}
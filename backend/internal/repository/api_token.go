@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// APITokenRepository handles personal access token database operations
+type APITokenRepository struct {
+	db *db.Database
+}
+
+// NewAPITokenRepository creates a new API token repository
+func NewAPITokenRepository(database *db.Database) *APITokenRepository {
+	return &APITokenRepository{db: database}
+}
+
+// Create stores a new API token
+func (r *APITokenRepository) Create(token *model.APIToken) error {
+	query := `
+		INSERT INTO api_tokens (user_id, name, token_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	token.CreatedAt = time.Now().UTC()
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	id, err := r.db.InsertReturningID(query, token.UserID, token.Name, token.TokenHash, strings.Join(token.Scopes, " "), token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API token: %w", err)
+	}
+	token.ID = int(id)
+
+	return nil
+}
+
+// GetByHash retrieves the API token with the given hash, whether or not it
+// is still usable
+func (r *APITokenRepository) GetByHash(tokenHash string) (*model.APIToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_tokens WHERE token_hash = ?
+	`
+
+	token, err := scanAPIToken(r.db.QueryRow(query, tokenHash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("API token not found")
+		}
+		return nil, fmt.Errorf("failed to get API token: %w", err)
+	}
+
+	return token, nil
+}
+
+// ListActiveByUser retrieves the unrevoked, unexpired API tokens of a user,
+// newest first
+func (r *APITokenRepository) ListActiveByUser(userID int) ([]model.APIToken, error) {
+	dialect := r.db.Dialect()
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM api_tokens
+		WHERE user_id = ? AND revoked_at IS NULL
+			AND ` + timeExpr(dialect, "expires_at") + ` > ` + timeExpr(dialect, "?") + `
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.Query(query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []model.APIToken{}
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API token: %w", err)
+		}
+		tokens = append(tokens, *token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// Touch records that an API token was used at the given time
+func (r *APITokenRepository) Touch(id int, at time.Time) error {
+	query := `UPDATE api_tokens SET last_used_at = ? WHERE id = ?`
+
+	if _, err := r.db.Exec(query, at.UTC(), id); err != nil {
+		return fmt.Errorf("failed to update API token: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeForUser revokes an API token of the given user. Tokens of other
+// users are reported as not found.
+func (r *APITokenRepository) RevokeForUser(id, userID int) error {
+	query := `UPDATE api_tokens SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now().UTC(), id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke API token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("API token not found")
+	}
+
+	return nil
+}
+
+// scanAPIToken scans an API token row, splitting its scopes
+func scanAPIToken(row interface{ Scan(...interface{}) error }) (*model.APIToken, error) {
+	var token model.APIToken
+	var scopes string
+	err := row.Scan(
+		&token.ID, &token.UserID, &token.Name, &token.TokenHash, &scopes,
+		&token.ExpiresAt, &token.LastUsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	token.Scopes = strings.Fields(scopes)
+
+	return &token, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestAPITokenRepositoryListAndRevoke(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewAPITokenRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+
+		ci := &model.APIToken{UserID: alice.ID, Name: "ci", TokenHash: "ci", Scopes: []string{model.ScopeRead, model.ScopeArticlesWrite}, ExpiresAt: time.Now().Add(time.Hour)}
+		expired := &model.APIToken{UserID: alice.ID, Name: "old", TokenHash: "old", Scopes: []string{model.ScopeRead}, ExpiresAt: time.Now().Add(-time.Hour)}
+		for _, token := range []*model.APIToken{ci, expired} {
+			if err := repo.Create(token); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		got, err := repo.GetByHash("ci")
+		if err != nil || got.ID != ci.ID || len(got.Scopes) != 2 || got.Scopes[1] != model.ScopeArticlesWrite {
+			t.Fatalf("GetByHash() = %+v, %v; want ci with two scopes", got, err)
+		}
+
+		tokens, err := repo.ListActiveByUser(alice.ID)
+		if err != nil || len(tokens) != 1 || tokens[0].ID != ci.ID {
+			t.Fatalf("ListActiveByUser() = %+v, %v; want only ci", tokens, err)
+		}
+
+		if err := repo.RevokeForUser(ci.ID, bob.ID); err == nil || err.Error() != "API token not found" {
+			t.Errorf("RevokeForUser(other user) error = %v, want API token not found", err)
+		}
+		if err := repo.RevokeForUser(ci.ID, alice.ID); err != nil {
+			t.Fatalf("RevokeForUser() error = %v", err)
+		}
+		if got, _ := repo.GetByHash("ci"); got.RevokedAt == nil {
+			t.Error("GetByHash() after revoke has no revoked time")
+		}
+		if tokens, _ := repo.ListActiveByUser(alice.ID); len(tokens) != 0 {
+			t.Errorf("ListActiveByUser() after revoke = %+v, want none", tokens)
+		}
+	})
+}
@@ -3,46 +3,67 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"html"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
+// resolveSlugQuery finds an article by its current slug or by a slug it used
+// before a title change, returning the article id and its current slug
+const resolveSlugQuery = `
+	SELECT id, slug FROM articles WHERE slug = ?
+	UNION ALL
+	SELECT a.id, a.slug
+	FROM article_slug_history h
+	INNER JOIN articles a ON a.id = h.article_id
+	WHERE h.slug = ?
+`
+
+// authorNotSuspended keeps the articles a whose author is not suspended, so
+// that listings hide the content of suspended users
+const authorNotSuspended = `NOT EXISTS (SELECT 1 FROM users su WHERE su.id = a.author_id AND su.suspended_at IS NOT NULL)`
+
+// authorNotMuted keeps the articles a whose author is not muted by the user
+// given as its argument
+const authorNotMuted = `NOT EXISTS (SELECT 1 FROM user_mutes um WHERE um.muter_id = ? AND um.muted_id = a.author_id)`
+
 // ArticleRepository handles article database operations
 type ArticleRepository struct {
-	db *sql.DB
+	db *db.Database
 }
 
 // NewArticleRepository creates a new article repository
-func NewArticleRepository(db *sql.DB) *ArticleRepository {
-	return &ArticleRepository{db: db}
+func NewArticleRepository(database *db.Database) *ArticleRepository {
+	return &ArticleRepository{db: database}
 }
 
 // Create creates a new article
 func (r *ArticleRepository) Create(article *model.Article) error {
 	query := `
-		INSERT INTO articles (slug, title, description, body, author_id, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO articles (slug, title, description, body, author_id, status, publish_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	now := time.Now()
 	article.CreatedAt = now
 	article.UpdatedAt = now
 	article.FavoritesCount = 0
+	if article.Status == "" {
+		article.Status = model.ArticleStatusPublished
+	}
 
-	result, err := r.db.Exec(query,
+	id, err := r.db.InsertReturningID(query,
 		article.Slug, article.Title, article.Description, article.Body,
-		article.AuthorID, article.CreatedAt, article.UpdatedAt)
+		article.AuthorID, article.Status, article.PublishAt, article.CreatedAt, article.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create article: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get article ID: %w", err)
-	}
-
 	article.ID = int(id)
 	return nil
 }
@@ -50,7 +71,7 @@ func (r *ArticleRepository) Create(article *model.Article) error {
 // GetBySlug retrieves an article by slug
 func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 	query := `
-		SELECT id, slug, title, description, body, author_id, created_at, updated_at
+		SELECT id, slug, title, description, body, author_id, status, publish_at, favorites_count, created_at, updated_at
 		FROM articles 
 		WHERE slug = ?
 	`
@@ -58,7 +79,8 @@ func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 	article := &model.Article{}
 	err := r.db.QueryRow(query, slug).Scan(
 		&article.ID, &article.Slug, &article.Title, &article.Description,
-		&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
+		&article.Body, &article.AuthorID, &article.Status, &article.PublishAt,
+		&article.FavoritesCount, &article.CreatedAt, &article.UpdatedAt,
 	)
 
 	if err != nil {
@@ -68,14 +90,25 @@ func (r *ArticleRepository) GetBySlug(slug string) (*model.Article, error) {
 		return nil, fmt.Errorf("failed to get article: %w", err)
 	}
 
-	// Set default favorites count to 0 for now
-	article.FavoritesCount = 0
-
 	return article, nil
 }
 
 // Update updates an existing article
 func (r *ArticleRepository) Update(slug string, updates map[string]interface{}) (*model.Article, error) {
+	return r.update(slug, updates, 0, false)
+}
+
+// UpdateWithRevision updates an existing article like Update and, in the same
+// transaction, snapshots its content as the next revision by editorID. Edits
+// that leave the title, description and body as they were, such as tag or
+// status changes, record no revision.
+func (r *ArticleRepository) UpdateWithRevision(slug string, updates map[string]interface{}, editorID int) (*model.Article, error) {
+	return r.update(slug, updates, editorID, true)
+}
+
+// update applies updates to the article at slug, recording a revision when
+// withRevision is set
+func (r *ArticleRepository) update(slug string, updates map[string]interface{}, editorID int, withRevision bool) (*model.Article, error) {
 	if len(updates) == 0 {
 		return r.GetBySlug(slug)
 	}
@@ -102,14 +135,87 @@ func (r *ArticleRepository) Update(slug string, updates map[string]interface{})
 		WHERE slug = ?
 	`, strings.Join(setParts, ", "))
 
-	_, err := r.db.Exec(query, args...)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// A title change also changes the slug; remember the old one
+	newSlug, slugChanged := updates["slug"].(string)
+	if slugChanged && newSlug != slug {
+		if err := r.recordSlugChange(tx, slug, newSlug); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update article: %w", err)
 	}
 
+	if slugChanged {
+		slug = newSlug
+	}
+
+	// The update holds the article's row lock until commit, so concurrent
+	// edits number their revisions one after the other
+	if withRevision {
+		if err := recordChangedRevision(tx, slug, editorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit article update: %w", err)
+	}
+
 	return r.GetBySlug(slug)
 }
 
+// recordSlugChange keeps oldSlug in the slug history of the article that is
+// moving to newSlug
+func (r *ArticleRepository) recordSlugChange(tx *db.Tx, oldSlug, newSlug string) error {
+	var articleID int
+	err := tx.QueryRow("SELECT id FROM articles WHERE slug = ?", oldSlug).Scan(&articleID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("article not found")
+		}
+		return fmt.Errorf("failed to get article: %w", err)
+	}
+
+	// A slug left behind by a deleted article may be taken over
+	upsert := r.db.Dialect().Upsert("article_slug_history",
+		[]string{"slug", "article_id", "created_at"}, []string{"slug"}, []string{"article_id", "created_at"})
+	if _, err := tx.Exec(upsert, oldSlug, articleID, time.Now()); err != nil {
+		return fmt.Errorf("failed to record slug history: %w", err)
+	}
+
+	// The new slug is current now, so it must not redirect anywhere
+	if _, err := tx.Exec("DELETE FROM article_slug_history WHERE slug = ?", newSlug); err != nil {
+		return fmt.Errorf("failed to clear slug history: %w", err)
+	}
+
+	return nil
+}
+
+// ResolveSlug returns the id and current slug of the article that uses slug
+// now or used it before a title change
+func (r *ArticleRepository) ResolveSlug(slug string) (int, string, error) {
+	var articleID int
+	var canonical string
+	err := r.db.QueryRow(resolveSlugQuery, slug, slug).Scan(&articleID, &canonical)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", fmt.Errorf("article not found")
+		}
+		return 0, "", fmt.Errorf("failed to resolve slug: %w", err)
+	}
+
+	return articleID, canonical, nil
+}
+
 // Delete deletes an article by slug
 func (r *ArticleRepository) Delete(slug string) error {
 	query := `DELETE FROM articles WHERE slug = ?`
@@ -185,14 +291,10 @@ func (r *ArticleRepository) SetArticleTags(articleID int, tagNames []string) err
 		err = tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
 		if err == sql.ErrNoRows {
 			// Create new tag
-			result, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
+			id, err := tx.InsertReturningID("INSERT INTO tags (name) VALUES (?)", tagName)
 			if err != nil {
 				return fmt.Errorf("failed to create tag %s: %w", tagName, err)
 			}
-			id, err := result.LastInsertId()
-			if err != nil {
-				return fmt.Errorf("failed to get tag ID: %w", err)
-			}
 			tagID = int(id)
 		} else if err != nil {
 			return fmt.Errorf("failed to get tag %s: %w", tagName, err)
@@ -219,59 +321,142 @@ func (r *ArticleRepository) CheckArticleExists(slug string) (bool, error) {
 	return count > 0, nil
 }
 
-// GetArticles retrieves articles with filtering and pagination
+// ArticleListOptions filters and orders the global article list
+type ArticleListOptions struct {
+	Limit     int
+	Offset    int
+	Tag       string
+	Author    string
+	Favorited string
+	// Sort is one of the model.ArticleSort values; empty means recent
+	Sort string
+	// Since restricts recent and top to articles created after it and updated
+	// to articles edited after it. For trending it bounds the activity that is
+	// scored, defaulting to the last trendingWindow.
+	Since *time.Time
+	// Before continues a recent ordering after the given cursor
+	Before *utils.Cursor
+	// Viewer leaves out the articles of authors they muted, unless Author
+	// asks for one of them; zero for anonymous readers
+	Viewer int
+}
+
+const (
+	// trendingWindow is how far back trending looks when no since is given
+	trendingWindow = 7 * 24 * time.Hour
+	// trendingHalfLifeHours is the age at which a favorite or comment counts half
+	trendingHalfLifeHours = 24
+	// trendingCommentWeight is how much a comment counts relative to a favorite
+	trendingCommentWeight = 2
+)
+
+// GetArticles retrieves published articles with filtering and pagination, newest first
 func (r *ArticleRepository) GetArticles(limit, offset int, tag, author, favorited string) ([]model.Article, int, error) {
-	// Build the base query
-	baseQuery := `
-		FROM articles a
-		LEFT JOIN article_tags at ON a.id = at.article_id
-		LEFT JOIN tags t ON at.tag_id = t.id
-		LEFT JOIN users u ON a.author_id = u.id
-		LEFT JOIN favorites f ON a.id = f.article_id
-	`
+	return r.ListArticles(ArticleListOptions{
+		Limit: limit, Offset: offset, Tag: tag, Author: author, Favorited: favorited,
+	})
+}
 
-	// Build WHERE conditions
-	conditions := []string{}
+// ListArticles retrieves published articles with filtering, ordering and pagination
+func (r *ArticleRepository) ListArticles(opts ArticleListOptions) ([]model.Article, int, error) {
+	dialect := r.db.Dialect()
+
+	// Build WHERE conditions (only published articles of active authors are listed)
+	conditions := []string{"a.status = 'published'", authorNotSuspended}
 	args := []interface{}{}
 
-	if tag != "" {
-		conditions = append(conditions, "t.name = ?")
-		args = append(args, tag)
+	if opts.Tag != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM article_tags at
+			INNER JOIN tags t ON t.id = at.tag_id
+			WHERE at.article_id = a.id AND t.name = ?
+		)`)
+		args = append(args, opts.Tag)
+	}
+
+	if opts.Author != "" {
+		conditions = append(conditions, "a.author_id = (SELECT id FROM users WHERE username = ?)")
+		args = append(args, opts.Author)
 	}
 
-	if author != "" {
-		conditions = append(conditions, "u.username = ?")
-		args = append(args, author)
+	if opts.Viewer > 0 && opts.Author == "" {
+		conditions = append(conditions, authorNotMuted)
+		args = append(args, opts.Viewer)
 	}
 
-	if favorited != "" {
-		conditions = append(conditions, "f.user_id = (SELECT id FROM users WHERE username = ?)")
-		args = append(args, favorited)
+	if opts.Favorited != "" {
+		conditions = append(conditions, `EXISTS (
+			SELECT 1 FROM favorites f
+			WHERE f.article_id = a.id AND f.user_id = (SELECT id FROM users WHERE username = ?)
+		)`)
+		args = append(args, opts.Favorited)
+	}
+
+	var orderBy string
+	var orderArgs []interface{}
+	switch opts.Sort {
+	case "", model.ArticleSortRecent:
+		orderBy = newestFirst(dialect, "a.created_at", "a.id")
+		if opts.Since != nil {
+			conditions = append(conditions, timeExpr(dialect, "a.created_at")+" >= "+timeExpr(dialect, "?"))
+			args = append(args, opts.Since.UTC())
+		}
+	case model.ArticleSortUpdated:
+		orderBy = timeExpr(dialect, "a.updated_at") + " DESC, a.id DESC"
+		if opts.Since != nil {
+			conditions = append(conditions, timeExpr(dialect, "a.updated_at")+" >= "+timeExpr(dialect, "?"))
+			args = append(args, opts.Since.UTC())
+		}
+	case model.ArticleSortTop:
+		orderBy = "a.favorites_count DESC, " + newestFirst(dialect, "a.created_at", "a.id")
+		if opts.Since != nil {
+			conditions = append(conditions, timeExpr(dialect, "a.created_at")+" >= "+timeExpr(dialect, "?"))
+			args = append(args, opts.Since.UTC())
+		}
+	case model.ArticleSortTrending:
+		now := time.Now().UTC()
+		since := now.Add(-trendingWindow)
+		if opts.Since != nil {
+			since = opts.Since.UTC()
+		}
+		orderBy = r.trendingScore() + " DESC, " + newestFirst(dialect, "a.created_at", "a.id")
+		orderArgs = []interface{}{now, since, now, since}
+	default:
+		return nil, 0, fmt.Errorf("invalid sort")
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	if opts.Before != nil && opts.Sort != "" && opts.Sort != model.ArticleSortRecent {
+		return nil, 0, fmt.Errorf("cursor requires sort=recent")
 	}
 
 	// Get total count
-	countQuery := "SELECT COUNT(DISTINCT a.id) " + baseQuery + " " + whereClause
+	countQuery := "SELECT COUNT(*) FROM articles a WHERE " + strings.Join(conditions, " AND ")
 	var totalCount int
 	err := r.db.QueryRow(countQuery, args...).Scan(&totalCount)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get articles count: %w", err)
 	}
 
+	// The cursor narrows the page but not the total
+	if opts.Before != nil {
+		condition, cursorArgs := keysetBefore(dialect, "a.created_at", "a.id", opts.Before)
+		conditions = append(conditions, condition)
+		args = append(args, cursorArgs...)
+	}
+	whereClause := "WHERE " + strings.Join(conditions, " AND ")
+
 	// Get articles
 	articlesQuery := `
-		SELECT DISTINCT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at, 
-		       COALESCE((SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id), 0) as favorites_count
-	` + baseQuery + " " + whereClause + `
-		ORDER BY a.created_at DESC
+		SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.status, a.publish_at, a.created_at, a.updated_at, 
+		       a.favorites_count
+		FROM articles a
+	` + whereClause + `
+		ORDER BY ` + orderBy + `
 		LIMIT ? OFFSET ?
 	`
 
-	args = append(args, limit, offset)
+	args = append(args, orderArgs...)
+	args = append(args, opts.Limit, opts.Offset)
 	rows, err := r.db.Query(articlesQuery, args...)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get articles: %w", err)
@@ -283,7 +468,7 @@ func (r *ArticleRepository) GetArticles(limit, offset int, tag, author, favorite
 		var article model.Article
 		err := rows.Scan(
 			&article.ID, &article.Slug, &article.Title, &article.Description,
-			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
+			&article.Body, &article.AuthorID, &article.Status, &article.PublishAt, &article.CreatedAt, &article.UpdatedAt,
 			&article.FavoritesCount,
 		)
 		if err != nil {
@@ -299,16 +484,56 @@ func (r *ArticleRepository) GetArticles(limit, offset int, tag, author, favorite
 	return articles, totalCount, nil
 }
 
+// trendingScore builds the trending score of article a: every favorite and
+// comment since the window start adds its weight divided by (1 + age in
+// half-lives), so recent activity dominates. It takes the arguments now,
+// since, now, since.
+func (r *ArticleRepository) trendingScore() string {
+	dialect := r.db.Dialect()
+	return fmt.Sprintf(`(
+		COALESCE((
+			SELECT SUM(%[1]d.0 / (%[1]d + %[2]s))
+			FROM favorites f
+			WHERE f.article_id = a.id AND %[3]s >= %[4]s
+		), 0) +
+		COALESCE((
+			SELECT SUM(%[5]d.0 * %[1]d / (%[1]d + %[6]s))
+			FROM comments c
+			WHERE c.article_id = a.id AND %[7]s >= %[4]s
+		), 0)
+	)`,
+		trendingHalfLifeHours, r.ageInHours("f.created_at"), timeExpr(dialect, "f.created_at"), timeExpr(dialect, "?"),
+		trendingCommentWeight, r.ageInHours("c.created_at"), timeExpr(dialect, "c.created_at"),
+	)
+}
+
+// ageInHours builds the age of a timestamp column in hours relative to a `?`
+// argument holding the current time
+func (r *ArticleRepository) ageInHours(column string) string {
+	if r.db.Dialect().Name() == "postgres" {
+		return "EXTRACT(EPOCH FROM (CAST(? AS TIMESTAMPTZ) - " + column + ")) / 3600"
+	}
+	return "(julianday(?) - julianday(" + column + ")) * 24"
+}
+
 // GetFeedArticles retrieves articles from followed users for personalized feed
 func (r *ArticleRepository) GetFeedArticles(limit, offset, userID int) ([]model.Article, int, error) {
+	return r.ListFeedArticles(userID, limit, offset, nil)
+}
+
+// ListFeedArticles retrieves the feed of userID newest first, continuing after
+// before when it is set. Authors they muted are left out.
+func (r *ArticleRepository) ListFeedArticles(userID, limit, offset int, before *utils.Cursor) ([]model.Article, int, error) {
+	dialect := r.db.Dialect()
+
 	// Build the base query for feed (articles from followed users)
 	baseQuery := `
 		FROM articles a
 		INNER JOIN follows f ON a.author_id = f.followed_id
-		WHERE f.follower_id = ?
+		WHERE f.follower_id = ? AND a.status = 'published' AND ` + authorNotSuspended + ` AND ` + authorNotMuted + `
 	`
 
-	args := []interface{}{userID}
+	args := []interface{}{userID, userID}
 
 	// Get total count
 	countQuery := "SELECT COUNT(a.id) " + baseQuery
@@ -318,12 +543,19 @@ func (r *ArticleRepository) GetFeedArticles(limit, offset, userID int) ([]model.
 		return nil, 0, fmt.Errorf("failed to get feed count: %w", err)
 	}
 
+	// The cursor narrows the page but not the total
+	if before != nil {
+		condition, cursorArgs := keysetBefore(dialect, "a.created_at", "a.id", before)
+		baseQuery += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
 	// Get articles
 	articlesQuery := `
-		SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at, 
-		       COALESCE((SELECT COUNT(*) FROM favorites f WHERE f.article_id = a.id), 0) as favorites_count
+		SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.status, a.publish_at, a.created_at, a.updated_at, 
+		       a.favorites_count
 	` + baseQuery + `
-		ORDER BY a.created_at DESC
+		ORDER BY ` + newestFirst(dialect, "a.created_at", "a.id") + `
 		LIMIT ? OFFSET ?
 	`
 
@@ -339,7 +571,7 @@ func (r *ArticleRepository) GetFeedArticles(limit, offset, userID int) ([]model.
 		var article model.Article
 		err := rows.Scan(
 			&article.ID, &article.Slug, &article.Title, &article.Description,
-			&article.Body, &article.AuthorID, &article.CreatedAt, &article.UpdatedAt,
+			&article.Body, &article.AuthorID, &article.Status, &article.PublishAt, &article.CreatedAt, &article.UpdatedAt,
 			&article.FavoritesCount,
 		)
 		if err != nil {
@@ -355,23 +587,122 @@ func (r *ArticleRepository) GetFeedArticles(limit, offset, userID int) ([]model.
 	return articles, totalCount, nil
 }
 
-// FavoriteArticle adds an article to user's favorites
+// GetDraftsByAuthor retrieves an author's draft articles, most recently edited first
+func (r *ArticleRepository) GetDraftsByAuthor(authorID, limit, offset int) ([]model.Article, int, error) {
+	dialect := r.db.Dialect()
+	baseQuery := `
+		FROM articles a
+		WHERE a.author_id = ? AND a.status = 'draft'
+	`
+
+	args := []interface{}{authorID}
+
+	// Get total count
+	countQuery := "SELECT COUNT(a.id) " + baseQuery
+	var totalCount int
+	err := r.db.QueryRow(countQuery, args...).Scan(&totalCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get drafts count: %w", err)
+	}
+
+	// Get articles
+	articlesQuery := `
+		SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.status, a.publish_at, a.created_at, a.updated_at,
+		       a.favorites_count
+	` + baseQuery + `
+		ORDER BY ` + timeExpr(dialect, "a.updated_at") + ` DESC, a.id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	args = append(args, limit, offset)
+	rows, err := r.db.Query(articlesQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var articles []model.Article
+	for rows.Next() {
+		var article model.Article
+		err := rows.Scan(
+			&article.ID, &article.Slug, &article.Title, &article.Description,
+			&article.Body, &article.AuthorID, &article.Status, &article.PublishAt, &article.CreatedAt, &article.UpdatedAt,
+			&article.FavoritesCount,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan draft: %w", err)
+		}
+		articles = append(articles, article)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate drafts: %w", err)
+	}
+
+	return articles, totalCount, nil
+}
+
+// PublishScheduled publishes a scheduled draft once its publish time has passed.
+// It reports false when the article was unpublished, rescheduled or already
+// published in the meantime, so stale publish jobs are harmless.
+func (r *ArticleRepository) PublishScheduled(articleID int, now time.Time) (bool, error) {
+	dialect := r.db.Dialect()
+	query := `
+		UPDATE articles
+		SET status = 'published', updated_at = ?
+		WHERE id = ? AND status = 'draft' AND publish_at IS NOT NULL
+			AND ` + timeExpr(dialect, "publish_at") + ` <= ` + timeExpr(dialect, "?") + `
+	`
+
+	result, err := r.db.Exec(query, time.Now(), articleID, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to publish scheduled article: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// FavoriteArticle adds an article to user's favorites and bumps its
+// favorites count in the same transaction
 func (r *ArticleRepository) FavoriteArticle(userID, articleID int) error {
-	query := `INSERT INTO favorites (user_id, article_id) VALUES (?, ?)`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	_, err := r.db.Exec(query, userID, articleID)
+	_, err = tx.Exec(`INSERT INTO favorites (user_id, article_id) VALUES (?, ?)`, userID, articleID)
 	if err != nil {
 		return fmt.Errorf("failed to favorite article: %w", err)
 	}
 
+	_, err = tx.Exec(`UPDATE articles SET favorites_count = favorites_count + 1 WHERE id = ?`, articleID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorites count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit favorite: %w", err)
+	}
+
 	return nil
 }
 
-// UnfavoriteArticle removes an article from user's favorites
+// UnfavoriteArticle removes an article from user's favorites and lowers its
+// favorites count in the same transaction
 func (r *ArticleRepository) UnfavoriteArticle(userID, articleID int) error {
-	query := `DELETE FROM favorites WHERE user_id = ? AND article_id = ?`
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	result, err := r.db.Exec(query, userID, articleID)
+	result, err := tx.Exec(`DELETE FROM favorites WHERE user_id = ? AND article_id = ?`, userID, articleID)
 	if err != nil {
 		return fmt.Errorf("failed to unfavorite article: %w", err)
 	}
@@ -385,6 +716,15 @@ func (r *ArticleRepository) UnfavoriteArticle(userID, articleID int) error {
 		return fmt.Errorf("favorite not found")
 	}
 
+	_, err = tx.Exec(`UPDATE articles SET favorites_count = favorites_count - 1 WHERE id = ? AND favorites_count > 0`, articleID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorites count: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit unfavorite: %w", err)
+	}
+
 	return nil
 }
 
@@ -403,13 +743,156 @@ func (r *ArticleRepository) IsFavorited(userID, articleID int) (bool, error) {
 
 // GetFavoritesCount returns the number of favorites for an article
 func (r *ArticleRepository) GetFavoritesCount(articleID int) (int, error) {
-	query := `SELECT COUNT(*) FROM favorites WHERE article_id = ?`
+	query := `SELECT favorites_count FROM articles WHERE id = ?`
 
 	var count int
 	err := r.db.QueryRow(query, articleID).Scan(&count)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("article not found")
+		}
 		return 0, fmt.Errorf("failed to get favorites count: %w", err)
 	}
 
 	return count, nil
 }
+
+// RecomputeFavoritesCounts rewrites the favorites count of every article whose
+// stored count has drifted from the favorites table, and reports how many
+// articles were fixed
+func (r *ArticleRepository) RecomputeFavoritesCounts() (int, error) {
+	query := `
+		UPDATE articles
+		SET favorites_count = (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id)
+		WHERE favorites_count <> (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id)
+	`
+
+	result, err := r.db.Exec(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute favorites counts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
+
+// SearchArticles runs a ranked full-text search over title, description and body.
+// The returned titles and snippets are HTML-escaped, with matched terms wrapped
+// in <mark> tags.
+func (r *ArticleRepository) SearchArticles(query string, limit, offset int) ([]model.ArticleSearchResult, int, error) {
+	var countQuery, searchQuery string
+	var match string
+
+	switch r.db.Dialect().Name() {
+	case "postgres":
+		match = query
+		countQuery = `
+			SELECT COUNT(*)
+			FROM articles a
+			WHERE a.search_vector @@ websearch_to_tsquery('english', ?) AND a.status = 'published' AND ` + authorNotSuspended + `
+		`
+		searchQuery = `
+			SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.status, a.publish_at, a.created_at, a.updated_at,
+			       a.favorites_count,
+			       ts_headline('english', a.title, q, 'StartSel="` + matchStart + `", StopSel="` + matchEnd + `", HighlightAll=true') as title_highlight,
+			       ts_headline('english', a.description || ' ' || a.body, q,
+			                   'StartSel="` + matchStart + `", StopSel="` + matchEnd + `", MaxWords=30, MinWords=10') as snippet
+			FROM articles a, websearch_to_tsquery('english', ?) q
+			WHERE a.search_vector @@ q AND a.status = 'published' AND ` + authorNotSuspended + `
+			ORDER BY ts_rank(a.search_vector, q) DESC, a.created_at DESC
+			LIMIT ? OFFSET ?
+		`
+	default:
+		match = ftsMatchQuery(query)
+		if match == "" {
+			return []model.ArticleSearchResult{}, 0, nil
+		}
+		countQuery = `
+			SELECT COUNT(*)
+			FROM articles_fts
+			INNER JOIN articles a ON a.id = articles_fts.rowid
+			WHERE articles_fts MATCH ? AND a.status = 'published' AND ` + authorNotSuspended + `
+		`
+		searchQuery = `
+			SELECT a.id, a.slug, a.title, a.description, a.body, a.author_id, a.status, a.publish_at, a.created_at, a.updated_at,
+			       a.favorites_count,
+			       highlight(articles_fts, 0, '` + matchStart + `', '` + matchEnd + `') as title_highlight,
+			       snippet(articles_fts, -1, '` + matchStart + `', '` + matchEnd + `', '...', 30) as snippet
+			FROM articles_fts
+			INNER JOIN articles a ON a.id = articles_fts.rowid
+			WHERE articles_fts MATCH ? AND a.status = 'published' AND ` + authorNotSuspended + `
+			ORDER BY bm25(articles_fts, 10.0, 5.0, 1.0), a.created_at DESC
+			LIMIT ? OFFSET ?
+		`
+	}
+
+	// Get total count
+	var totalCount int
+	if err := r.db.QueryRow(countQuery, match).Scan(&totalCount); err != nil {
+		return nil, 0, fmt.Errorf("failed to get search count: %w", err)
+	}
+
+	rows, err := r.db.Query(searchQuery, match, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search articles: %w", err)
+	}
+	defer rows.Close()
+
+	results := []model.ArticleSearchResult{}
+	for rows.Next() {
+		var result model.ArticleSearchResult
+		article := &result.Article
+		err := rows.Scan(
+			&article.ID, &article.Slug, &article.Title, &article.Description,
+			&article.Body, &article.AuthorID, &article.Status, &article.PublishAt, &article.CreatedAt, &article.UpdatedAt,
+			&article.FavoritesCount, &result.TitleHighlight, &result.Snippet,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan search result: %w", err)
+		}
+		result.TitleHighlight = markMatches(result.TitleHighlight)
+		result.Snippet = markMatches(result.Snippet)
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate search results: %w", err)
+	}
+
+	return results, totalCount, nil
+}
+
+// matchStart and matchEnd delimit matched terms in search highlights. They are
+// private-use characters so they survive HTML escaping of the article text.
+const (
+	matchStart = "\uE000"
+	matchEnd   = "\uE001"
+)
+
+// matchMarks turns the match delimiters into <mark> tags
+var matchMarks = strings.NewReplacer(matchStart, "<mark>", matchEnd, "</mark>")
+
+// markMatches escapes highlighted article text for HTML and then marks the
+// matched terms, so markup stored in articles is never rendered
+func markMatches(text string) string {
+	return matchMarks.Replace(html.EscapeString(text))
+}
+
+// ftsMatchQuery turns free text into an FTS MATCH expression that requires every
+// word, quoting each one so user input cannot inject FTS query syntax
+func ftsMatchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, `"`+word+`"`)
+	}
+
+	return strings.Join(terms, " ")
+}
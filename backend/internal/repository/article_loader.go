@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// ArticleBatch holds the data needed to render a page of articles
+type ArticleBatch struct {
+	Authors   map[int]*model.User // keyed by user ID
+	Tags      map[int][]string    // keyed by article ID
+	Favorited map[int]bool        // keyed by article ID, for the viewer
+	Following map[int]bool        // keyed by author ID, for the viewer
+}
+
+// ArticleLoader batch-loads authors, tags, favorited and following flags for a
+// page of articles in a constant number of queries, whatever the page size
+type ArticleLoader struct {
+	db       *db.Database
+	userRepo *UserRepository
+}
+
+// NewArticleLoader creates a new article loader
+func NewArticleLoader(database *db.Database) *ArticleLoader {
+	return &ArticleLoader{db: database, userRepo: NewUserRepository(database)}
+}
+
+// Load fetches the related data of articles as seen by viewerID. Anonymous
+// viewers (viewerID 0) skip the favorited and following lookups.
+func (l *ArticleLoader) Load(articles []model.Article, viewerID int) (*ArticleBatch, error) {
+	batch := &ArticleBatch{
+		Authors:   make(map[int]*model.User),
+		Tags:      make(map[int][]string),
+		Favorited: make(map[int]bool),
+		Following: make(map[int]bool),
+	}
+	if len(articles) == 0 {
+		return batch, nil
+	}
+
+	articleIDs := make([]int, 0, len(articles))
+	authorIDs := make([]int, 0, len(articles))
+	seenAuthors := make(map[int]bool)
+	for _, article := range articles {
+		articleIDs = append(articleIDs, article.ID)
+		if !seenAuthors[article.AuthorID] {
+			seenAuthors[article.AuthorID] = true
+			authorIDs = append(authorIDs, article.AuthorID)
+		}
+	}
+
+	if err := l.loadAuthors(batch, authorIDs); err != nil {
+		return nil, err
+	}
+	if err := l.loadTags(batch, articleIDs); err != nil {
+		return nil, err
+	}
+	if viewerID > 0 {
+		if err := l.loadFavorited(batch, viewerID, articleIDs); err != nil {
+			return nil, err
+		}
+		following, err := l.userRepo.GetFollowedAmong(viewerID, authorIDs)
+		if err != nil {
+			return nil, err
+		}
+		batch.Following = following
+	}
+
+	return batch, nil
+}
+
+// loadAuthors fetches the authors of the page
+func (l *ArticleLoader) loadAuthors(batch *ArticleBatch, authorIDs []int) error {
+	in, args := inClause(authorIDs)
+	query := `
+		SELECT id, email, username, password_hash, bio, image, created_at, updated_at
+		FROM users WHERE id IN (` + in + `)
+	`
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get authors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.Username, &user.PasswordHash,
+			&user.Bio, &user.Image, &user.CreatedAt, &user.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to scan author: %w", err)
+		}
+		batch.Authors[user.ID] = &user
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate authors: %w", err)
+	}
+
+	return nil
+}
+
+// loadTags fetches the tag lists of the page, each sorted by name
+func (l *ArticleLoader) loadTags(batch *ArticleBatch, articleIDs []int) error {
+	in, args := inClause(articleIDs)
+	query := `
+		SELECT at.article_id, t.name
+		FROM article_tags at
+		INNER JOIN tags t ON t.id = at.tag_id
+		WHERE at.article_id IN (` + in + `)
+		ORDER BY at.article_id, t.name ASC
+	`
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to get article tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int
+		var tag string
+		if err := rows.Scan(&articleID, &tag); err != nil {
+			return fmt.Errorf("failed to scan tag: %w", err)
+		}
+		batch.Tags[articleID] = append(batch.Tags[articleID], tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate tags: %w", err)
+	}
+
+	return nil
+}
+
+// loadFavorited fetches which articles of the page the viewer has favorited
+func (l *ArticleLoader) loadFavorited(batch *ArticleBatch, viewerID int, articleIDs []int) error {
+	in, args := inClause(articleIDs)
+	query := `SELECT article_id FROM favorites WHERE user_id = ? AND article_id IN (` + in + `)`
+
+	rows, err := l.db.Query(query, append([]interface{}{viewerID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("failed to get favorited articles: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var articleID int
+		if err := rows.Scan(&articleID); err != nil {
+			return fmt.Errorf("failed to scan favorited article: %w", err)
+		}
+		batch.Favorited[articleID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to iterate favorited articles: %w", err)
+	}
+
+	return nil
+}
+
+// inClause returns the placeholders and arguments for an IN (...) list of ids
+func inClause(ids []int) (string, []interface{}) {
+	args := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
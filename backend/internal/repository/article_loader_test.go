@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestArticleLoaderLoad(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		viewer := createTestUser(t, database, "viewer")
+		first := createTestArticle(t, database, alice.ID, "first", "sql", "go")
+		second := createTestArticle(t, database, bob.ID, "second")
+
+		if err := NewUserRepository(database).FollowUser(viewer.ID, alice.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+		if err := NewArticleRepository(database).FavoriteArticle(viewer.ID, second.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+
+		loader := NewArticleLoader(database)
+		batch, err := loader.Load([]model.Article{*first, *second}, viewer.ID)
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+
+		if batch.Authors[alice.ID].Username != "alice" || batch.Authors[bob.ID].Username != "bob" {
+			t.Errorf("Authors = %+v, want alice and bob", batch.Authors)
+		}
+		if !reflect.DeepEqual(batch.Tags[first.ID], []string{"go", "sql"}) || len(batch.Tags[second.ID]) != 0 {
+			t.Errorf("Tags = %v, want [go sql] for first only", batch.Tags)
+		}
+		if batch.Favorited[first.ID] || !batch.Favorited[second.ID] {
+			t.Errorf("Favorited = %v, want only second", batch.Favorited)
+		}
+		if !batch.Following[alice.ID] || batch.Following[bob.ID] {
+			t.Errorf("Following = %v, want only alice", batch.Following)
+		}
+
+		// Anonymous viewers get no favorited or following flags
+		anonymous, err := loader.Load([]model.Article{*first, *second}, 0)
+		if err != nil {
+			t.Fatalf("Load() anonymous error = %v", err)
+		}
+		if len(anonymous.Favorited) != 0 || len(anonymous.Following) != 0 {
+			t.Errorf("anonymous batch has viewer flags: %+v", anonymous)
+		}
+	})
+}
+
+func TestArticleLoaderQueryCountIsFlat(t *testing.T) {
+	database := openSQLite(t)
+	viewer := createTestUser(t, database, "viewer")
+	loader := NewArticleLoader(database)
+
+	var want int64
+	for _, size := range []int{1, 10, 100} {
+		articles := seedArticlePage(t, database, fmt.Sprintf("flat%d", size), size)
+
+		before := database.QueryCount()
+		if _, err := loader.Load(articles, viewer.ID); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		got := database.QueryCount() - before
+
+		if want == 0 {
+			want = got
+		}
+		if got != want {
+			t.Errorf("Load() of %d articles ran %d queries, want %d", size, got, want)
+		}
+	}
+}
+
+func BenchmarkArticleLoader(b *testing.B) {
+	for _, size := range []int{10, 50, 100} {
+		b.Run(fmt.Sprintf("page=%d", size), func(b *testing.B) {
+			database := openSQLite(b)
+			viewer := createTestUser(b, database, "viewer")
+			articles := seedArticlePage(b, database, "bench", size)
+			loader := NewArticleLoader(database)
+
+			before := database.QueryCount()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := loader.Load(articles, viewer.ID); err != nil {
+					b.Fatalf("Load() error = %v", err)
+				}
+			}
+			b.StopTimer()
+
+			b.ReportMetric(float64(database.QueryCount()-before)/float64(b.N), "queries/op")
+		})
+	}
+}
+
+// seedArticlePage creates size tagged articles, each by a different author
+func seedArticlePage(t testing.TB, database *db.Database, prefix string, size int) []model.Article {
+	t.Helper()
+
+	articles := make([]model.Article, 0, size)
+	for i := 0; i < size; i++ {
+		author := createTestUser(t, database, fmt.Sprintf("%s-author-%d", prefix, i))
+		article := createTestArticle(t, database, author.ID, fmt.Sprintf("%s-%d", prefix, i), "go", "sql")
+		articles = append(articles, *article)
+	}
+
+	return articles
+}
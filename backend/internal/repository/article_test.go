@@ -0,0 +1,698 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+func TestArticleRepositoryCreateUpdateDelete(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		if article.ID == 0 {
+			t.Fatal("Create() did not set article ID")
+		}
+
+		updated, err := repo.Update("first", map[string]interface{}{
+			"title": "Renamed",
+			"body":  "new body",
+		})
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if updated.Title != "Renamed" || updated.Body != "new body" {
+			t.Errorf("Update() = %q/%q, want Renamed/new body", updated.Title, updated.Body)
+		}
+
+		if err := repo.Delete("first"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if exists, _ := repo.CheckArticleExists("first"); exists {
+			t.Error("CheckArticleExists() = true after delete")
+		}
+	})
+}
+
+func TestArticleRepositoryGetArticlesFilters(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		first := createTestArticle(t, database, alice.ID, "first", "go")
+		createTestArticle(t, database, alice.ID, "second", "sql")
+		createTestArticle(t, database, bob.ID, "third", "go")
+
+		if err := repo.FavoriteArticle(bob.ID, first.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+
+		tests := []struct {
+			name                   string
+			tag, author, favorited string
+			wantCount              int
+		}{
+			{name: "all", wantCount: 3},
+			{name: "by tag", tag: "go", wantCount: 2},
+			{name: "by author", author: "alice", wantCount: 2},
+			{name: "by tag and author", tag: "go", author: "bob", wantCount: 1},
+			{name: "favorited by", favorited: "bob", wantCount: 1},
+		}
+
+		for _, tt := range tests {
+			articles, count, err := repo.GetArticles(20, 0, tt.tag, tt.author, tt.favorited)
+			if err != nil {
+				t.Fatalf("%s: GetArticles() error = %v", tt.name, err)
+			}
+			if count != tt.wantCount || len(articles) != tt.wantCount {
+				t.Errorf("%s: GetArticles() returned %d articles, count %d; want %d", tt.name, len(articles), count, tt.wantCount)
+			}
+		}
+
+		articles, _, err := repo.GetArticles(20, 0, "", "", "bob")
+		if err != nil {
+			t.Fatalf("GetArticles() error = %v", err)
+		}
+		if len(articles) != 1 || articles[0].FavoritesCount != 1 {
+			t.Errorf("GetArticles() favorited article = %+v, want favoritesCount 1", articles)
+		}
+	})
+}
+
+func TestArticleRepositoryFeedAndFavorites(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		users := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		article := createTestArticle(t, database, bob.ID, "bobs-post")
+		createTestArticle(t, database, alice.ID, "alices-post")
+
+		if err := users.FollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		feed, count, err := repo.GetFeedArticles(10, 0, alice.ID)
+		if err != nil {
+			t.Fatalf("GetFeedArticles() error = %v", err)
+		}
+		if count != 1 || len(feed) != 1 || feed[0].Slug != "bobs-post" {
+			t.Errorf("GetFeedArticles() = %+v (count %d), want only bobs-post", feed, count)
+		}
+
+		if err := repo.FavoriteArticle(alice.ID, article.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+		favorited, err := repo.IsFavorited(alice.ID, article.ID)
+		if err != nil || !favorited {
+			t.Errorf("IsFavorited() = %v, %v; want true, nil", favorited, err)
+		}
+		if n, _ := repo.GetFavoritesCount(article.ID); n != 1 {
+			t.Errorf("GetFavoritesCount() = %d, want 1", n)
+		}
+
+		if err := repo.UnfavoriteArticle(alice.ID, article.ID); err != nil {
+			t.Fatalf("UnfavoriteArticle() error = %v", err)
+		}
+		if err := repo.UnfavoriteArticle(alice.ID, article.ID); err == nil || err.Error() != "favorite not found" {
+			t.Errorf("UnfavoriteArticle() twice error = %v, want favorite not found", err)
+		}
+	})
+}
+
+func TestArticleRepositoryFavoritesCount(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "author")
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		article := createTestArticle(t, database, author.ID, "counted-post")
+
+		before, err := repo.GetBySlug("counted-post")
+		if err != nil {
+			t.Fatalf("GetBySlug() error = %v", err)
+		}
+
+		for _, user := range []*model.User{alice, bob} {
+			if err := repo.FavoriteArticle(user.ID, article.ID); err != nil {
+				t.Fatalf("FavoriteArticle() error = %v", err)
+			}
+		}
+		// A duplicate favorite fails and must not bump the counter
+		if err := repo.FavoriteArticle(alice.ID, article.ID); err == nil {
+			t.Fatal("FavoriteArticle() twice error = nil, want error")
+		}
+
+		got, err := repo.GetBySlug("counted-post")
+		if err != nil {
+			t.Fatalf("GetBySlug() error = %v", err)
+		}
+		if got.FavoritesCount != 2 {
+			t.Errorf("GetBySlug().FavoritesCount = %d, want 2", got.FavoritesCount)
+		}
+		if !got.UpdatedAt.Equal(before.UpdatedAt) {
+			t.Errorf("UpdatedAt changed from %v to %v on favorite", before.UpdatedAt, got.UpdatedAt)
+		}
+
+		articles, _, err := repo.GetArticles(10, 0, "", "", "")
+		if err != nil {
+			t.Fatalf("GetArticles() error = %v", err)
+		}
+		if len(articles) != 1 || articles[0].FavoritesCount != 2 {
+			t.Errorf("GetArticles() = %+v, want one article with 2 favorites", articles)
+		}
+
+		if err := repo.UnfavoriteArticle(bob.ID, article.ID); err != nil {
+			t.Fatalf("UnfavoriteArticle() error = %v", err)
+		}
+		if n, _ := repo.GetFavoritesCount(article.ID); n != 1 {
+			t.Errorf("GetFavoritesCount() = %d, want 1", n)
+		}
+
+		// Simulate drift, e.g. favorites removed by a cascading user delete
+		if _, err := database.Exec("UPDATE articles SET favorites_count = 7 WHERE id = ?", article.ID); err != nil {
+			t.Fatalf("failed to corrupt favorites count: %v", err)
+		}
+		fixed, err := repo.RecomputeFavoritesCounts()
+		if err != nil {
+			t.Fatalf("RecomputeFavoritesCounts() error = %v", err)
+		}
+		if fixed != 1 {
+			t.Errorf("RecomputeFavoritesCounts() = %d, want 1", fixed)
+		}
+		if n, _ := repo.GetFavoritesCount(article.ID); n != 1 {
+			t.Errorf("GetFavoritesCount() after repair = %d, want 1", n)
+		}
+		if fixed, _ := repo.RecomputeFavoritesCounts(); fixed != 0 {
+			t.Errorf("RecomputeFavoritesCounts() on consistent data = %d, want 0", fixed)
+		}
+	})
+}
+
+func TestArticleRepositoryListArticlesSort(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "author")
+		readers := []*model.User{
+			createTestUser(t, database, "reader1"),
+			createTestUser(t, database, "reader2"),
+			createTestUser(t, database, "reader3"),
+		}
+
+		now := time.Now().UTC()
+		oldPost := createTestArticle(t, database, author.ID, "old-post")
+		midPost := createTestArticle(t, database, author.ID, "mid-post")
+		newPost := createTestArticle(t, database, author.ID, "new-post")
+		backdate := func(article *model.Article, age time.Duration) {
+			at := now.Add(-age)
+			if _, err := database.Exec("UPDATE articles SET created_at = ?, updated_at = ? WHERE id = ?", at, at, article.ID); err != nil {
+				t.Fatalf("failed to backdate article: %v", err)
+			}
+		}
+		backdate(oldPost, 10*24*time.Hour)
+		backdate(midPost, 3*24*time.Hour)
+		backdate(newPost, time.Hour)
+
+		// The old post is the most favorited, but only long ago
+		for _, reader := range readers {
+			if err := repo.FavoriteArticle(reader.ID, oldPost.ID); err != nil {
+				t.Fatalf("FavoriteArticle() error = %v", err)
+			}
+		}
+		if _, err := database.Exec("UPDATE favorites SET created_at = ? WHERE article_id = ?", now.Add(-20*24*time.Hour), oldPost.ID); err != nil {
+			t.Fatalf("failed to backdate favorites: %v", err)
+		}
+		if err := repo.FavoriteArticle(readers[0].ID, midPost.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+		comment := &model.Comment{Body: "nice", AuthorID: readers[1].ID, ArticleID: newPost.ID}
+		if err := NewCommentRepository(database).Create(comment); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+
+		// Editing the old post makes it the most recently updated
+		if _, err := database.Exec("UPDATE articles SET updated_at = ? WHERE id = ?", now, oldPost.ID); err != nil {
+			t.Fatalf("failed to touch article: %v", err)
+		}
+		// An edit time written with another offset is ordered by instant
+		kst := time.FixedZone("KST", 9*3600)
+		if _, err := database.Exec("UPDATE articles SET updated_at = ? WHERE id = ?", now.Add(-2*time.Hour).In(kst), midPost.ID); err != nil {
+			t.Fatalf("failed to touch article: %v", err)
+		}
+
+		fiveDaysAgo := now.Add(-5 * 24 * time.Hour)
+		tests := []struct {
+			sort      string
+			since     *time.Time
+			want      []string
+			wantCount int
+		}{
+			{"", nil, []string{"new-post", "mid-post", "old-post"}, 3},
+			{model.ArticleSortRecent, &fiveDaysAgo, []string{"new-post", "mid-post"}, 2},
+			{model.ArticleSortTop, nil, []string{"old-post", "mid-post", "new-post"}, 3},
+			{model.ArticleSortTop, &fiveDaysAgo, []string{"mid-post", "new-post"}, 2},
+			{model.ArticleSortTrending, nil, []string{"new-post", "mid-post", "old-post"}, 3},
+			{model.ArticleSortUpdated, nil, []string{"old-post", "new-post", "mid-post"}, 3},
+		}
+
+		for _, tt := range tests {
+			articles, count, err := repo.ListArticles(ArticleListOptions{Limit: 10, Sort: tt.sort, Since: tt.since})
+			if err != nil {
+				t.Fatalf("ListArticles(%q) error = %v", tt.sort, err)
+			}
+			var got []string
+			for _, article := range articles {
+				got = append(got, article.Slug)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") || count != tt.wantCount {
+				t.Errorf("ListArticles(%q, since %v) = %v (count %d), want %v (count %d)",
+					tt.sort, tt.since != nil, got, count, tt.want, tt.wantCount)
+			}
+		}
+
+		if _, _, err := repo.ListArticles(ArticleListOptions{Limit: 10, Sort: "random"}); err == nil || err.Error() != "invalid sort" {
+			t.Errorf("ListArticles(random) error = %v, want invalid sort", err)
+		}
+	})
+}
+
+func TestArticleRepositoryCursorPagination(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "author")
+		reader := createTestUser(t, database, "reader")
+		if err := NewUserRepository(database).FollowUser(reader.ID, author.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		// Five articles where the middle three share a timestamp, so only the
+		// id keeps the order stable
+		base := time.Now().UTC().Add(-time.Hour)
+		offsets := []time.Duration{0, time.Minute, time.Minute, time.Minute, 2 * time.Minute}
+		for i, offset := range offsets {
+			article := createTestArticle(t, database, author.ID, fmt.Sprintf("post-%d", i))
+			if _, err := database.Exec("UPDATE articles SET created_at = ? WHERE id = ?", base.Add(offset), article.ID); err != nil {
+				t.Fatalf("failed to set created_at: %v", err)
+			}
+		}
+		want := "post-4,post-3,post-2,post-1,post-0"
+
+		pagers := []struct {
+			name string
+			page func(before *utils.Cursor) ([]model.Article, error)
+		}{
+			{"list", func(before *utils.Cursor) ([]model.Article, error) {
+				articles, _, err := repo.ListArticles(ArticleListOptions{Limit: 2, Before: before})
+				return articles, err
+			}},
+			{"feed", func(before *utils.Cursor) ([]model.Article, error) {
+				articles, _, err := repo.ListFeedArticles(reader.ID, 2, 0, before)
+				return articles, err
+			}},
+		}
+
+		for _, pager := range pagers {
+			var got []string
+			var before *utils.Cursor
+			for i := 0; i < 5; i++ {
+				articles, err := pager.page(before)
+				if err != nil {
+					t.Fatalf("%s: page %d error = %v", pager.name, i, err)
+				}
+				if len(articles) == 0 {
+					break
+				}
+				for _, article := range articles {
+					// Articles added by an earlier pager are newer than every
+					// post and only show up on a first page
+					if !strings.HasPrefix(article.Slug, "post-new-") {
+						got = append(got, article.Slug)
+					}
+				}
+				last := articles[len(articles)-1]
+				before = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+
+				// A new article arriving mid-pagination must not shift later pages
+				if i == 0 {
+					createTestArticle(t, database, author.ID, "post-new-"+pager.name)
+				}
+			}
+			if strings.Join(got, ",") != want {
+				t.Errorf("%s: paged articles = %v, want %s", pager.name, got, want)
+			}
+		}
+
+		if _, _, err := repo.ListArticles(ArticleListOptions{Limit: 2, Sort: model.ArticleSortTop, Before: &utils.Cursor{ID: 1}}); err == nil {
+			t.Error("ListArticles(top) with cursor error = nil, want error")
+		}
+	})
+}
+
+func TestArticleRepositorySearchArticles(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "alice")
+
+		inBody := createTestArticle(t, database, author.ID, "in-body")
+		inTitle := createTestArticle(t, database, author.ID, "in-title")
+		createTestArticle(t, database, author.ID, "unrelated")
+
+		if _, err := repo.Update(inBody.Slug, map[string]interface{}{
+			"body": "A long post that mentions goroutines once in passing",
+		}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if _, err := repo.Update(inTitle.Slug, map[string]interface{}{
+			"title": "Understanding <em>goroutines</em>",
+		}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		results, count, err := repo.SearchArticles("goroutines", 10, 0)
+		if err != nil {
+			t.Fatalf("SearchArticles() error = %v", err)
+		}
+		if count != 2 || len(results) != 2 {
+			t.Fatalf("SearchArticles() returned %d results, count %d; want 2", len(results), count)
+		}
+		if results[0].Article.Slug != "in-title" {
+			t.Errorf("SearchArticles() first result = %s, want title match ranked first", results[0].Article.Slug)
+		}
+		if results[0].TitleHighlight != "Understanding &lt;em&gt;<mark>goroutines</mark>&lt;/em&gt;" {
+			t.Errorf("SearchArticles() title highlight = %q, want escaped title with highlighted term", results[0].TitleHighlight)
+		}
+		if !strings.Contains(results[1].Snippet, "<mark>goroutines</mark>") {
+			t.Errorf("SearchArticles() snippet = %q, want highlighted term", results[1].Snippet)
+		}
+
+		page, count, err := repo.SearchArticles("goroutines", 1, 1)
+		if err != nil {
+			t.Fatalf("SearchArticles() page error = %v", err)
+		}
+		if count != 2 || len(page) != 1 || page[0].Article.Slug != "in-body" {
+			t.Errorf("SearchArticles() second page = %+v (count %d), want in-body", page, count)
+		}
+
+		// Deleted articles drop out of the index
+		if err := repo.Delete("in-title"); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, count, _ := repo.SearchArticles("goroutines", 10, 0); count != 1 {
+			t.Errorf("SearchArticles() after delete count = %d, want 1", count)
+		}
+
+		// Query syntax characters are treated as plain text
+		if _, _, err := repo.SearchArticles(`"unbalanced OR (`, 10, 0); err != nil {
+			t.Errorf("SearchArticles() with syntax characters error = %v", err)
+		}
+	})
+}
+
+func TestArticleRepositoryDraftsAreNotListed(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		tags := NewTagRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		createTestArticle(t, database, alice.ID, "published", "go")
+		draft := createTestArticle(t, database, alice.ID, "draft", "draft-only")
+		unlisted := createTestArticle(t, database, alice.ID, "unlisted", "go")
+
+		if _, err := repo.Update(draft.Slug, map[string]interface{}{"status": model.ArticleStatusDraft}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if _, err := repo.Update(unlisted.Slug, map[string]interface{}{"status": model.ArticleStatusUnlisted}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := NewUserRepository(database).FollowUser(bob.ID, alice.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		if _, count, _ := repo.GetArticles(20, 0, "", "", ""); count != 1 {
+			t.Errorf("GetArticles() count = %d, want only the published article", count)
+		}
+		if _, count, _ := repo.GetFeedArticles(20, 0, bob.ID); count != 1 {
+			t.Errorf("GetFeedArticles() count = %d, want only the published article", count)
+		}
+		if n, _ := tags.GetArticleCountByTag("go"); n != 1 {
+			t.Errorf("GetArticleCountByTag(go) = %d, want 1", n)
+		}
+		if popular, _ := tags.GetPopularTags(10); len(popular) != 1 || popular[0] != "go" {
+			t.Errorf("GetPopularTags() = %v, want [go]", popular)
+		}
+
+		drafts, count, err := repo.GetDraftsByAuthor(alice.ID, 20, 0)
+		if err != nil {
+			t.Fatalf("GetDraftsByAuthor() error = %v", err)
+		}
+		if count != 1 || len(drafts) != 1 || drafts[0].Slug != "draft" {
+			t.Errorf("GetDraftsByAuthor() = %+v (count %d), want only draft", drafts, count)
+		}
+		if _, count, _ := repo.GetDraftsByAuthor(bob.ID, 20, 0); count != 0 {
+			t.Errorf("GetDraftsByAuthor(bob) count = %d, want 0", count)
+		}
+
+		// Edit times written with different offsets are ordered by instant
+		older := createTestArticle(t, database, alice.ID, "older-draft")
+		if _, err := repo.Update(older.Slug, map[string]interface{}{"status": model.ArticleStatusDraft}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		now := time.Now()
+		if _, err := database.Exec("UPDATE articles SET updated_at = ? WHERE id = ?", now.In(time.FixedZone("KST", 9*3600)), older.ID); err != nil {
+			t.Fatalf("failed to set updated_at: %v", err)
+		}
+		if _, err := database.Exec("UPDATE articles SET updated_at = ? WHERE id = ?", now.UTC().Add(time.Minute), draft.ID); err != nil {
+			t.Fatalf("failed to set updated_at: %v", err)
+		}
+		drafts, _, err = repo.GetDraftsByAuthor(alice.ID, 20, 0)
+		if err != nil {
+			t.Fatalf("GetDraftsByAuthor() error = %v", err)
+		}
+		if len(drafts) != 2 || drafts[0].Slug != "draft" || drafts[1].Slug != "older-draft" {
+			t.Errorf("GetDraftsByAuthor() = %+v, want draft before older-draft", drafts)
+		}
+
+		got, err := repo.GetBySlug("unlisted")
+		if err != nil || got.Status != model.ArticleStatusUnlisted {
+			t.Errorf("GetBySlug(unlisted) = %+v, %v; want unlisted status", got, err)
+		}
+	})
+}
+
+func TestArticleRepositoryPublishScheduled(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "alice")
+
+		publishAt := time.Now().UTC().Add(time.Hour)
+		article := &model.Article{
+			Slug:        "scheduled",
+			Title:       "Scheduled",
+			Description: "description",
+			Body:        "body",
+			AuthorID:    author.ID,
+			Status:      model.ArticleStatusDraft,
+			PublishAt:   &publishAt,
+		}
+		if err := repo.Create(article); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+
+		// Not yet due
+		published, err := repo.PublishScheduled(article.ID, time.Now().UTC())
+		if err != nil {
+			t.Fatalf("PublishScheduled() error = %v", err)
+		}
+		if published {
+			t.Error("PublishScheduled() published an article before its publish time")
+		}
+
+		published, err = repo.PublishScheduled(article.ID, publishAt.Add(time.Second))
+		if err != nil {
+			t.Fatalf("PublishScheduled() error = %v", err)
+		}
+		if !published {
+			t.Fatal("PublishScheduled() = false after the publish time")
+		}
+
+		got, err := repo.GetBySlug("scheduled")
+		if err != nil {
+			t.Fatalf("GetBySlug() error = %v", err)
+		}
+		if got.Status != model.ArticleStatusPublished {
+			t.Errorf("Status = %q, want published", got.Status)
+		}
+		if got.PublishAt == nil || !got.PublishAt.Equal(publishAt) {
+			t.Errorf("PublishAt = %v, want %v", got.PublishAt, publishAt)
+		}
+
+		// Unscheduled drafts are left alone
+		if _, err := repo.Update("scheduled", map[string]interface{}{"status": "draft", "publish_at": nil}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if published, _ := repo.PublishScheduled(article.ID, publishAt.Add(time.Second)); published {
+			t.Error("PublishScheduled() published a draft without a schedule")
+		}
+
+		// Publish times stored with another offset are compared by instant
+		kstPublishAt := time.Now().Add(-time.Minute).In(time.FixedZone("KST", 9*3600))
+		kst := &model.Article{
+			Slug:        "scheduled-kst",
+			Title:       "Scheduled KST",
+			Description: "description",
+			Body:        "body",
+			AuthorID:    author.ID,
+			Status:      model.ArticleStatusDraft,
+			PublishAt:   &kstPublishAt,
+		}
+		if err := repo.Create(kst); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if published, err := repo.PublishScheduled(kst.ID, time.Now().UTC()); err != nil || !published {
+			t.Errorf("PublishScheduled() = %v, %v for a due publish time in KST, want true", published, err)
+		}
+	})
+}
+
+func TestArticleRepositorySlugHistory(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		for _, rename := range [][2]string{{"first", "second"}, {"second", "third"}} {
+			if _, err := repo.Update(rename[0], map[string]interface{}{"slug": rename[1]}); err != nil {
+				t.Fatalf("Update(%s -> %s) error = %v", rename[0], rename[1], err)
+			}
+		}
+
+		// Every earlier slug resolves to the current one
+		for _, slug := range []string{"first", "second", "third"} {
+			id, canonical, err := repo.ResolveSlug(slug)
+			if err != nil {
+				t.Fatalf("ResolveSlug(%s) error = %v", slug, err)
+			}
+			if id != article.ID || canonical != "third" {
+				t.Errorf("ResolveSlug(%s) = %d/%s, want %d/third", slug, id, canonical, article.ID)
+			}
+		}
+
+		// Going back to an old slug makes it current again
+		if _, err := repo.Update("third", map[string]interface{}{"slug": "first"}); err != nil {
+			t.Fatalf("Update(third -> first) error = %v", err)
+		}
+		if _, canonical, _ := repo.ResolveSlug("first"); canonical != "first" {
+			t.Errorf("ResolveSlug(first) = %s, want first", canonical)
+		}
+		if _, canonical, _ := repo.ResolveSlug("third"); canonical != "first" {
+			t.Errorf("ResolveSlug(third) = %s, want first", canonical)
+		}
+
+		if _, _, err := repo.ResolveSlug("missing"); err == nil || err.Error() != "article not found" {
+			t.Errorf("ResolveSlug(missing) error = %v, want article not found", err)
+		}
+	})
+}
+
+func TestArticleRepositorySuspendedAuthorsAreNotListed(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		users := NewUserRepository(database)
+		comments := NewCommentRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		createTestArticle(t, database, alice.ID, "alice-post", "go")
+		bobArticle := createTestArticle(t, database, bob.ID, "bob-post", "spam")
+
+		if _, err := repo.Update(bobArticle.Slug, map[string]interface{}{"title": "Cheap goroutines"}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		for _, author := range []int{alice.ID, bob.ID} {
+			if err := comments.Create(&model.Comment{Body: "hi", AuthorID: author, ArticleID: bobArticle.ID}); err != nil {
+				t.Fatalf("Create() comment error = %v", err)
+			}
+		}
+		if err := users.FollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+		if err := users.Suspend(bob.ID, "spam"); err != nil {
+			t.Fatalf("Suspend() error = %v", err)
+		}
+
+		if articles, count, _ := repo.GetArticles(20, 0, "", "", ""); count != 1 || articles[0].Slug != "alice-post" {
+			t.Errorf("GetArticles() = %+v (count %d), want only alice-post", articles, count)
+		}
+		if _, count, _ := repo.GetFeedArticles(20, 0, alice.ID); count != 0 {
+			t.Errorf("GetFeedArticles() count = %d, want 0", count)
+		}
+		if _, count, _ := repo.SearchArticles("goroutines", 20, 0); count != 0 {
+			t.Errorf("SearchArticles() count = %d, want 0", count)
+		}
+		if popular, _ := NewTagRepository(database).GetPopularTags(10); len(popular) != 1 || popular[0] != "go" {
+			t.Errorf("GetPopularTags() = %v, want [go]", popular)
+		}
+		if list, _ := comments.GetByArticleSlug("bob-post"); len(list) != 1 || list[0].Author.Username != "alice" {
+			t.Errorf("GetByArticleSlug() = %+v, want only the comment of alice", list)
+		}
+
+		if err := users.Reinstate(bob.ID); err != nil {
+			t.Fatalf("Reinstate() error = %v", err)
+		}
+		if _, count, _ := repo.GetArticles(20, 0, "", "", ""); count != 2 {
+			t.Errorf("GetArticles() after Reinstate() count = %d, want 2", count)
+		}
+	})
+}
+
+func TestArticleRepositoryMutedAuthorsAreNotListed(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewArticleRepository(database)
+		users := NewUserRepository(database)
+		comments := NewCommentRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		carol := createTestUser(t, database, "carol")
+		createTestArticle(t, database, alice.ID, "alice-post")
+		bobPost := createTestArticle(t, database, bob.ID, "bob-post")
+		for _, author := range []int{alice.ID, bob.ID} {
+			if err := comments.Create(&model.Comment{Body: "hi", AuthorID: author, ArticleID: bobPost.ID}); err != nil {
+				t.Fatalf("Create() comment error = %v", err)
+			}
+		}
+
+		if err := users.FollowUser(carol.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+		if err := users.MuteUser(carol.ID, bob.ID); err != nil {
+			t.Fatalf("MuteUser() error = %v", err)
+		}
+
+		if articles, count, _ := repo.ListArticles(ArticleListOptions{Limit: 20, Viewer: carol.ID}); count != 1 || articles[0].Slug != "alice-post" {
+			t.Errorf("ListArticles() for carol = %+v (count %d), want only alice-post", articles, count)
+		}
+		if _, count, _ := repo.ListArticles(ArticleListOptions{Limit: 20, Viewer: alice.ID}); count != 2 {
+			t.Errorf("ListArticles() for alice count = %d, want 2", count)
+		}
+		// Asking for the muted author by name still lists their articles
+		if _, count, _ := repo.ListArticles(ArticleListOptions{Limit: 20, Author: "bob", Viewer: carol.ID}); count != 1 {
+			t.Errorf("ListArticles(author bob) for carol count = %d, want 1", count)
+		}
+		if _, count, _ := repo.GetFeedArticles(20, 0, carol.ID); count != 0 {
+			t.Errorf("GetFeedArticles() for carol count = %d, want 0", count)
+		}
+
+		if list, _ := comments.ListByArticleSlug("bob-post", carol.ID, 0, nil); len(list) != 1 || list[0].Author.Username != "alice" {
+			t.Errorf("ListByArticleSlug() for carol = %+v, want only the comment of alice", list)
+		}
+		if list, _ := comments.GetByArticleSlug("bob-post"); len(list) != 2 {
+			t.Errorf("GetByArticleSlug() = %d comments, want 2", len(list))
+		}
+	})
+}
@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 type CommentRepository struct {
-	db *sql.DB
+	db *db.Database
 }
 
-func NewCommentRepository(db *sql.DB) *CommentRepository {
-	return &CommentRepository{db: db}
+func NewCommentRepository(database *db.Database) *CommentRepository {
+	return &CommentRepository{db: database}
 }
 
 func (r *CommentRepository) Create(comment *model.Comment) error {
@@ -26,34 +28,54 @@ func (r *CommentRepository) Create(comment *model.Comment) error {
 	comment.CreatedAt = now
 	comment.UpdatedAt = now
 
-	result, err := r.db.Exec(query,
+	id, err := r.db.InsertReturningID(query,
 		comment.Body, comment.AuthorID, comment.ArticleID,
 		comment.CreatedAt, comment.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create comment: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get comment ID: %w", err)
-	}
-
 	comment.ID = int(id)
 	return nil
 }
 
 func (r *CommentRepository) GetByArticleSlug(slug string) ([]*model.Comment, error) {
+	return r.ListByArticleSlug(slug, 0, 0, nil)
+}
+
+// ListByArticleSlug retrieves up to limit comments of an article newest first,
+// continuing after before when it is set. A limit of 0 returns them all.
+// Comments of suspended users are left out, as are those of users viewerID
+// muted.
+func (r *CommentRepository) ListByArticleSlug(slug string, viewerID, limit int, before *utils.Cursor) ([]*model.Comment, error) {
+	dialect := r.db.Dialect()
+
+	conditions := "a.slug = ? AND u.suspended_at IS NULL"
+	args := []interface{}{slug}
+	if viewerID > 0 {
+		conditions += " AND NOT EXISTS (SELECT 1 FROM user_mutes um WHERE um.muter_id = ? AND um.muted_id = c.author_id)"
+		args = append(args, viewerID)
+	}
+	if before != nil {
+		condition, cursorArgs := keysetBefore(dialect, "c.created_at", "c.id", before)
+		conditions += " AND " + condition
+		args = append(args, cursorArgs...)
+	}
+
 	query := `
 		SELECT c.id, c.body, c.author_id, c.article_id, c.created_at, c.updated_at,
 			   u.username, u.email, u.bio, u.image
 		FROM comments c
 		JOIN articles a ON c.article_id = a.id
 		JOIN users u ON c.author_id = u.id
-		WHERE a.slug = ?
-		ORDER BY c.created_at DESC
-	`
+		WHERE ` + conditions + `
+		ORDER BY ` + newestFirst(dialect, "c.created_at", "c.id")
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
 
-	rows, err := r.db.Query(query, slug)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query comments: %w", err)
 	}
@@ -134,6 +156,8 @@ func (r *CommentRepository) Delete(id int) error {
 	return nil
 }
 
+// GetArticleIDBySlug returns the id of the article currently using slug.
+// Old slugs are resolved with ArticleRepository.ResolveSlug.
 func (r *CommentRepository) GetArticleIDBySlug(slug string) (int, error) {
 	query := `SELECT id FROM articles WHERE slug = ?`
 
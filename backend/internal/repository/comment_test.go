@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+func TestCommentRepositoryLifecycle(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewCommentRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		articleID, err := repo.GetArticleIDBySlug("first")
+		if err != nil || articleID != article.ID {
+			t.Fatalf("GetArticleIDBySlug() = %d, %v; want %d, nil", articleID, err, article.ID)
+		}
+
+		comment := &model.Comment{Body: "nice", AuthorID: author.ID, ArticleID: article.ID}
+		if err := repo.Create(comment); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if comment.ID == 0 {
+			t.Fatal("Create() did not set comment ID")
+		}
+
+		comments, err := repo.GetByArticleSlug("first")
+		if err != nil {
+			t.Fatalf("GetByArticleSlug() error = %v", err)
+		}
+		if len(comments) != 1 || comments[0].Author.Username != "alice" {
+			t.Fatalf("GetByArticleSlug() = %+v, want one comment by alice", comments)
+		}
+
+		if err := repo.Delete(comment.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByID(comment.ID); err == nil || err.Error() != "comment not found" {
+			t.Errorf("GetByID() after delete error = %v, want comment not found", err)
+		}
+	})
+}
+
+func TestCommentRepositoryCursorPagination(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewCommentRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		var ids []int
+		for i := 0; i < 5; i++ {
+			comment := &model.Comment{Body: "comment", AuthorID: author.ID, ArticleID: article.ID}
+			if err := repo.Create(comment); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			ids = append(ids, comment.ID)
+		}
+		// Give two comments the same timestamp to exercise the id tiebreak
+		if _, err := database.Exec("UPDATE comments SET created_at = (SELECT created_at FROM comments WHERE id = ?) WHERE id = ?", ids[1], ids[2]); err != nil {
+			t.Fatalf("failed to align created_at: %v", err)
+		}
+
+		var got []int
+		var before *utils.Cursor
+		for {
+			comments, err := repo.ListByArticleSlug("first", 0, 2, before)
+			if err != nil {
+				t.Fatalf("ListByArticleSlug() error = %v", err)
+			}
+			if len(comments) == 0 {
+				break
+			}
+			for _, comment := range comments {
+				got = append(got, comment.ID)
+			}
+			last := comments[len(comments)-1]
+			before = &utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+
+		if len(got) != len(ids) {
+			t.Fatalf("paged comments = %v, want all of %v", got, ids)
+		}
+		seen := make(map[int]bool)
+		for _, id := range got {
+			if seen[id] {
+				t.Fatalf("paged comments = %v, repeats %d", got, id)
+			}
+			seen[id] = true
+		}
+		if got[0] != ids[4] || got[4] != ids[0] {
+			t.Errorf("paged comments = %v, want newest first", got)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// EmailVerificationRepository handles email verification token database operations
+type EmailVerificationRepository struct {
+	db *db.Database
+}
+
+// NewEmailVerificationRepository creates a new email verification repository
+func NewEmailVerificationRepository(database *db.Database) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: database}
+}
+
+// Create stores a new verification token for a user, using up the tokens
+// sent to them before so that only the latest email works
+func (r *EmailVerificationRepository) Create(token *model.EmailVerificationToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`UPDATE email_verification_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`, now, token.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO email_verification_tokens (user_id, email, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	token.CreatedAt = now
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.UsedAt = nil
+	id, err := tx.InsertReturningID(query, token.UserID, token.Email, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verification token: %w", err)
+	}
+	token.ID = int(id)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit verification token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume uses up the verification token with the given hash and marks the
+// address it was sent to as verified. A token for the pending email of the
+// user makes it their email. It returns the id of the user.
+func (r *EmailVerificationRepository) Consume(tokenHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT t.id, t.user_id, t.email, t.expires_at, t.used_at, u.email, u.pending_email
+		FROM email_verification_tokens t
+		INNER JOIN users u ON u.id = t.user_id
+		WHERE t.token_hash = ?
+	`
+
+	var token model.EmailVerificationToken
+	var email, pendingEmail string
+	err = tx.QueryRow(query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Email, &token.ExpiresAt, &token.UsedAt, &email, &pendingEmail,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("invalid verification token")
+		}
+		return 0, fmt.Errorf("failed to get verification token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if token.UsedAt != nil {
+		return 0, fmt.Errorf("invalid verification token")
+	}
+	if !token.ExpiresAt.After(now) {
+		return 0, fmt.Errorf("verification token expired")
+	}
+
+	// Guard against a concurrent use of the same token
+	result, err := tx.Exec(`UPDATE email_verification_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, token.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to use verification token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("invalid verification token")
+	}
+
+	switch token.Email {
+	case email:
+		_, err = tx.Exec(`UPDATE users SET email_verified = ? WHERE id = ?`, true, token.UserID)
+	case pendingEmail:
+		// The address may have been taken since the change was requested
+		var taken int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE email = ? AND id <> ?`, token.Email, token.UserID).Scan(&taken); err != nil {
+			return 0, fmt.Errorf("failed to check email existence: %w", err)
+		}
+		if taken > 0 {
+			return 0, fmt.Errorf("email already exists")
+		}
+		_, err = tx.Exec(`UPDATE users SET email = ?, pending_email = '', email_verified = ? WHERE id = ?`, token.Email, true, token.UserID)
+	default:
+		// The user has asked for another email since this token was sent
+		return 0, fmt.Errorf("invalid verification token")
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit email verification: %w", err)
+	}
+
+	return token.UserID, nil
+}
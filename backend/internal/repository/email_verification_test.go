@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestEmailVerificationRepositoryConsume(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewEmailVerificationRepository(database)
+		users := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+		expires := time.Now().Add(time.Hour)
+
+		if err := repo.Create(&model.EmailVerificationToken{UserID: user.ID, Email: user.Email, TokenHash: "signup", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("signup"); err != nil {
+			t.Fatalf("Consume() error = %v", err)
+		}
+		if got, _ := users.GetByID(user.ID); !got.EmailVerified || got.Email != user.Email {
+			t.Errorf("user after Consume() = %+v, want %s verified", got, user.Email)
+		}
+		if _, err := repo.Consume("signup"); err == nil || err.Error() != "invalid verification token" {
+			t.Errorf("Consume(used) error = %v, want invalid verification token", err)
+		}
+
+		// A changed email replaces the current one only once verified, and a
+		// token for an abandoned change stops working
+		user.PendingEmail = "first@example.com"
+		if err := users.Update(user); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := repo.Create(&model.EmailVerificationToken{UserID: user.ID, Email: "first@example.com", TokenHash: "first", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		user.PendingEmail = "second@example.com"
+		if err := users.Update(user); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := repo.Create(&model.EmailVerificationToken{UserID: user.ID, Email: "second@example.com", TokenHash: "second", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("first"); err == nil || err.Error() != "invalid verification token" {
+			t.Errorf("Consume(abandoned) error = %v, want invalid verification token", err)
+		}
+
+		if _, err := repo.Consume("second"); err != nil {
+			t.Fatalf("Consume(change) error = %v", err)
+		}
+		got, err := users.GetByID(user.ID)
+		if err != nil || got.Email != "second@example.com" || got.PendingEmail != "" || !got.EmailVerified {
+			t.Errorf("user after email change = %+v, %v; want second@example.com verified", got, err)
+		}
+	})
+}
+
+func TestEmailVerificationRepositoryConsumeTakenEmail(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewEmailVerificationRepository(database)
+		users := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+
+		alice.PendingEmail = bob.Email
+		if err := users.Update(alice); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := repo.Create(&model.EmailVerificationToken{UserID: alice.ID, Email: bob.Email, TokenHash: "taken", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("taken"); err == nil || err.Error() != "email already exists" {
+			t.Errorf("Consume(taken) error = %v, want email already exists", err)
+		}
+
+		if err := repo.Create(&model.EmailVerificationToken{UserID: alice.ID, Email: alice.Email, TokenHash: "stale", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("stale"); err == nil || err.Error() != "verification token expired" {
+			t.Errorf("Consume(expired) error = %v, want verification token expired", err)
+		}
+	})
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// ExportRepository reads everything a user has created, for their personal
+// data export
+type ExportRepository struct {
+	db *db.Database
+}
+
+// NewExportRepository creates a new export repository
+func NewExportRepository(database *db.Database) *ExportRepository {
+	return &ExportRepository{db: database}
+}
+
+// Articles retrieves every article of a user with its tags, drafts included,
+// oldest first
+func (r *ExportRepository) Articles(userID int) ([]model.ExportedArticle, error) {
+	query := `
+		SELECT id, slug, title, description, body, status, publish_at, favorites_count, created_at, updated_at
+		FROM articles WHERE author_id = ?
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export articles: %w", err)
+	}
+	defer rows.Close()
+
+	articles := []model.ExportedArticle{}
+	index := make(map[int]int)
+	for rows.Next() {
+		var id int
+		article := model.ExportedArticle{TagList: []string{}}
+		err := rows.Scan(&id, &article.Slug, &article.Title, &article.Description, &article.Body, &article.Status,
+			&article.PublishAt, &article.FavoritesCount, &article.CreatedAt, &article.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan exported article: %w", err)
+		}
+		index[id] = len(articles)
+		articles = append(articles, article)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate exported articles: %w", err)
+	}
+
+	query = `
+		SELECT at.article_id, t.name
+		FROM article_tags at
+		INNER JOIN tags t ON t.id = at.tag_id
+		INNER JOIN articles a ON a.id = at.article_id
+		WHERE a.author_id = ?
+		ORDER BY t.name
+	`
+
+	tagRows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export article tags: %w", err)
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var articleID int
+		var tag string
+		if err := tagRows.Scan(&articleID, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan exported tag: %w", err)
+		}
+		if i, ok := index[articleID]; ok {
+			articles[i].TagList = append(articles[i].TagList, tag)
+		}
+	}
+
+	return articles, tagRows.Err()
+}
+
+// Comments retrieves every comment of a user, oldest first
+func (r *ExportRepository) Comments(userID int) ([]model.ExportedComment, error) {
+	query := `
+		SELECT a.slug, c.body, c.created_at, c.updated_at
+		FROM comments c
+		INNER JOIN articles a ON a.id = c.article_id
+		WHERE c.author_id = ?
+		ORDER BY c.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []model.ExportedComment{}
+	for rows.Next() {
+		var comment model.ExportedComment
+		if err := rows.Scan(&comment.Article, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exported comment: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// Favorites retrieves the slugs of the articles a user favorited, oldest
+// first
+func (r *ExportRepository) Favorites(userID int) ([]model.ExportedLink, error) {
+	query := `
+		SELECT a.slug, f.created_at
+		FROM favorites f
+		INNER JOIN articles a ON a.id = f.article_id
+		WHERE f.user_id = ?
+		ORDER BY f.created_at, a.id
+	`
+	return r.links(query, userID, "favorites")
+}
+
+// Following retrieves the usernames of the users a user follows, oldest first
+func (r *ExportRepository) Following(userID int) ([]model.ExportedLink, error) {
+	query := `
+		SELECT u.username, f.created_at
+		FROM follows f
+		INNER JOIN users u ON u.id = f.followed_id
+		WHERE f.follower_id = ?
+		ORDER BY f.created_at, u.id
+	`
+	return r.links(query, userID, "following")
+}
+
+// Followers retrieves the usernames of the users following a user, oldest
+// first
+func (r *ExportRepository) Followers(userID int) ([]model.ExportedLink, error) {
+	query := `
+		SELECT u.username, f.created_at
+		FROM follows f
+		INNER JOIN users u ON u.id = f.follower_id
+		WHERE f.followed_id = ?
+		ORDER BY f.created_at, u.id
+	`
+	return r.links(query, userID, "followers")
+}
+
+// links runs a query for name and created_at pairs, naming what it exports in
+// errors
+func (r *ExportRepository) links(query string, userID int, what string) ([]model.ExportedLink, error) {
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export %s: %w", what, err)
+	}
+	defer rows.Close()
+
+	links := []model.ExportedLink{}
+	for rows.Next() {
+		var link model.ExportedLink
+		var createdAt sql.NullTime
+		if err := rows.Scan(&link.Name, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan exported %s: %w", what, err)
+		}
+		link.CreatedAt = createdAt.Time
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestExportRepository(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewExportRepository(database)
+		users := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		createTestArticle(t, database, alice.ID, "alice-post", "go", "db")
+		draft := createTestArticle(t, database, alice.ID, "alice-draft")
+		bobArticle := createTestArticle(t, database, bob.ID, "bob-post", "go")
+
+		if _, err := NewArticleRepository(database).Update(draft.Slug, map[string]interface{}{"status": model.ArticleStatusDraft}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if err := NewCommentRepository(database).Create(&model.Comment{Body: "nice", AuthorID: alice.ID, ArticleID: bobArticle.ID}); err != nil {
+			t.Fatalf("Create() comment error = %v", err)
+		}
+		if err := NewArticleRepository(database).FavoriteArticle(alice.ID, bobArticle.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+		if err := users.FollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		articles, err := repo.Articles(alice.ID)
+		if err != nil {
+			t.Fatalf("Articles() error = %v", err)
+		}
+		if len(articles) != 2 || articles[0].Slug != "alice-post" || len(articles[0].TagList) != 2 || articles[0].TagList[0] != "db" {
+			t.Errorf("Articles() = %+v, want alice-post tagged db, go and the draft", articles)
+		}
+		if len(articles) == 2 && (articles[1].Status != model.ArticleStatusDraft || len(articles[1].TagList) != 0) {
+			t.Errorf("Articles()[1] = %+v, want the untagged draft", articles[1])
+		}
+
+		if comments, err := repo.Comments(alice.ID); err != nil || len(comments) != 1 || comments[0].Article != "bob-post" {
+			t.Errorf("Comments() = %+v, %v; want the comment on bob-post", comments, err)
+		}
+		if favorites, err := repo.Favorites(alice.ID); err != nil || len(favorites) != 1 || favorites[0].Name != "bob-post" {
+			t.Errorf("Favorites() = %+v, %v; want bob-post", favorites, err)
+		}
+		if following, err := repo.Following(alice.ID); err != nil || len(following) != 1 || following[0].Name != "bob" {
+			t.Errorf("Following() = %+v, %v; want bob", following, err)
+		}
+		if followers, err := repo.Followers(bob.ID); err != nil || len(followers) != 1 || followers[0].Name != "alice" {
+			t.Errorf("Followers(bob) = %+v, %v; want alice", followers, err)
+		}
+		if followers, err := repo.Followers(alice.ID); err != nil || len(followers) != 0 {
+			t.Errorf("Followers(alice) = %+v, %v; want none", followers, err)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// JobRepository handles background job database operations
+type JobRepository struct {
+	db *db.Database
+}
+
+// NewJobRepository creates a new job repository
+func NewJobRepository(database *db.Database) *JobRepository {
+	return &JobRepository{db: database}
+}
+
+// Enqueue persists a pending job that becomes due at job.RunAt
+func (r *JobRepository) Enqueue(job *model.Job) error {
+	query := `
+		INSERT INTO jobs (type, payload, status, run_at, attempts, last_error, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, '', ?, ?)
+	`
+
+	now := time.Now().UTC()
+	job.Status = model.JobStatusPending
+	job.RunAt = job.RunAt.UTC()
+	job.Attempts = 0
+	job.LastError = ""
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.Payload == "" {
+		job.Payload = "{}"
+	}
+
+	id, err := r.db.InsertReturningID(query,
+		job.Type, job.Payload, job.Status, job.RunAt, job.CreatedAt, job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	job.ID = int(id)
+	return nil
+}
+
+// GetByID retrieves a job by ID
+func (r *JobRepository) GetByID(id int) (*model.Job, error) {
+	query := `
+		SELECT id, type, payload, status, run_at, attempts, last_error, created_at, updated_at
+		FROM jobs
+		WHERE id = ?
+	`
+
+	job := &model.Job{}
+	err := r.db.QueryRow(query, id).Scan(
+		&job.ID, &job.Type, &job.Payload, &job.Status, &job.RunAt,
+		&job.Attempts, &job.LastError, &job.CreatedAt, &job.UpdatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+
+	return job, nil
+}
+
+// GetDue retrieves up to limit pending jobs whose run time is at or before now, oldest first
+func (r *JobRepository) GetDue(now time.Time, limit int) ([]model.Job, error) {
+	dialect := r.db.Dialect()
+	query := `
+		SELECT id, type, payload, status, run_at, attempts, last_error, created_at, updated_at
+		FROM jobs
+		WHERE status = 'pending' AND ` + timeExpr(dialect, "run_at") + ` <= ` + timeExpr(dialect, "?") + `
+		ORDER BY ` + timeExpr(dialect, "run_at") + `, id
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, now.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []model.Job
+	for rows.Next() {
+		var job model.Job
+		err := rows.Scan(
+			&job.ID, &job.Type, &job.Payload, &job.Status, &job.RunAt,
+			&job.Attempts, &job.LastError, &job.CreatedAt, &job.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Claim marks a pending job as running and counts the attempt. It reports
+// false when the job is no longer pending.
+func (r *JobRepository) Claim(id int) (bool, error) {
+	query := `
+		UPDATE jobs
+		SET status = 'running', attempts = attempts + 1, updated_at = ?
+		WHERE id = ? AND status = 'pending'
+	`
+
+	result, err := r.db.Exec(query, time.Now().UTC(), id)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// Complete marks a job as done
+func (r *JobRepository) Complete(id int) error {
+	query := `UPDATE jobs SET status = 'done', last_error = '', updated_at = ? WHERE id = ?`
+
+	if _, err := r.db.Exec(query, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to complete job: %w", err)
+	}
+
+	return nil
+}
+
+// Retry puts a job back in the queue to run again at runAt
+func (r *JobRepository) Retry(id int, runAt time.Time, lastError string) error {
+	query := `UPDATE jobs SET status = 'pending', run_at = ?, last_error = ?, updated_at = ? WHERE id = ?`
+
+	if _, err := r.db.Exec(query, runAt.UTC(), lastError, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to reschedule job: %w", err)
+	}
+
+	return nil
+}
+
+// Fail marks a job as permanently failed
+func (r *JobRepository) Fail(id int, lastError string) error {
+	query := `UPDATE jobs SET status = 'failed', last_error = ?, updated_at = ? WHERE id = ?`
+
+	if _, err := r.db.Exec(query, lastError, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to mark job as failed: %w", err)
+	}
+
+	return nil
+}
+
+// RequeueRunning returns jobs left running by an interrupted process to the
+// queue and reports how many were requeued
+func (r *JobRepository) RequeueRunning() (int, error) {
+	query := `UPDATE jobs SET status = 'pending', updated_at = ? WHERE status = 'running'`
+
+	result, err := r.db.Exec(query, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue running jobs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return int(rowsAffected), nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestJobRepositoryLifecycle(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewJobRepository(database)
+		now := time.Now().UTC()
+
+		due := &model.Job{Type: "test", Payload: `{"n":1}`, RunAt: now.Add(-time.Minute)}
+		later := &model.Job{Type: "test", RunAt: now.Add(time.Hour)}
+		for _, job := range []*model.Job{due, later} {
+			if err := repo.Enqueue(job); err != nil {
+				t.Fatalf("Enqueue() error = %v", err)
+			}
+		}
+
+		jobs, err := repo.GetDue(now, 10)
+		if err != nil {
+			t.Fatalf("GetDue() error = %v", err)
+		}
+		if len(jobs) != 1 || jobs[0].ID != due.ID || jobs[0].Payload != `{"n":1}` {
+			t.Fatalf("GetDue() = %+v, want only job %d", jobs, due.ID)
+		}
+
+		claimed, err := repo.Claim(due.ID)
+		if err != nil || !claimed {
+			t.Fatalf("Claim() = %v, %v, want true", claimed, err)
+		}
+		if claimed, _ := repo.Claim(due.ID); claimed {
+			t.Error("Claim() succeeded twice for the same job")
+		}
+
+		// A crash while running puts the job back in the queue
+		requeued, err := repo.RequeueRunning()
+		if err != nil || requeued != 1 {
+			t.Fatalf("RequeueRunning() = %d, %v, want 1", requeued, err)
+		}
+
+		if err := repo.Retry(due.ID, now.Add(time.Hour), "boom"); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+		if jobs, _ := repo.GetDue(now, 10); len(jobs) != 0 {
+			t.Errorf("GetDue() after Retry() = %d jobs, want 0", len(jobs))
+		}
+
+		if err := repo.Complete(later.ID); err != nil {
+			t.Fatalf("Complete() error = %v", err)
+		}
+		if err := repo.Fail(due.ID, "gave up"); err != nil {
+			t.Fatalf("Fail() error = %v", err)
+		}
+
+		got, err := repo.GetByID(due.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.Status != model.JobStatusFailed || got.LastError != "gave up" || got.Attempts != 1 {
+			t.Errorf("job = %+v, want failed after 1 attempt", got)
+		}
+		if jobs, _ := repo.GetDue(now.Add(2*time.Hour), 10); len(jobs) != 0 {
+			t.Errorf("GetDue() returned finished jobs: %+v", jobs)
+		}
+	})
+}
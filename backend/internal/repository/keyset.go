@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// timeExpr wraps a timestamp column or placeholder so that it compares by
+// instant. SQLite stores timestamps as text whose offset depends on how the
+// row was written, so it compares julian day numbers instead.
+func timeExpr(dialect db.Dialect, expr string) string {
+	if dialect.Name() == "postgres" {
+		return expr
+	}
+	return "julianday(" + expr + ")"
+}
+
+// newestFirst is the ORDER BY clause matching keysetBefore for the given
+// creation time and id columns
+func newestFirst(dialect db.Dialect, createdAt, id string) string {
+	return timeExpr(dialect, createdAt) + " DESC, " + id + " DESC"
+}
+
+// keysetBefore builds the condition selecting rows that come after cursor in
+// a newestFirst ordering, with its arguments. Its leading range on the
+// creation time lets SQLite seek the julianday indexes to the cursor.
+func keysetBefore(dialect db.Dialect, createdAt, id string, cursor *utils.Cursor) (string, []interface{}) {
+	column, param := timeExpr(dialect, createdAt), timeExpr(dialect, "?")
+	condition := "(" + column + " <= " + param + " AND (" + column + " < " + param + " OR " + id + " < ?))"
+	at := cursor.CreatedAt.UTC()
+	return condition, []interface{}{at, at, cursor.ID}
+}
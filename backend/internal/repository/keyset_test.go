@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+func TestKeysetPagesReadFromIndex(t *testing.T) {
+	database := openSQLite(t)
+	dialect := database.Dialect()
+	cursor := &utils.Cursor{CreatedAt: time.Now(), ID: 10}
+
+	articlesBefore, articleArgs := keysetBefore(dialect, "a.created_at", "a.id", cursor)
+	commentsBefore, commentArgs := keysetBefore(dialect, "c.created_at", "c.id", cursor)
+
+	tests := []struct {
+		name  string
+		query string
+		args  []interface{}
+		index string
+	}{
+		{
+			name: "articles",
+			query: `SELECT a.id FROM articles a
+				WHERE a.status = 'published' AND ` + authorNotSuspended + ` AND ` + articlesBefore + `
+				ORDER BY ` + newestFirst(dialect, "a.created_at", "a.id") + ` LIMIT 20`,
+			args:  articleArgs,
+			index: "idx_articles_status_created_instant",
+		},
+		{
+			name: "comments",
+			query: `SELECT c.id FROM comments c
+				JOIN articles a ON c.article_id = a.id
+				JOIN users u ON c.author_id = u.id
+				WHERE a.slug = ? AND u.suspended_at IS NULL AND ` + commentsBefore + `
+				ORDER BY ` + newestFirst(dialect, "c.created_at", "c.id") + ` LIMIT 20`,
+			args:  append([]interface{}{"first"}, commentArgs...),
+			index: "idx_comments_article_created_instant",
+		},
+	}
+
+	for _, tt := range tests {
+		// The cursor bounds the index search, so deep pages start where the
+		// previous one ended
+		plan := queryPlan(t, database, tt.query, tt.args...)
+		if !strings.Contains(plan, "USING INDEX "+tt.index) || !strings.Contains(plan, "<expr><") {
+			t.Errorf("%s page plan does not seek %s to the cursor:\n%s", tt.name, tt.index, plan)
+		}
+		if strings.Contains(plan, "TEMP B-TREE") {
+			t.Errorf("%s page plan sorts the rows:\n%s", tt.name, plan)
+		}
+	}
+}
+
+// queryPlan returns the steps SQLite's EXPLAIN QUERY PLAN reports for query,
+// one per line
+func queryPlan(t *testing.T, database *db.Database, query string, args ...interface{}) string {
+	t.Helper()
+
+	rows, err := database.Query("EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		t.Fatalf("EXPLAIN QUERY PLAN error = %v", err)
+	}
+	defer rows.Close()
+
+	var steps []string
+	for rows.Next() {
+		var id, parent, unused int
+		var detail string
+		if err := rows.Scan(&id, &parent, &unused, &detail); err != nil {
+			t.Fatalf("failed to scan query plan: %v", err)
+		}
+		steps = append(steps, detail)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read query plan: %v", err)
+	}
+	return strings.Join(steps, "\n")
+}
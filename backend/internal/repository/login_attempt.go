@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// LoginAttemptRepository handles the database operations of login throttling:
+// failed logins per account and per IP, lockout events and unlock tokens
+type LoginAttemptRepository struct {
+	db *db.Database
+}
+
+// NewLoginAttemptRepository creates a new login attempt repository
+func NewLoginAttemptRepository(database *db.Database) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: database}
+}
+
+// GetAccountState retrieves the failed logins of a user. Users without any
+// get an empty state.
+func (r *LoginAttemptRepository) GetAccountState(userID int) (*model.AccountLoginState, error) {
+	query := `SELECT user_id, failed_logins, last_failed_at, locked_until FROM account_login_states WHERE user_id = ?`
+
+	state := model.AccountLoginState{UserID: userID}
+	err := r.db.QueryRow(query, userID).Scan(&state.UserID, &state.FailedLogins, &state.LastFailedAt, &state.LockedUntil)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+
+	return &state, nil
+}
+
+// RecordAccountFailure counts a failed login of a user at the given time and
+// returns their failures since the last successful login or lockout
+func (r *LoginAttemptRepository) RecordAccountFailure(userID int, at time.Time) (int, error) {
+	query := `
+		INSERT INTO account_login_states (user_id, failed_logins, last_failed_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (user_id) DO UPDATE SET
+			failed_logins = account_login_states.failed_logins + 1,
+			last_failed_at = excluded.last_failed_at
+	`
+	if _, err := r.db.Exec(query, userID, at.UTC()); err != nil {
+		return 0, fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	var failures int
+	if err := r.db.QueryRow(`SELECT failed_logins FROM account_login_states WHERE user_id = ?`, userID).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("failed to get failed logins: %w", err)
+	}
+
+	return failures, nil
+}
+
+// LockAccount stops logins of a user until the given time, starting the count
+// of failures afresh for when the lockout ends
+func (r *LoginAttemptRepository) LockAccount(userID int, until time.Time) error {
+	query := `UPDATE account_login_states SET failed_logins = 0, locked_until = ? WHERE user_id = ?`
+
+	if _, err := r.db.Exec(query, until.UTC(), userID); err != nil {
+		return fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	return nil
+}
+
+// ResetAccount forgets the failed logins and lockout of a user
+func (r *LoginAttemptRepository) ResetAccount(userID int) error {
+	if _, err := r.db.Exec(`DELETE FROM account_login_states WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to reset login state: %w", err)
+	}
+
+	return nil
+}
+
+// RecordIPFailure stores a failed login from an IP address, pruning failures
+// from before pruneBefore
+func (r *LoginAttemptRepository) RecordIPFailure(ipAddress, email string, at, pruneBefore time.Time) error {
+	query := `INSERT INTO login_failures (ip_address, email, created_at) VALUES (?, ?, ?)`
+	if _, err := r.db.Exec(query, ipAddress, email, at.UTC()); err != nil {
+		return fmt.Errorf("failed to record failed login: %w", err)
+	}
+
+	query = `DELETE FROM login_failures WHERE ` + timeExpr(r.db.Dialect(), "created_at") + ` < ` + timeExpr(r.db.Dialect(), "?")
+	if _, err := r.db.Exec(query, pruneBefore.UTC()); err != nil {
+		return fmt.Errorf("failed to prune failed logins: %w", err)
+	}
+
+	return nil
+}
+
+// IPFailuresSince counts the failed logins from an IP address after since
+// and returns the time of the latest one
+func (r *LoginAttemptRepository) IPFailuresSince(ipAddress string, since time.Time) (int, *time.Time, error) {
+	dialect := r.db.Dialect()
+	query := `
+		SELECT created_at FROM login_failures
+		WHERE ip_address = ? AND ` + timeExpr(dialect, "created_at") + ` > ` + timeExpr(dialect, "?") + `
+		ORDER BY ` + timeExpr(dialect, "created_at") + ` DESC
+	`
+
+	rows, err := r.db.Query(query, ipAddress, since.UTC())
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count failed logins: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	var latest *time.Time
+	for rows.Next() {
+		var at time.Time
+		if err := rows.Scan(&at); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan failed login: %w", err)
+		}
+		if latest == nil {
+			latest = &at
+		}
+		count++
+	}
+
+	return count, latest, rows.Err()
+}
+
+// RecordLockout stores a lockout event
+func (r *LoginAttemptRepository) RecordLockout(event *model.LockoutEvent) error {
+	query := `
+		INSERT INTO lockout_events (user_id, ip_address, scope, locked_until, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	event.CreatedAt = time.Now().UTC()
+	event.LockedUntil = event.LockedUntil.UTC()
+	id, err := r.db.InsertReturningID(query, event.UserID, event.IPAddress, event.Scope, event.LockedUntil, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record lockout: %w", err)
+	}
+	event.ID = int(id)
+
+	return nil
+}
+
+// CreateUnlockToken stores a new unlock token for a user, using up the
+// tokens issued to them before so that only the latest email works
+func (r *LoginAttemptRepository) CreateUnlockToken(token *model.AccountUnlockToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`UPDATE account_unlock_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`, now, token.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate unlock tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO account_unlock_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	token.CreatedAt = now
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.UsedAt = nil
+	id, err := tx.InsertReturningID(query, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create unlock token: %w", err)
+	}
+	token.ID = int(id)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit unlock token: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeUnlockToken uses up the unlock token with the given hash and lifts
+// the lockout of its user. It returns the id of the user.
+func (r *LoginAttemptRepository) ConsumeUnlockToken(tokenHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, user_id, expires_at, used_at FROM account_unlock_tokens WHERE token_hash = ?`
+
+	var token model.AccountUnlockToken
+	err = tx.QueryRow(query, tokenHash).Scan(&token.ID, &token.UserID, &token.ExpiresAt, &token.UsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("invalid unlock token")
+		}
+		return 0, fmt.Errorf("failed to get unlock token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if token.UsedAt != nil {
+		return 0, fmt.Errorf("invalid unlock token")
+	}
+	if !token.ExpiresAt.After(now) {
+		return 0, fmt.Errorf("unlock token expired")
+	}
+
+	// Guard against a concurrent use of the same token
+	result, err := tx.Exec(`UPDATE account_unlock_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, token.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to use unlock token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("invalid unlock token")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM account_login_states WHERE user_id = ?`, token.UserID); err != nil {
+		return 0, fmt.Errorf("failed to unlock account: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit account unlock: %w", err)
+	}
+
+	return token.UserID, nil
+}
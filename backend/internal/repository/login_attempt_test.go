@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestLoginAttemptRepositoryAccountLockout(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewLoginAttemptRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		if state, err := repo.GetAccountState(user.ID); err != nil || state.FailedLogins != 0 || state.LockedUntil != nil {
+			t.Fatalf("GetAccountState() = %+v, %v; want an empty state", state, err)
+		}
+
+		for want := 1; want <= 3; want++ {
+			got, err := repo.RecordAccountFailure(user.ID, time.Now())
+			if err != nil || got != want {
+				t.Fatalf("RecordAccountFailure() = %d, %v; want %d", got, err, want)
+			}
+		}
+
+		until := time.Now().Add(time.Hour)
+		if err := repo.LockAccount(user.ID, until); err != nil {
+			t.Fatalf("LockAccount() error = %v", err)
+		}
+		state, err := repo.GetAccountState(user.ID)
+		if err != nil || state.FailedLogins != 0 || state.LockedUntil == nil || state.LockedUntil.Sub(until).Abs() > time.Second {
+			t.Fatalf("GetAccountState() after lock = %+v, %v; want locked with no failures", state, err)
+		}
+
+		if err := repo.RecordLockout(&model.LockoutEvent{UserID: &user.ID, Scope: model.LockoutScopeAccount, LockedUntil: until}); err != nil {
+			t.Fatalf("RecordLockout() error = %v", err)
+		}
+
+		// An unlock token lifts the lockout once
+		if err := repo.CreateUnlockToken(&model.AccountUnlockToken{UserID: user.ID, TokenHash: "unlock", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("CreateUnlockToken() error = %v", err)
+		}
+		if userID, err := repo.ConsumeUnlockToken("unlock"); err != nil || userID != user.ID {
+			t.Fatalf("ConsumeUnlockToken() = %d, %v; want %d", userID, err, user.ID)
+		}
+		if state, _ := repo.GetAccountState(user.ID); state.LockedUntil != nil {
+			t.Errorf("GetAccountState() after unlock = %+v, want unlocked", state)
+		}
+		if _, err := repo.ConsumeUnlockToken("unlock"); err == nil || err.Error() != "invalid unlock token" {
+			t.Errorf("ConsumeUnlockToken(used) error = %v, want invalid unlock token", err)
+		}
+	})
+}
+
+func TestLoginAttemptRepositoryIPFailures(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewLoginAttemptRepository(database)
+		now := time.Now()
+
+		old := now.Add(-time.Hour)
+		if err := repo.RecordIPFailure("10.0.0.1", "a@example.com", old, old.Add(-time.Hour)); err != nil {
+			t.Fatalf("RecordIPFailure() error = %v", err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := repo.RecordIPFailure("10.0.0.1", "b@example.com", now.Add(time.Duration(i)*time.Second), now.Add(-2*time.Hour)); err != nil {
+				t.Fatalf("RecordIPFailure() error = %v", err)
+			}
+		}
+		if err := repo.RecordIPFailure("10.0.0.2", "c@example.com", now, now.Add(-2*time.Hour)); err != nil {
+			t.Fatalf("RecordIPFailure() error = %v", err)
+		}
+
+		count, latest, err := repo.IPFailuresSince("10.0.0.1", now.Add(-time.Minute))
+		if err != nil || count != 2 || latest == nil || latest.Sub(now.Add(time.Second)).Abs() > time.Millisecond {
+			t.Fatalf("IPFailuresSince() = %d, %v, %v; want 2 ending a second from now", count, latest, err)
+		}
+
+		// Failures from before the window are pruned as new ones come in
+		if err := repo.RecordIPFailure("10.0.0.2", "c@example.com", now, now.Add(-time.Minute)); err != nil {
+			t.Fatalf("RecordIPFailure() error = %v", err)
+		}
+		if count, _, _ := repo.IPFailuresSince("10.0.0.1", now.Add(-24*time.Hour)); count != 2 {
+			t.Errorf("IPFailuresSince() after prune = %d, want 2", count)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// ModerationRepository handles the database operations of the moderation log
+type ModerationRepository struct {
+	db *db.Database
+}
+
+// NewModerationRepository creates a new moderation repository
+func NewModerationRepository(database *db.Database) *ModerationRepository {
+	return &ModerationRepository{db: database}
+}
+
+// Record stores a moderation action
+func (r *ModerationRepository) Record(action *model.ModerationAction) error {
+	query := `
+		INSERT INTO moderation_actions (actor_id, action, target_type, target_id, target_owner_id, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	action.CreatedAt = time.Now().UTC()
+	id, err := r.db.InsertReturningID(query, action.ActorID, action.Action, action.TargetType, action.TargetID, action.TargetOwnerID, action.Details, action.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation action: %w", err)
+	}
+	action.ID = int(id)
+
+	return nil
+}
+
+// List retrieves moderation actions, newest first, with the usernames of the
+// moderator and the owner of the content. Deleted users have empty usernames.
+func (r *ModerationRepository) List(limit, offset int) ([]model.ModerationAction, error) {
+	query := `
+		SELECT m.id, m.actor_id, m.action, m.target_type, m.target_id, m.target_owner_id, m.details, m.created_at,
+			actor.username, owner.username
+		FROM moderation_actions m
+		LEFT JOIN users actor ON actor.id = m.actor_id
+		LEFT JOIN users owner ON owner.id = m.target_owner_id
+		ORDER BY m.id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list moderation actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []model.ModerationAction{}
+	for rows.Next() {
+		var action model.ModerationAction
+		var actor, owner sql.NullString
+		err := rows.Scan(&action.ID, &action.ActorID, &action.Action, &action.TargetType, &action.TargetID,
+			&action.TargetOwnerID, &action.Details, &action.CreatedAt, &actor, &owner)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan moderation action: %w", err)
+		}
+		action.Actor = actor.String
+		action.TargetOwner = owner.String
+		actions = append(actions, action)
+	}
+
+	return actions, rows.Err()
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestModerationRepositoryRecordAndList(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewModerationRepository(database)
+		moderator := createTestUser(t, database, "mod")
+		author := createTestUser(t, database, "author")
+
+		for _, action := range []string{model.ModerationArticleUpdate, model.ModerationArticleDelete} {
+			err := repo.Record(&model.ModerationAction{
+				ActorID:       &moderator.ID,
+				Action:        action,
+				TargetType:    "article",
+				TargetID:      42,
+				TargetOwnerID: &author.ID,
+				Details:       "spam",
+			})
+			if err != nil {
+				t.Fatalf("Record(%s) error = %v", action, err)
+			}
+		}
+
+		actions, err := repo.List(10, 0)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(actions) != 2 || actions[0].Action != model.ModerationArticleDelete || actions[0].Actor != "mod" || actions[0].TargetOwner != "author" {
+			t.Fatalf("List() = %+v; want the delete by mod first", actions)
+		}
+
+		if actions, err := repo.List(10, 1); err != nil || len(actions) != 1 || actions[0].Action != model.ModerationArticleUpdate {
+			t.Errorf("List(offset 1) = %+v, %v; want the update", actions, err)
+		}
+	})
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// OIDCRepository handles the database operations of OpenID Connect logins:
+// linked identities, pending authorization requests and login codes
+type OIDCRepository struct {
+	db *db.Database
+}
+
+// NewOIDCRepository creates a new OIDC repository
+func NewOIDCRepository(database *db.Database) *OIDCRepository {
+	return &OIDCRepository{db: database}
+}
+
+// GetIdentity retrieves the identity an issuer knows by subject
+func (r *OIDCRepository) GetIdentity(issuer, subject string) (*model.UserIdentity, error) {
+	query := `SELECT id, user_id, issuer, subject, email, created_at FROM user_identities WHERE issuer = ? AND subject = ?`
+
+	var identity model.UserIdentity
+	err := r.db.QueryRow(query, issuer, subject).Scan(
+		&identity.ID, &identity.UserID, &identity.Issuer, &identity.Subject, &identity.Email, &identity.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("identity not found")
+		}
+		return nil, fmt.Errorf("failed to get identity: %w", err)
+	}
+
+	return &identity, nil
+}
+
+// LinkIdentity links an identity to an existing user
+func (r *OIDCRepository) LinkIdentity(identity *model.UserIdentity) error {
+	query := `INSERT INTO user_identities (user_id, issuer, subject, email, created_at) VALUES (?, ?, ?, ?, ?)`
+
+	identity.CreatedAt = time.Now().UTC()
+	id, err := r.db.InsertReturningID(query, identity.UserID, identity.Issuer, identity.Subject, identity.Email, identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	identity.ID = int(id)
+
+	return nil
+}
+
+// CreateUserWithIdentity creates a user signing up through an issuer together
+// with their identity, so that no user is left without a way to log in
+func (r *OIDCRepository) CreateUserWithIdentity(user *model.User, identity *model.UserIdentity) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO users (email, username, password_hash, bio, image, email_verified, role)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	if user.Role == "" {
+		user.Role = model.RoleUser
+	}
+	userID, err := tx.InsertReturningID(query, user.Email, user.Username, user.PasswordHash, user.Bio, user.Image, user.EmailVerified, user.Role)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	query = `INSERT INTO user_identities (user_id, issuer, subject, email, created_at) VALUES (?, ?, ?, ?, ?)`
+	identity.UserID = int(userID)
+	identity.CreatedAt = time.Now().UTC()
+	identityID, err := tx.InsertReturningID(query, identity.UserID, identity.Issuer, identity.Subject, identity.Email, identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user: %w", err)
+	}
+
+	user.ID = int(userID)
+	identity.ID = int(identityID)
+	return nil
+}
+
+// CreateState stores a pending authorization request, pruning the expired
+// ones left by logins that were never completed
+func (r *OIDCRepository) CreateState(state *model.OIDCState) error {
+	now := time.Now().UTC()
+
+	query := `DELETE FROM oidc_states WHERE ` + timeExpr(r.db.Dialect(), "expires_at") + ` < ` + timeExpr(r.db.Dialect(), "?")
+	if _, err := r.db.Exec(query, now); err != nil {
+		return fmt.Errorf("failed to prune login states: %w", err)
+	}
+
+	query = `
+		INSERT INTO oidc_states (state_hash, code_verifier, nonce, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	state.CreatedAt = now
+	state.ExpiresAt = state.ExpiresAt.UTC()
+	state.UsedAt = nil
+	id, err := r.db.InsertReturningID(query, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt, state.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create login state: %w", err)
+	}
+	state.ID = int(id)
+
+	return nil
+}
+
+// ConsumeState uses up the pending authorization request with the given state
+// hash and returns it
+func (r *OIDCRepository) ConsumeState(stateHash string) (*model.OIDCState, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, state_hash, code_verifier, nonce, expires_at, used_at, created_at FROM oidc_states WHERE state_hash = ?`
+
+	var state model.OIDCState
+	err = tx.QueryRow(query, stateHash).Scan(
+		&state.ID, &state.StateHash, &state.CodeVerifier, &state.Nonce, &state.ExpiresAt, &state.UsedAt, &state.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid login state")
+		}
+		return nil, fmt.Errorf("failed to get login state: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if state.UsedAt != nil {
+		return nil, fmt.Errorf("invalid login state")
+	}
+	if !state.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("login state expired")
+	}
+
+	// Guard against the issuer's redirect being replayed concurrently
+	result, err := tx.Exec(`UPDATE oidc_states SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, state.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use login state: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("invalid login state")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit login state: %w", err)
+	}
+
+	state.UsedAt = &now
+	return &state, nil
+}
+
+// CreateLoginCode stores a new login code for a user
+func (r *OIDCRepository) CreateLoginCode(code *model.OIDCLoginCode) error {
+	query := `
+		INSERT INTO oidc_login_codes (user_id, code_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	code.CreatedAt = time.Now().UTC()
+	code.ExpiresAt = code.ExpiresAt.UTC()
+	code.UsedAt = nil
+	id, err := r.db.InsertReturningID(query, code.UserID, code.CodeHash, code.ExpiresAt, code.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create login code: %w", err)
+	}
+	code.ID = int(id)
+
+	return nil
+}
+
+// ConsumeLoginCode uses up the login code with the given hash and returns the
+// id of its user
+func (r *OIDCRepository) ConsumeLoginCode(codeHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, user_id, expires_at, used_at FROM oidc_login_codes WHERE code_hash = ?`
+
+	var code model.OIDCLoginCode
+	err = tx.QueryRow(query, codeHash).Scan(&code.ID, &code.UserID, &code.ExpiresAt, &code.UsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("invalid login code")
+		}
+		return 0, fmt.Errorf("failed to get login code: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if code.UsedAt != nil {
+		return 0, fmt.Errorf("invalid login code")
+	}
+	if !code.ExpiresAt.After(now) {
+		return 0, fmt.Errorf("login code expired")
+	}
+
+	// Guard against a concurrent use of the same code
+	result, err := tx.Exec(`UPDATE oidc_login_codes SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, code.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to use login code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("invalid login code")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit login code: %w", err)
+	}
+
+	return code.UserID, nil
+}
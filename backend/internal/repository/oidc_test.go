@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestOIDCRepositoryIdentities(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewOIDCRepository(database)
+		issuer := "https://idp.example.com"
+
+		if _, err := repo.GetIdentity(issuer, "sub-1"); err == nil || err.Error() != "identity not found" {
+			t.Fatalf("GetIdentity(unknown) error = %v, want identity not found", err)
+		}
+
+		user := &model.User{Email: "jane@example.com", Username: "jane", PasswordHash: "!", EmailVerified: true}
+		identity := &model.UserIdentity{Issuer: issuer, Subject: "sub-1", Email: user.Email}
+		if err := repo.CreateUserWithIdentity(user, identity); err != nil {
+			t.Fatalf("CreateUserWithIdentity() error = %v", err)
+		}
+
+		got, err := repo.GetIdentity(issuer, "sub-1")
+		if err != nil || got.UserID != user.ID {
+			t.Fatalf("GetIdentity() = %+v, %v; want user %d", got, err, user.ID)
+		}
+		created, err := NewUserRepository(database).GetByID(user.ID)
+		if err != nil || !created.EmailVerified || created.Username != "jane" {
+			t.Fatalf("GetByID() = %+v, %v; want verified jane", created, err)
+		}
+
+		// Subjects are unique per issuer only
+		if err := repo.LinkIdentity(&model.UserIdentity{UserID: user.ID, Issuer: issuer, Subject: "sub-1"}); err == nil {
+			t.Error("LinkIdentity(duplicate) succeeded, want error")
+		}
+		other := createTestUser(t, database, "bob")
+		if err := repo.LinkIdentity(&model.UserIdentity{UserID: other.ID, Issuer: "https://other.example.com", Subject: "sub-1"}); err != nil {
+			t.Errorf("LinkIdentity(other issuer) error = %v", err)
+		}
+
+		// A failed identity insert leaves no user behind
+		orphan := &model.User{Email: "orphan@example.com", Username: "orphan", PasswordHash: "!"}
+		if err := repo.CreateUserWithIdentity(orphan, &model.UserIdentity{Issuer: issuer, Subject: "sub-1"}); err == nil {
+			t.Fatal("CreateUserWithIdentity(duplicate identity) succeeded, want error")
+		}
+		if exists, _ := NewUserRepository(database).UsernameExists("orphan"); exists {
+			t.Error("CreateUserWithIdentity() left a user without identity")
+		}
+	})
+}
+
+func TestOIDCRepositoryStatesAndLoginCodes(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewOIDCRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		state := &model.OIDCState{StateHash: "state", CodeVerifier: "verifier", Nonce: "nonce", ExpiresAt: time.Now().Add(time.Minute)}
+		if err := repo.CreateState(state); err != nil {
+			t.Fatalf("CreateState() error = %v", err)
+		}
+		expired := &model.OIDCState{StateHash: "expired", CodeVerifier: "verifier", Nonce: "nonce", ExpiresAt: time.Now().Add(-time.Minute)}
+		if err := repo.CreateState(expired); err != nil {
+			t.Fatalf("CreateState() error = %v", err)
+		}
+
+		got, err := repo.ConsumeState("state")
+		if err != nil || got.CodeVerifier != "verifier" || got.Nonce != "nonce" {
+			t.Fatalf("ConsumeState() = %+v, %v", got, err)
+		}
+		if _, err := repo.ConsumeState("state"); err == nil || err.Error() != "invalid login state" {
+			t.Errorf("ConsumeState(used) error = %v, want invalid login state", err)
+		}
+		if _, err := repo.ConsumeState("expired"); err == nil || err.Error() != "login state expired" {
+			t.Errorf("ConsumeState(expired) error = %v, want login state expired", err)
+		}
+
+		if err := repo.CreateLoginCode(&model.OIDCLoginCode{UserID: user.ID, CodeHash: "code", ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+			t.Fatalf("CreateLoginCode() error = %v", err)
+		}
+		if userID, err := repo.ConsumeLoginCode("code"); err != nil || userID != user.ID {
+			t.Fatalf("ConsumeLoginCode() = %d, %v; want %d", userID, err, user.ID)
+		}
+		if _, err := repo.ConsumeLoginCode("code"); err == nil || err.Error() != "invalid login code" {
+			t.Errorf("ConsumeLoginCode(used) error = %v, want invalid login code", err)
+		}
+	})
+}
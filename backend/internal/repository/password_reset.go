@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// PasswordResetRepository handles password reset token database operations
+type PasswordResetRepository struct {
+	db *db.Database
+}
+
+// NewPasswordResetRepository creates a new password reset repository
+func NewPasswordResetRepository(database *db.Database) *PasswordResetRepository {
+	return &PasswordResetRepository{db: database}
+}
+
+// Create stores a new reset token for a user, using up the tokens issued to
+// them before so that only the latest email works
+func (r *PasswordResetRepository) Create(token *model.PasswordResetToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = ? WHERE user_id = ? AND used_at IS NULL`, now, token.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	query := `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	token.CreatedAt = now
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.UsedAt = nil
+	id, err := tx.InsertReturningID(query, token.UserID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+	token.ID = int(id)
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit reset token: %w", err)
+	}
+
+	return nil
+}
+
+// Consume uses up the reset token with the given hash and sets the password
+// hash of its user. All sessions of the user are revoked, signing out whoever
+// may have known the old password. It returns the id of the user.
+func (r *PasswordResetRepository) Consume(tokenHash, passwordHash string) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `SELECT id, user_id, expires_at, used_at FROM password_reset_tokens WHERE token_hash = ?`
+
+	var token model.PasswordResetToken
+	err = tx.QueryRow(query, tokenHash).Scan(&token.ID, &token.UserID, &token.ExpiresAt, &token.UsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, fmt.Errorf("invalid reset token")
+		}
+		return 0, fmt.Errorf("failed to get reset token: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if token.UsedAt != nil {
+		return 0, fmt.Errorf("invalid reset token")
+	}
+	if !token.ExpiresAt.After(now) {
+		return 0, fmt.Errorf("reset token expired")
+	}
+
+	// Guard against a concurrent use of the same token
+	result, err := tx.Exec(`UPDATE password_reset_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, token.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to use reset token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, fmt.Errorf("invalid reset token")
+	}
+
+	if _, err := tx.Exec(`UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, token.UserID); err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`, now, token.UserID); err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit password reset: %w", err)
+	}
+
+	return token.UserID, nil
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestPasswordResetRepositoryConsume(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewPasswordResetRepository(database)
+		sessions := NewSessionRepository(database)
+		users := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+		expires := time.Now().Add(time.Hour)
+
+		session := &model.Session{UserID: user.ID}
+		if err := sessions.Create(session, &model.RefreshToken{TokenHash: "refresh", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create(session) error = %v", err)
+		}
+
+		// A newer token replaces the older one
+		if err := repo.Create(&model.PasswordResetToken{UserID: user.ID, TokenHash: "first", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := repo.Create(&model.PasswordResetToken{UserID: user.ID, TokenHash: "second", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("first", "new-hash"); err == nil || err.Error() != "invalid reset token" {
+			t.Errorf("Consume(replaced) error = %v, want invalid reset token", err)
+		}
+
+		userID, err := repo.Consume("second", "new-hash")
+		if err != nil || userID != user.ID {
+			t.Fatalf("Consume() = %d, %v; want %d, nil", userID, err, user.ID)
+		}
+		updated, err := users.GetByID(user.ID)
+		if err != nil || updated.PasswordHash != "new-hash" {
+			t.Errorf("password hash after Consume() = %q, %v; want new-hash", updated.PasswordHash, err)
+		}
+		if active, _ := sessions.IsActive(session.ID); active {
+			t.Error("IsActive() after password reset = true, want false")
+		}
+
+		if _, err := repo.Consume("second", "other-hash"); err == nil || err.Error() != "invalid reset token" {
+			t.Errorf("Consume(used) error = %v, want invalid reset token", err)
+		}
+		if _, err := repo.Consume("unknown", "other-hash"); err == nil || err.Error() != "invalid reset token" {
+			t.Errorf("Consume(unknown) error = %v, want invalid reset token", err)
+		}
+
+		if err := repo.Create(&model.PasswordResetToken{UserID: user.ID, TokenHash: "stale", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Consume("stale", "other-hash"); err == nil || err.Error() != "reset token expired" {
+			t.Errorf("Consume(expired) error = %v, want reset token expired", err)
+		}
+	})
+}
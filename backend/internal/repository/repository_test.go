@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// migrationsDir is the migrations root relative to this package
+const migrationsDir = "../../migrations"
+
+// forEachEngine runs fn against a freshly migrated SQLite database and, when
+// TEST_POSTGRES_URL is set, against an isolated schema in that PostgreSQL database
+func forEachEngine(t *testing.T, fn func(t *testing.T, database *db.Database)) {
+	t.Helper()
+
+	t.Run("sqlite", func(t *testing.T) {
+		fn(t, openSQLite(t))
+	})
+
+	t.Run("postgres", func(t *testing.T) {
+		url := os.Getenv("TEST_POSTGRES_URL")
+		if url == "" {
+			t.Skip("TEST_POSTGRES_URL not set")
+		}
+		fn(t, openPostgres(t, url))
+	})
+}
+
+// openSQLite creates a migrated SQLite database in a temporary directory
+func openSQLite(t testing.TB) *db.Database {
+	t.Helper()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.MigrateFrom(migrationsDir); err != nil {
+		t.Fatalf("failed to migrate sqlite: %v", err)
+	}
+
+	return database
+}
+
+// openPostgres creates a throwaway schema and a migrated connection bound to it
+func openPostgres(t testing.TB, url string) *db.Database {
+	t.Helper()
+
+	admin, err := db.NewDatabase(url)
+	if err != nil {
+		t.Fatalf("failed to open postgres: %v", err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	schema := "test_" + hex.EncodeToString(suffix)
+
+	if _, err := admin.Exec("CREATE SCHEMA " + schema); err != nil {
+		t.Fatalf("failed to create schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	separator := "?"
+	if strings.Contains(url, "?") {
+		separator = "&"
+	}
+
+	database, err := db.NewDatabase(url + separator + "search_path=" + schema)
+	if err != nil {
+		t.Fatalf("failed to open postgres schema: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.MigrateFrom(migrationsDir); err != nil {
+		t.Fatalf("failed to migrate postgres: %v", err)
+	}
+
+	return database
+}
+
+// createTestUser inserts a user with a unique email derived from username
+func createTestUser(t testing.TB, database *db.Database, username string) *model.User {
+	t.Helper()
+
+	user := &model.User{
+		Email:        username + "@example.com",
+		Username:     username,
+		PasswordHash: "hash",
+	}
+	if err := NewUserRepository(database).Create(user); err != nil {
+		t.Fatalf("failed to create user %s: %v", username, err)
+	}
+
+	return user
+}
+
+// createTestArticle inserts an article with the given tags for authorID
+func createTestArticle(t testing.TB, database *db.Database, authorID int, slug string, tags ...string) *model.Article {
+	t.Helper()
+
+	article := &model.Article{
+		Slug:        slug,
+		Title:       fmt.Sprintf("Title of %s", slug),
+		Description: "description",
+		Body:        "body",
+		AuthorID:    authorID,
+	}
+	if err := NewArticleRepository(database).Create(article); err != nil {
+		t.Fatalf("failed to create article %s: %v", slug, err)
+	}
+
+	if len(tags) > 0 {
+		if err := NewTagRepository(database).CreateTagsForArticle(article.ID, tags); err != nil {
+			t.Fatalf("failed to tag article %s: %v", slug, err)
+		}
+	}
+
+	return article
+}
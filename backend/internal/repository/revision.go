@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// RevisionRepository handles article revision database operations
+type RevisionRepository struct {
+	db *db.Database
+}
+
+// NewRevisionRepository creates a new revision repository
+func NewRevisionRepository(database *db.Database) *RevisionRepository {
+	return &RevisionRepository{db: database}
+}
+
+// Create records a snapshot of an article's content as its next revision.
+// Revisions are never updated once written.
+func (r *RevisionRepository) Create(revision *model.ArticleRevision) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertRevision(tx, revision); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit revision: %w", err)
+	}
+
+	return nil
+}
+
+// insertRevision records revision as the next revision of its article within
+// a transaction
+func insertRevision(tx *db.Tx, revision *model.ArticleRevision) error {
+	query := `
+		INSERT INTO article_revisions (article_id, revision, title, description, body, editor_id, created_at)
+		VALUES (?, (SELECT COALESCE(MAX(revision), 0) + 1 FROM article_revisions WHERE article_id = ?), ?, ?, ?, ?, ?)
+	`
+
+	revision.CreatedAt = time.Now()
+
+	id, err := tx.InsertReturningID(query,
+		revision.ArticleID, revision.ArticleID, revision.Title, revision.Description, revision.Body,
+		revision.EditorID, revision.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create revision: %w", err)
+	}
+
+	revision.ID = int(id)
+	return tx.QueryRow("SELECT revision FROM article_revisions WHERE id = ?", revision.ID).Scan(&revision.Revision)
+}
+
+// recordChangedRevision snapshots the content of the article at slug as its
+// next revision by editorID within a transaction, unless it matches the
+// latest revision
+func recordChangedRevision(tx *db.Tx, slug string, editorID int) error {
+	revision := &model.ArticleRevision{EditorID: editorID}
+	err := tx.QueryRow("SELECT id, title, description, body FROM articles WHERE slug = ?", slug).Scan(
+		&revision.ArticleID, &revision.Title, &revision.Description, &revision.Body)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("article not found")
+		}
+		return fmt.Errorf("failed to get article: %w", err)
+	}
+
+	var title, description, body string
+	err = tx.QueryRow(`
+		SELECT title, description, body FROM article_revisions
+		WHERE article_id = ?
+		ORDER BY revision DESC
+		LIMIT 1
+	`, revision.ArticleID).Scan(&title, &description, &body)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return fmt.Errorf("failed to get latest revision: %w", err)
+	case title == revision.Title && description == revision.Description && body == revision.Body:
+		return nil
+	}
+
+	if err := insertRevision(tx, revision); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
+// GetByArticle retrieves an article's revisions, newest first
+func (r *RevisionRepository) GetByArticle(articleID int) ([]model.ArticleRevision, error) {
+	query := `
+		SELECT ar.id, ar.article_id, ar.revision, ar.title, ar.description, ar.body,
+		       COALESCE(ar.editor_id, 0), COALESCE(u.username, ''), ar.created_at
+		FROM article_revisions ar
+		LEFT JOIN users u ON ar.editor_id = u.id
+		WHERE ar.article_id = ?
+		ORDER BY ar.revision DESC
+	`
+
+	rows, err := r.db.Query(query, articleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []model.ArticleRevision
+	for rows.Next() {
+		var revision model.ArticleRevision
+		err := rows.Scan(
+			&revision.ID, &revision.ArticleID, &revision.Revision, &revision.Title,
+			&revision.Description, &revision.Body, &revision.EditorID, &revision.EditorUsername,
+			&revision.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// GetByNumber retrieves revision n of an article
+func (r *RevisionRepository) GetByNumber(articleID, n int) (*model.ArticleRevision, error) {
+	query := `
+		SELECT ar.id, ar.article_id, ar.revision, ar.title, ar.description, ar.body,
+		       COALESCE(ar.editor_id, 0), COALESCE(u.username, ''), ar.created_at
+		FROM article_revisions ar
+		LEFT JOIN users u ON ar.editor_id = u.id
+		WHERE ar.article_id = ? AND ar.revision = ?
+	`
+
+	revision := &model.ArticleRevision{}
+	err := r.db.QueryRow(query, articleID, n).Scan(
+		&revision.ID, &revision.ArticleID, &revision.Revision, &revision.Title,
+		&revision.Description, &revision.Body, &revision.EditorID, &revision.EditorUsername,
+		&revision.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("revision not found")
+		}
+		return nil, fmt.Errorf("failed to get revision: %w", err)
+	}
+
+	return revision, nil
+}
+
+// GetLatestNumber returns the newest revision number of an article, or 0 if it has none
+func (r *RevisionRepository) GetLatestNumber(articleID int) (int, error) {
+	query := `SELECT COALESCE(MAX(revision), 0) FROM article_revisions WHERE article_id = ?`
+
+	var n int
+	if err := r.db.QueryRow(query, articleID).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to get latest revision: %w", err)
+	}
+
+	return n, nil
+}
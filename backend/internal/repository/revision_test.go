@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestRevisionRepositoryNumbering(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewRevisionRepository(database)
+		author := createTestUser(t, database, "alice")
+		first := createTestArticle(t, database, author.ID, "first")
+		second := createTestArticle(t, database, author.ID, "second")
+
+		for i, body := range []string{"v1", "v2", "v3"} {
+			revision := &model.ArticleRevision{
+				ArticleID:   first.ID,
+				Title:       "Title",
+				Description: "description",
+				Body:        body,
+				EditorID:    author.ID,
+			}
+			if err := repo.Create(revision); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+			if revision.Revision != i+1 {
+				t.Errorf("Create() revision = %d, want %d", revision.Revision, i+1)
+			}
+		}
+
+		// Numbering is per article
+		other := &model.ArticleRevision{ArticleID: second.ID, Title: "t", Description: "d", Body: "b", EditorID: author.ID}
+		if err := repo.Create(other); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if other.Revision != 1 {
+			t.Errorf("first revision of another article = %d, want 1", other.Revision)
+		}
+
+		revisions, err := repo.GetByArticle(first.ID)
+		if err != nil {
+			t.Fatalf("GetByArticle() error = %v", err)
+		}
+		if len(revisions) != 3 || revisions[0].Revision != 3 || revisions[0].EditorUsername != "alice" {
+			t.Errorf("GetByArticle() = %+v, want 3 revisions newest first", revisions)
+		}
+
+		revision, err := repo.GetByNumber(first.ID, 2)
+		if err != nil {
+			t.Fatalf("GetByNumber() error = %v", err)
+		}
+		if revision.Body != "v2" {
+			t.Errorf("GetByNumber() body = %q, want v2", revision.Body)
+		}
+
+		if _, err := repo.GetByNumber(first.ID, 9); err == nil || err.Error() != "revision not found" {
+			t.Errorf("GetByNumber() missing revision error = %v", err)
+		}
+
+		if latest, err := repo.GetLatestNumber(first.ID); err != nil || latest != 3 {
+			t.Errorf("GetLatestNumber() = %d, %v, want 3", latest, err)
+		}
+	})
+}
+
+func TestArticleRepositoryUpdateWithRevision(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		articles := NewArticleRepository(database)
+		revisions := NewRevisionRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		updated, err := articles.UpdateWithRevision("first", map[string]interface{}{"body": "edited"}, author.ID)
+		if err != nil {
+			t.Fatalf("UpdateWithRevision() error = %v", err)
+		}
+		if updated.Body != "edited" {
+			t.Errorf("UpdateWithRevision() body = %q, want edited", updated.Body)
+		}
+		if latest, _ := revisions.GetLatestNumber(article.ID); latest != 1 {
+			t.Fatalf("GetLatestNumber() after edit = %d, want 1", latest)
+		}
+
+		// Edits that leave the content as it was add no revision
+		for _, updates := range []map[string]interface{}{
+			{"status": model.ArticleStatusDraft},
+			{"body": "edited"},
+		} {
+			if _, err := articles.UpdateWithRevision("first", updates, author.ID); err != nil {
+				t.Fatalf("UpdateWithRevision(%v) error = %v", updates, err)
+			}
+		}
+		if latest, _ := revisions.GetLatestNumber(article.ID); latest != 1 {
+			t.Errorf("GetLatestNumber() after unchanged edits = %d, want 1", latest)
+		}
+
+		// A rename is recorded under the new slug
+		if _, err := articles.UpdateWithRevision("first", map[string]interface{}{"title": "Renamed", "slug": "renamed"}, author.ID); err != nil {
+			t.Fatalf("UpdateWithRevision() rename error = %v", err)
+		}
+		if revision, err := revisions.GetByNumber(article.ID, 2); err != nil || revision.Title != "Renamed" {
+			t.Errorf("GetByNumber(2) = %+v, %v; want the renamed content", revision, err)
+		}
+	})
+}
+
+func TestArticleRepositoryConcurrentUpdatesKeepRevisions(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		articles := NewArticleRepository(database)
+		revisions := NewRevisionRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first")
+
+		const editors = 8
+		var wg sync.WaitGroup
+		errs := make([]error, editors)
+		for i := 0; i < editors; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, errs[i] = articles.UpdateWithRevision("first", map[string]interface{}{"body": fmt.Sprintf("edit %d", i)}, author.ID)
+			}(i)
+		}
+		wg.Wait()
+
+		// An edit may lose to a concurrent one, but then it changes nothing:
+		// every applied edit has exactly one revision, numbered in order
+		applied := 0
+		for _, err := range errs {
+			if err == nil {
+				applied++
+			}
+		}
+		if applied == 0 {
+			t.Fatalf("no concurrent edit succeeded: %v", errs)
+		}
+
+		list, err := revisions.GetByArticle(article.ID)
+		if err != nil {
+			t.Fatalf("GetByArticle() error = %v", err)
+		}
+		if len(list) != applied {
+			t.Fatalf("GetByArticle() = %d revisions, want one per applied edit (%d)", len(list), applied)
+		}
+		for i, revision := range list {
+			if revision.Revision != applied-i {
+				t.Errorf("revision %d numbered %d, want %d", i, revision.Revision, applied-i)
+			}
+		}
+
+		current, err := articles.GetBySlug("first")
+		if err != nil {
+			t.Fatalf("GetBySlug() error = %v", err)
+		}
+		if list[0].Body != current.Body {
+			t.Errorf("latest revision body = %q, want the article body %q", list[0].Body, current.Body)
+		}
+	})
+}
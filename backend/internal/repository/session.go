@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// SessionRepository handles session and refresh token database operations
+type SessionRepository struct {
+	db *db.Database
+}
+
+// NewSessionRepository creates a new session repository
+func NewSessionRepository(database *db.Database) *SessionRepository {
+	return &SessionRepository{db: database}
+}
+
+// Create persists a new session together with its first refresh token
+func (r *SessionRepository) Create(session *model.Session, token *model.RefreshToken) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	session.CreatedAt = time.Now().UTC()
+	session.LastSeenAt = &session.CreatedAt
+	session.RevokedAt = nil
+	query := `
+		INSERT INTO sessions (user_id, user_agent, ip_address, created_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?)
+	`
+	id, err := tx.InsertReturningID(query,
+		session.UserID, session.UserAgent, session.IPAddress, session.CreatedAt, session.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	session.ID = int(id)
+
+	token.SessionID = session.ID
+	if err := r.insertRefreshToken(tx, token); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session: %w", err)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a session by ID
+func (r *SessionRepository) GetByID(id int) (*model.Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, created_at, last_seen_at, revoked_at
+		FROM sessions WHERE id = ?
+	`
+
+	session := &model.Session{}
+	err := r.db.QueryRow(query, id).Scan(
+		&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("session not found")
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	return session, nil
+}
+
+// IsActive reports whether a session exists and has not been revoked
+func (r *SessionRepository) IsActive(id int) (bool, error) {
+	query := `SELECT COUNT(*) FROM sessions WHERE id = ? AND revoked_at IS NULL`
+
+	var count int
+	if err := r.db.QueryRow(query, id).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// ListActiveByUser retrieves the sessions of a user that have not been revoked
+// and can still be refreshed, most recently used first
+func (r *SessionRepository) ListActiveByUser(userID int) ([]model.Session, error) {
+	dialect := r.db.Dialect()
+	lastSeen := timeExpr(dialect, "COALESCE(s.last_seen_at, s.created_at)")
+	query := `
+		SELECT s.id, s.user_id, s.user_agent, s.ip_address, s.created_at, s.last_seen_at, s.revoked_at
+		FROM sessions s
+		WHERE s.user_id = ? AND s.revoked_at IS NULL
+			AND EXISTS (
+				SELECT 1 FROM refresh_tokens rt
+				WHERE rt.session_id = s.id AND rt.used_at IS NULL
+					AND ` + timeExpr(dialect, "rt.expires_at") + ` > ` + timeExpr(dialect, "?") + `
+			)
+		ORDER BY ` + lastSeen + ` DESC, s.id DESC
+	`
+
+	rows, err := r.db.Query(query, userID, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []model.Session{}
+	for rows.Next() {
+		var session model.Session
+		err := rows.Scan(
+			&session.ID, &session.UserID, &session.UserAgent, &session.IPAddress,
+			&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch records that a session was used at the given time
+func (r *SessionRepository) Touch(id int, at time.Time) error {
+	query := `UPDATE sessions SET last_seen_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	if _, err := r.db.Exec(query, at.UTC(), id); err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	return nil
+}
+
+// Revoke ends a session so that its access and refresh tokens stop working
+func (r *SessionRepository) Revoke(id int) error {
+	query := `UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`
+
+	return r.revoke(query, time.Now().UTC(), id)
+}
+
+// RevokeForUser ends a session of the given user. Sessions of other users
+// are reported as not found.
+func (r *SessionRepository) RevokeForUser(id, userID int) error {
+	query := `UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`
+
+	return r.revoke(query, time.Now().UTC(), id, userID)
+}
+
+// revoke runs a session revocation, reporting a missing or already revoked
+// session as not found
+func (r *SessionRepository) revoke(query string, args ...interface{}) error {
+	result, err := r.db.Exec(query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("session not found")
+	}
+
+	return nil
+}
+
+// Rotate exchanges the refresh token with the given hash for next, which joins
+// the same session. A token can only be exchanged once: presenting a used
+// token again means it leaked, so the whole session is revoked.
+func (r *SessionRepository) Rotate(tokenHash string, next *model.RefreshToken) (*model.Session, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT rt.id, rt.expires_at, rt.used_at, s.id, s.user_id, s.created_at, s.revoked_at
+		FROM refresh_tokens rt
+		INNER JOIN sessions s ON s.id = rt.session_id
+		WHERE rt.token_hash = ?
+	`
+
+	var current model.RefreshToken
+	session := &model.Session{}
+	err = tx.QueryRow(query, tokenHash).Scan(
+		&current.ID, &current.ExpiresAt, &current.UsedAt,
+		&session.ID, &session.UserID, &session.CreatedAt, &session.RevokedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid refresh token")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if session.RevokedAt != nil {
+		return nil, fmt.Errorf("session revoked")
+	}
+
+	now := time.Now().UTC()
+	if current.UsedAt != nil {
+		return nil, r.revokeReused(tx, session.ID, now)
+	}
+
+	if !current.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("refresh token expired")
+	}
+
+	// Guard against a concurrent exchange of the same token
+	result, err := tx.Exec(`UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL`, now, current.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use refresh token: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, r.revokeReused(tx, session.ID, now)
+	}
+
+	next.SessionID = session.ID
+	if err := r.insertRefreshToken(tx, next); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit refresh token rotation: %w", err)
+	}
+
+	return session, nil
+}
+
+// revokeReused revokes a session whose refresh token was presented twice and
+// reports the reuse
+func (r *SessionRepository) revokeReused(tx *db.Tx, sessionID int, now time.Time) error {
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL`, now, sessionID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit session revocation: %w", err)
+	}
+
+	return fmt.Errorf("refresh token reused")
+}
+
+// insertRefreshToken stores a refresh token within a transaction
+func (r *SessionRepository) insertRefreshToken(tx *db.Tx, token *model.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (session_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	token.CreatedAt = time.Now().UTC()
+	token.ExpiresAt = token.ExpiresAt.UTC()
+	token.UsedAt = nil
+	id, err := tx.InsertReturningID(query, token.SessionID, token.TokenHash, token.ExpiresAt, token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	token.ID = int(id)
+	return nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestSessionRepositoryRotate(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewSessionRepository(database)
+		user := createTestUser(t, database, "alice")
+		expires := time.Now().Add(time.Hour)
+
+		session := &model.Session{UserID: user.ID}
+		if err := repo.Create(session, &model.RefreshToken{TokenHash: "first", ExpiresAt: expires}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if active, err := repo.IsActive(session.ID); err != nil || !active {
+			t.Fatalf("IsActive() = %v, %v; want true, nil", active, err)
+		}
+
+		rotated, err := repo.Rotate("first", &model.RefreshToken{TokenHash: "second", ExpiresAt: expires})
+		if err != nil {
+			t.Fatalf("Rotate() error = %v", err)
+		}
+		if rotated.ID != session.ID || rotated.UserID != user.ID {
+			t.Errorf("Rotate() session = %+v, want session %d of user %d", rotated, session.ID, user.ID)
+		}
+
+		if _, err := repo.Rotate("unknown", &model.RefreshToken{TokenHash: "x", ExpiresAt: expires}); err == nil || err.Error() != "invalid refresh token" {
+			t.Errorf("Rotate(unknown) error = %v, want invalid refresh token", err)
+		}
+
+		// Replaying the used token revokes the session, including the token
+		// that replaced it
+		if _, err := repo.Rotate("first", &model.RefreshToken{TokenHash: "third", ExpiresAt: expires}); err == nil || err.Error() != "refresh token reused" {
+			t.Fatalf("Rotate(reused) error = %v, want refresh token reused", err)
+		}
+		if active, _ := repo.IsActive(session.ID); active {
+			t.Error("IsActive() after reuse = true, want false")
+		}
+		if _, err := repo.Rotate("second", &model.RefreshToken{TokenHash: "fourth", ExpiresAt: expires}); err == nil || err.Error() != "session revoked" {
+			t.Errorf("Rotate(second) error = %v, want session revoked", err)
+		}
+	})
+}
+
+func TestSessionRepositoryExpiryAndRevoke(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewSessionRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		expired := &model.Session{UserID: user.ID}
+		if err := repo.Create(expired, &model.RefreshToken{TokenHash: "old", ExpiresAt: time.Now().Add(-time.Minute)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if _, err := repo.Rotate("old", &model.RefreshToken{TokenHash: "new", ExpiresAt: time.Now().Add(time.Hour)}); err == nil || err.Error() != "refresh token expired" {
+			t.Errorf("Rotate(expired) error = %v, want refresh token expired", err)
+		}
+
+		session := &model.Session{UserID: user.ID}
+		if err := repo.Create(session, &model.RefreshToken{TokenHash: "live", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if err := repo.Revoke(session.ID); err != nil {
+			t.Fatalf("Revoke() error = %v", err)
+		}
+		if active, _ := repo.IsActive(session.ID); active {
+			t.Error("IsActive() after Revoke() = true, want false")
+		}
+		if err := repo.Revoke(session.ID); err == nil || err.Error() != "session not found" {
+			t.Errorf("Revoke() twice error = %v, want session not found", err)
+		}
+		if _, err := repo.Rotate("live", &model.RefreshToken{TokenHash: "next", ExpiresAt: time.Now().Add(time.Hour)}); err == nil || err.Error() != "session revoked" {
+			t.Errorf("Rotate() after Revoke() error = %v, want session revoked", err)
+		}
+
+		got, err := repo.GetByID(session.ID)
+		if err != nil || got.RevokedAt == nil {
+			t.Errorf("GetByID() = %+v, %v; want a revoked session", got, err)
+		}
+	})
+}
+
+func TestSessionRepositoryListAndRevokeForUser(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewSessionRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		live := time.Now().Add(time.Hour)
+
+		laptop := &model.Session{UserID: alice.ID, UserAgent: "laptop", IPAddress: "10.0.0.1"}
+		phone := &model.Session{UserID: alice.ID, UserAgent: "phone", IPAddress: "10.0.0.2"}
+		stale := &model.Session{UserID: alice.ID, UserAgent: "old"}
+		other := &model.Session{UserID: bob.ID}
+		for _, c := range []struct {
+			session *model.Session
+			hash    string
+			expires time.Time
+		}{
+			{laptop, "laptop", live},
+			{phone, "phone", live},
+			{stale, "stale", time.Now().Add(-time.Minute)},
+			{other, "other", live},
+		} {
+			if err := repo.Create(c.session, &model.RefreshToken{TokenHash: c.hash, ExpiresAt: c.expires}); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+		}
+
+		// The laptop was used last, so it is listed first
+		if err := repo.Touch(laptop.ID, time.Now().Add(time.Minute)); err != nil {
+			t.Fatalf("Touch() error = %v", err)
+		}
+
+		sessions, err := repo.ListActiveByUser(alice.ID)
+		if err != nil {
+			t.Fatalf("ListActiveByUser() error = %v", err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != laptop.ID || sessions[1].ID != phone.ID {
+			t.Fatalf("ListActiveByUser() = %+v, want laptop then phone", sessions)
+		}
+		if sessions[0].UserAgent != "laptop" || sessions[0].IPAddress != "10.0.0.1" || sessions[0].LastSeenAt == nil {
+			t.Errorf("ListActiveByUser()[0] = %+v, want laptop client details", sessions[0])
+		}
+
+		if err := repo.RevokeForUser(other.ID, alice.ID); err == nil || err.Error() != "session not found" {
+			t.Errorf("RevokeForUser(other user) error = %v, want session not found", err)
+		}
+		if err := repo.RevokeForUser(phone.ID, alice.ID); err != nil {
+			t.Fatalf("RevokeForUser() error = %v", err)
+		}
+
+		sessions, err = repo.ListActiveByUser(alice.ID)
+		if err != nil || len(sessions) != 1 || sessions[0].ID != laptop.ID {
+			t.Errorf("ListActiveByUser() after revoke = %+v, %v; want laptop only", sessions, err)
+		}
+		if active, _ := repo.IsActive(other.ID); !active {
+			t.Error("IsActive(other user) = false, want true")
+		}
+	})
+}
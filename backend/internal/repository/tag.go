@@ -3,16 +3,18 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
 )
 
 // TagRepository handles tag database operations
 type TagRepository struct {
-	db *sql.DB
+	db *db.Database
 }
 
 // NewTagRepository creates a new tag repository
-func NewTagRepository(db *sql.DB) *TagRepository {
-	return &TagRepository{db: db}
+func NewTagRepository(database *db.Database) *TagRepository {
+	return &TagRepository{db: database}
 }
 
 // GetPopularTags retrieves popular tags ordered by usage count
@@ -21,6 +23,8 @@ func (r *TagRepository) GetPopularTags(limit int) ([]string, error) {
 		SELECT t.name
 		FROM tags t
 		INNER JOIN article_tags at ON t.id = at.tag_id
+		INNER JOIN articles a ON a.id = at.article_id
+		WHERE a.status = 'published' AND ` + authorNotSuspended + `
 		GROUP BY t.id, t.name
 		ORDER BY COUNT(at.article_id) DESC, t.name ASC
 		LIMIT ?
@@ -99,7 +103,7 @@ func (r *TagRepository) CreateTagsForArticle(articleID int, tagNames []string) e
 
 		// Link article to tag (ignore if already exists)
 		_, err = tx.Exec(
-			"INSERT OR IGNORE INTO article_tags (article_id, tag_id) VALUES (?, ?)",
+			r.db.Dialect().Upsert("article_tags", []string{"article_id", "tag_id"}, []string{"article_id", "tag_id"}, nil),
 			articleID, tagID,
 		)
 		if err != nil {
@@ -183,7 +187,8 @@ func (r *TagRepository) GetArticleCountByTag(tagName string) (int, error) {
 		SELECT COUNT(at.article_id)
 		FROM tags t
 		INNER JOIN article_tags at ON t.id = at.tag_id
-		WHERE t.name = ?
+		INNER JOIN articles a ON a.id = at.article_id
+		WHERE t.name = ? AND a.status = 'published'
 	`
 
 	var count int
@@ -226,7 +231,7 @@ func (r *TagRepository) TagExists(tagName string) (bool, error) {
 }
 
 // getOrCreateTag gets an existing tag ID or creates a new tag
-func (r *TagRepository) getOrCreateTag(tx *sql.Tx, tagName string) (int, error) {
+func (r *TagRepository) getOrCreateTag(tx *db.Tx, tagName string) (int, error) {
 	// Try to get existing tag
 	var tagID int
 	err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", tagName).Scan(&tagID)
@@ -239,15 +244,10 @@ func (r *TagRepository) getOrCreateTag(tx *sql.Tx, tagName string) (int, error)
 	}
 
 	// Create new tag
-	result, err := tx.Exec("INSERT INTO tags (name) VALUES (?)", tagName)
+	id, err := tx.InsertReturningID("INSERT INTO tags (name) VALUES (?)", tagName)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create tag: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("failed to get tag ID: %w", err)
-	}
-
 	return int(id), nil
 }
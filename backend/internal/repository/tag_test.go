@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+)
+
+func TestTagRepositoryCreateTagsIsIdempotent(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewTagRepository(database)
+		author := createTestUser(t, database, "alice")
+		article := createTestArticle(t, database, author.ID, "first", "go", "sql")
+
+		// Linking the same tags again must not fail or duplicate rows
+		if err := repo.CreateTagsForArticle(article.ID, []string{"go", "sql"}); err != nil {
+			t.Fatalf("CreateTagsForArticle() second call error = %v", err)
+		}
+
+		tags, err := repo.GetTagsForArticle(article.ID)
+		if err != nil {
+			t.Fatalf("GetTagsForArticle() error = %v", err)
+		}
+		if want := []string{"go", "sql"}; !reflect.DeepEqual(tags, want) {
+			t.Errorf("GetTagsForArticle() = %v, want %v", tags, want)
+		}
+	})
+}
+
+func TestTagRepositoryPopularTags(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewTagRepository(database)
+		author := createTestUser(t, database, "alice")
+		createTestArticle(t, database, author.ID, "first", "go", "sql")
+		createTestArticle(t, database, author.ID, "second", "go")
+		third := createTestArticle(t, database, author.ID, "third", "rust")
+
+		if err := repo.UpdateTagsForArticle(third.ID, []string{"go", "zig"}); err != nil {
+			t.Fatalf("UpdateTagsForArticle() error = %v", err)
+		}
+
+		tags, err := repo.GetPopularTags(10)
+		if err != nil {
+			t.Fatalf("GetPopularTags() error = %v", err)
+		}
+		if want := []string{"go", "sql", "zig"}; !reflect.DeepEqual(tags, want) {
+			t.Errorf("GetPopularTags() = %v, want %v", tags, want)
+		}
+
+		if err := repo.DeleteUnusedTags(); err != nil {
+			t.Fatalf("DeleteUnusedTags() error = %v", err)
+		}
+		if exists, _ := repo.TagExists("rust"); exists {
+			t.Error("DeleteUnusedTags() kept unused tag rust")
+		}
+	})
+}
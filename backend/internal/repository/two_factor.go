@@ -0,0 +1,326 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+// TwoFactorRepository handles TOTP secret, recovery code and login challenge
+// database operations
+type TwoFactorRepository struct {
+	db *db.Database
+}
+
+// NewTwoFactorRepository creates a new two-factor repository
+func NewTwoFactorRepository(database *db.Database) *TwoFactorRepository {
+	return &TwoFactorRepository{db: database}
+}
+
+// SaveSecret stores a new unconfirmed TOTP secret for a user, replacing an
+// earlier unconfirmed one
+func (r *TwoFactorRepository) SaveSecret(userID int, secret string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var confirmedAt *time.Time
+	err = tx.QueryRow(`SELECT confirmed_at FROM user_totp WHERE user_id = ?`, userID).Scan(&confirmedAt)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+	if err == nil && confirmedAt != nil {
+		return fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_totp WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to replace TOTP secret: %w", err)
+	}
+
+	query := `INSERT INTO user_totp (user_id, secret, created_at) VALUES (?, ?, ?)`
+	if _, err := tx.Exec(query, userID, secret, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to save TOTP secret: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit TOTP secret: %w", err)
+	}
+
+	return nil
+}
+
+// GetTOTP retrieves the TOTP secret of a user
+func (r *TwoFactorRepository) GetTOTP(userID int) (*model.TOTPCredential, error) {
+	query := `SELECT user_id, secret, confirmed_at, last_used_step, created_at FROM user_totp WHERE user_id = ?`
+
+	var credential model.TOTPCredential
+	err := r.db.QueryRow(query, userID).Scan(
+		&credential.UserID,
+		&credential.Secret,
+		&credential.ConfirmedAt,
+		&credential.LastUsedStep,
+		&credential.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("two-factor authentication not set up")
+		}
+		return nil, fmt.Errorf("failed to get TOTP secret: %w", err)
+	}
+
+	return &credential, nil
+}
+
+// IsEnabled reports whether a user logs in with two-factor authentication
+func (r *TwoFactorRepository) IsEnabled(userID int) (bool, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL`
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check two-factor authentication: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// Enable confirms the TOTP secret of a user with the time step of the first
+// code they entered and stores their recovery codes
+func (r *TwoFactorRepository) Enable(userID int, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	query := `UPDATE user_totp SET confirmed_at = ?, last_used_step = ? WHERE user_id = ? AND confirmed_at IS NULL`
+	result, err := tx.Exec(query, now, step, userID)
+	if err != nil {
+		return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// Disable removes the TOTP secret, recovery codes and pending login
+// challenges of a user
+func (r *TwoFactorRepository) Disable(userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, table := range []string{"user_totp", "recovery_codes", "two_factor_challenges"} {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE user_id = ?`, userID); err != nil {
+			return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit two-factor authentication: %w", err)
+	}
+
+	return nil
+}
+
+// UseStep records that the code of a time step was accepted for a user. It
+// fails for steps at or before the last accepted one, so that a code cannot
+// be replayed.
+func (r *TwoFactorRepository) UseStep(userID int, step int64) error {
+	query := `UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?`
+	result, err := r.db.Exec(query, step, userID, step)
+	if err != nil {
+		return fmt.Errorf("failed to use TOTP code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	return nil
+}
+
+// UseRecoveryCode uses up the recovery code of a user with the given hash
+func (r *TwoFactorRepository) UseRecoveryCode(userID int, codeHash string) error {
+	query := `UPDATE recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`
+	result, err := r.db.Exec(query, time.Now().UTC(), userID, codeHash)
+	if err != nil {
+		return fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid two-factor code")
+	}
+
+	return nil
+}
+
+// ReplaceRecoveryCodes swaps the recovery codes of a user for new ones
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID int, codeHashes []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userID, codeHashes, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of a user and inserts new
+// ones within tx
+func replaceRecoveryCodes(tx *db.Tx, userID int, codeHashes []string, now time.Time) error {
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		query := `INSERT INTO recovery_codes (user_id, code_hash, created_at) VALUES (?, ?, ?)`
+		if _, err := tx.Exec(query, userID, hash, now); err != nil {
+			return fmt.Errorf("failed to save recovery code: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CreateChallenge stores a new login challenge
+func (r *TwoFactorRepository) CreateChallenge(challenge *model.TwoFactorChallenge) error {
+	query := `
+		INSERT INTO two_factor_challenges (user_id, token_hash, expires_at, created_at)
+		VALUES (?, ?, ?, ?)
+	`
+
+	challenge.CreatedAt = time.Now().UTC()
+	challenge.ExpiresAt = challenge.ExpiresAt.UTC()
+	challenge.UsedAt = nil
+	challenge.Attempts = 0
+	id, err := r.db.InsertReturningID(query, challenge.UserID, challenge.TokenHash, challenge.ExpiresAt, challenge.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create login challenge: %w", err)
+	}
+	challenge.ID = int(id)
+
+	return nil
+}
+
+// RetireChallenges uses up all but the keep newest unused login challenges of
+// a user
+func (r *TwoFactorRepository) RetireChallenges(userID, keep int) error {
+	query := `
+		UPDATE two_factor_challenges SET used_at = ?
+		WHERE user_id = ? AND used_at IS NULL AND id NOT IN (
+			SELECT id FROM two_factor_challenges
+			WHERE user_id = ? AND used_at IS NULL
+			ORDER BY id DESC
+			LIMIT ?
+		)
+	`
+	if _, err := r.db.Exec(query, time.Now().UTC(), userID, userID, keep); err != nil {
+		return fmt.Errorf("failed to retire login challenges: %w", err)
+	}
+
+	return nil
+}
+
+// GetChallenge retrieves the unused, unexpired login challenge with the given
+// token hash
+func (r *TwoFactorRepository) GetChallenge(tokenHash string) (*model.TwoFactorChallenge, error) {
+	query := `SELECT id, user_id, token_hash, expires_at, used_at, attempts, created_at FROM two_factor_challenges WHERE token_hash = ?`
+
+	var challenge model.TwoFactorChallenge
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&challenge.ID,
+		&challenge.UserID,
+		&challenge.TokenHash,
+		&challenge.ExpiresAt,
+		&challenge.UsedAt,
+		&challenge.Attempts,
+		&challenge.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("invalid challenge token")
+		}
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+
+	if challenge.UsedAt != nil {
+		return nil, fmt.Errorf("invalid challenge token")
+	}
+	if !challenge.ExpiresAt.After(time.Now().UTC()) {
+		return nil, fmt.Errorf("challenge token expired")
+	}
+
+	return &challenge, nil
+}
+
+// RecordFailedAttempt counts a wrong code entered for a login challenge and
+// uses the challenge up once maxAttempts is reached
+func (r *TwoFactorRepository) RecordFailedAttempt(id, maxAttempts int) error {
+	query := `UPDATE two_factor_challenges SET attempts = attempts + 1 WHERE id = ?`
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	query = `UPDATE two_factor_challenges SET used_at = ? WHERE id = ? AND attempts >= ? AND used_at IS NULL`
+	if _, err := r.db.Exec(query, time.Now().UTC(), id, maxAttempts); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeChallenge uses up a login challenge, failing if it was used already
+func (r *TwoFactorRepository) ConsumeChallenge(id int) error {
+	query := `UPDATE two_factor_challenges SET used_at = ? WHERE id = ? AND used_at IS NULL`
+	result, err := r.db.Exec(query, time.Now().UTC(), id)
+	if err != nil {
+		return fmt.Errorf("failed to use login challenge: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("invalid challenge token")
+	}
+
+	return nil
+}
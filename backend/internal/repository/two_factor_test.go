@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestTwoFactorRepositoryEnrollment(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewTwoFactorRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		if enabled, err := repo.IsEnabled(user.ID); err != nil || enabled {
+			t.Fatalf("IsEnabled() = %v, %v; want false", enabled, err)
+		}
+
+		// An unconfirmed secret can be replaced
+		if err := repo.SaveSecret(user.ID, "FIRST"); err != nil {
+			t.Fatalf("SaveSecret() error = %v", err)
+		}
+		if err := repo.SaveSecret(user.ID, "SECOND"); err != nil {
+			t.Fatalf("SaveSecret() error = %v", err)
+		}
+		if enabled, _ := repo.IsEnabled(user.ID); enabled {
+			t.Error("IsEnabled() before confirming = true, want false")
+		}
+
+		if err := repo.Enable(user.ID, 100, []string{"code-a", "code-b"}); err != nil {
+			t.Fatalf("Enable() error = %v", err)
+		}
+		credential, err := repo.GetTOTP(user.ID)
+		if err != nil || credential.Secret != "SECOND" || credential.ConfirmedAt == nil || credential.LastUsedStep != 100 {
+			t.Fatalf("GetTOTP() = %+v, %v; want confirmed SECOND at step 100", credential, err)
+		}
+		if err := repo.SaveSecret(user.ID, "THIRD"); err == nil || err.Error() != "two-factor authentication already enabled" {
+			t.Errorf("SaveSecret(enabled) error = %v, want already enabled", err)
+		}
+
+		// Codes of a step cannot be used twice, nor older ones
+		if err := repo.UseStep(user.ID, 100); err == nil {
+			t.Error("UseStep(confirming step) = nil error, want invalid two-factor code")
+		}
+		if err := repo.UseStep(user.ID, 101); err != nil {
+			t.Errorf("UseStep(next) error = %v", err)
+		}
+		if err := repo.UseStep(user.ID, 101); err == nil {
+			t.Error("UseStep(replayed) = nil error, want invalid two-factor code")
+		}
+
+		// Recovery codes are single use and replaced as a whole
+		if err := repo.UseRecoveryCode(user.ID, "code-a"); err != nil {
+			t.Errorf("UseRecoveryCode() error = %v", err)
+		}
+		if err := repo.UseRecoveryCode(user.ID, "code-a"); err == nil {
+			t.Error("UseRecoveryCode(used) = nil error, want invalid two-factor code")
+		}
+		if err := repo.ReplaceRecoveryCodes(user.ID, []string{"code-c"}); err != nil {
+			t.Fatalf("ReplaceRecoveryCodes() error = %v", err)
+		}
+		if err := repo.UseRecoveryCode(user.ID, "code-b"); err == nil {
+			t.Error("UseRecoveryCode(replaced) = nil error, want invalid two-factor code")
+		}
+		if err := repo.UseRecoveryCode(user.ID, "code-c"); err != nil {
+			t.Errorf("UseRecoveryCode(new) error = %v", err)
+		}
+
+		if err := repo.Disable(user.ID); err != nil {
+			t.Fatalf("Disable() error = %v", err)
+		}
+		if enabled, _ := repo.IsEnabled(user.ID); enabled {
+			t.Error("IsEnabled() after Disable() = true, want false")
+		}
+	})
+}
+
+func TestTwoFactorRepositoryChallenges(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewTwoFactorRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		challenge := &model.TwoFactorChallenge{UserID: user.ID, TokenHash: "challenge", ExpiresAt: time.Now().Add(time.Minute)}
+		if err := repo.CreateChallenge(challenge); err != nil {
+			t.Fatalf("CreateChallenge() error = %v", err)
+		}
+
+		// Wrong codes use the challenge up after the last allowed attempt
+		for i := 0; i < 2; i++ {
+			if err := repo.RecordFailedAttempt(challenge.ID, 2); err != nil {
+				t.Fatalf("RecordFailedAttempt() error = %v", err)
+			}
+			got, err := repo.GetChallenge("challenge")
+			if i == 0 && (err != nil || got.Attempts != 1) {
+				t.Errorf("GetChallenge() after one attempt = %+v, %v; want one attempt", got, err)
+			}
+			if i == 1 && (err == nil || err.Error() != "invalid challenge token") {
+				t.Errorf("GetChallenge() after max attempts error = %v, want invalid challenge token", err)
+			}
+		}
+
+		other := &model.TwoFactorChallenge{UserID: user.ID, TokenHash: "other", ExpiresAt: time.Now().Add(time.Minute)}
+		if err := repo.CreateChallenge(other); err != nil {
+			t.Fatalf("CreateChallenge() error = %v", err)
+		}
+		if err := repo.ConsumeChallenge(other.ID); err != nil {
+			t.Fatalf("ConsumeChallenge() error = %v", err)
+		}
+		if err := repo.ConsumeChallenge(other.ID); err == nil {
+			t.Error("ConsumeChallenge(used) = nil error, want invalid challenge token")
+		}
+
+		stale := &model.TwoFactorChallenge{UserID: user.ID, TokenHash: "stale", ExpiresAt: time.Now().Add(-time.Minute)}
+		if err := repo.CreateChallenge(stale); err != nil {
+			t.Fatalf("CreateChallenge() error = %v", err)
+		}
+		if _, err := repo.GetChallenge("stale"); err == nil || err.Error() != "challenge token expired" {
+			t.Errorf("GetChallenge(expired) error = %v, want challenge token expired", err)
+		}
+
+		// Retiring keeps only the newest open challenges of the user
+		bob := createTestUser(t, database, "bob")
+		if err := repo.CreateChallenge(&model.TwoFactorChallenge{UserID: bob.ID, TokenHash: "bob", ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+			t.Fatalf("CreateChallenge() error = %v", err)
+		}
+		for _, hash := range []string{"first", "second", "third"} {
+			if err := repo.CreateChallenge(&model.TwoFactorChallenge{UserID: user.ID, TokenHash: hash, ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+				t.Fatalf("CreateChallenge() error = %v", err)
+			}
+		}
+		if err := repo.RetireChallenges(user.ID, 2); err != nil {
+			t.Fatalf("RetireChallenges() error = %v", err)
+		}
+		if _, err := repo.GetChallenge("first"); err == nil || err.Error() != "invalid challenge token" {
+			t.Errorf("GetChallenge(first) after retiring error = %v, want invalid challenge token", err)
+		}
+		for _, hash := range []string{"second", "third", "bob"} {
+			if _, err := repo.GetChallenge(hash); err != nil {
+				t.Errorf("GetChallenge(%s) after retiring error = %v", hash, err)
+			}
+		}
+	})
+}
@@ -3,24 +3,28 @@ package repository
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 )
 
 // UserRepository handles user data operations
 type UserRepository struct {
-	db *sql.DB
+	db *db.Database
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(db *sql.DB) *UserRepository {
-	return &UserRepository{db: db}
+func NewUserRepository(database *db.Database) *UserRepository {
+	return &UserRepository{db: database}
 }
 
 // GetByID retrieves a user by ID
 func (r *UserRepository) GetByID(id int) (*model.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, bio, image, created_at, updated_at
+		SELECT id, email, username, password_hash, bio, image, email_verified, pending_email, role, created_at, updated_at,
+			suspended_at, suspension_reason, deletion_scheduled_at, deletion_mode
 		FROM users WHERE id = ?
 	`
 
@@ -32,8 +36,15 @@ func (r *UserRepository) GetByID(id int) (*model.User, error) {
 		&user.PasswordHash,
 		&user.Bio,
 		&user.Image,
+		&user.EmailVerified,
+		&user.PendingEmail,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
+		&user.DeletionScheduledAt,
+		&user.DeletionMode,
 	)
 
 	if err != nil {
@@ -49,7 +60,8 @@ func (r *UserRepository) GetByID(id int) (*model.User, error) {
 // GetByEmail retrieves a user by email
 func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, bio, image, created_at, updated_at
+		SELECT id, email, username, password_hash, bio, image, email_verified, pending_email, role, created_at, updated_at,
+			suspended_at, suspension_reason, deletion_scheduled_at, deletion_mode
 		FROM users WHERE email = ?
 	`
 
@@ -61,8 +73,15 @@ func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 		&user.PasswordHash,
 		&user.Bio,
 		&user.Image,
+		&user.EmailVerified,
+		&user.PendingEmail,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
+		&user.DeletionScheduledAt,
+		&user.DeletionMode,
 	)
 
 	if err != nil {
@@ -78,7 +97,8 @@ func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 // GetByUsername retrieves a user by username
 func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 	query := `
-		SELECT id, email, username, password_hash, bio, image, created_at, updated_at
+		SELECT id, email, username, password_hash, bio, image, email_verified, pending_email, role, created_at, updated_at,
+			suspended_at, suspension_reason, deletion_scheduled_at, deletion_mode
 		FROM users WHERE username = ?
 	`
 
@@ -90,8 +110,15 @@ func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 		&user.PasswordHash,
 		&user.Bio,
 		&user.Image,
+		&user.EmailVerified,
+		&user.PendingEmail,
+		&user.Role,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
+		&user.DeletionScheduledAt,
+		&user.DeletionMode,
 	)
 
 	if err != nil {
@@ -107,18 +134,16 @@ func (r *UserRepository) GetByUsername(username string) (*model.User, error) {
 // Create creates a new user
 func (r *UserRepository) Create(user *model.User) error {
 	query := `
-		INSERT INTO users (email, username, password_hash, bio, image)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO users (email, username, password_hash, bio, image, role)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(query, user.Email, user.Username, user.PasswordHash, user.Bio, user.Image)
-	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+	if user.Role == "" {
+		user.Role = model.RoleUser
 	}
-
-	id, err := result.LastInsertId()
+	id, err := r.db.InsertReturningID(query, user.Email, user.Username, user.PasswordHash, user.Bio, user.Image, user.Role)
 	if err != nil {
-		return fmt.Errorf("failed to get last insert ID: %w", err)
+		return fmt.Errorf("failed to create user: %w", err)
 	}
 
 	user.ID = int(id)
@@ -129,14 +154,309 @@ func (r *UserRepository) Create(user *model.User) error {
 func (r *UserRepository) Update(user *model.User) error {
 	query := `
 		UPDATE users 
-		SET email = ?, username = ?, password_hash = ?, bio = ?, image = ?
+		SET email = ?, username = ?, password_hash = ?, bio = ?, image = ?, pending_email = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query, user.Email, user.Username, user.PasswordHash, user.Bio, user.Image, user.ID)
+	_, err := r.db.Exec(query, user.Email, user.Username, user.PasswordHash, user.Bio, user.Image, user.PendingEmail, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRole changes the role of a user and signs them out everywhere, so
+// that the new role applies to their next login
+func (r *UserRepository) UpdateRole(id int, role string) error {
+	return r.updateAndSignOut(id, `UPDATE users SET role = ? WHERE id = ?`, role, id)
+}
+
+// Suspend suspends a user for the given reason and signs them out everywhere
+func (r *UserRepository) Suspend(id int, reason string) error {
+	query := `UPDATE users SET suspended_at = ?, suspension_reason = ? WHERE id = ?`
+	return r.updateAndSignOut(id, query, time.Now().UTC(), reason, id)
+}
+
+// Reinstate lifts the suspension of a user
+func (r *UserRepository) Reinstate(id int) error {
+	result, err := r.db.Exec(`UPDATE users SET suspended_at = NULL, suspension_reason = '' WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to reinstate user: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// ClearPassword makes the password of a user unusable and signs them out
+// everywhere, so that they can only log in again after a password reset
+func (r *UserRepository) ClearPassword(id int) error {
+	return r.updateAndSignOut(id, `UPDATE users SET password_hash = ? WHERE id = ?`, model.UnusablePasswordHash, id)
+}
+
+// updateAndSignOut runs an update of the user with the given id and revokes
+// their sessions in the same transaction
+func (r *UserRepository) updateAndSignOut(id int, query string, args ...interface{}) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	if _, err := tx.Exec(`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`, time.Now().UTC(), id); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user together with everything they own. The favorite
+// counts of the articles they favorited are lowered to match.
+func (r *UserRepository) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := deleteUser(tx, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+
+	return nil
+}
+
+// ScheduleDeletion records that a user asked for their account to be deleted
+// at the given time in the given mode
+func (r *UserRepository) ScheduleDeletion(id int, at time.Time, mode string) error {
+	query := `UPDATE users SET deletion_scheduled_at = ?, deletion_mode = ? WHERE id = ? AND deletion_scheduled_at IS NULL`
+
+	result, err := r.db.Exec(query, at.UTC(), mode, id)
+	if err != nil {
+		return fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("account deletion already scheduled")
+	}
+
+	return nil
+}
+
+// CancelDeletion cancels the scheduled deletion of a user's account
+func (r *UserRepository) CancelDeletion(id int) error {
+	query := `UPDATE users SET deletion_scheduled_at = NULL, deletion_mode = '' WHERE id = ? AND deletion_scheduled_at IS NOT NULL`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to cancel account deletion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("account deletion not scheduled")
+	}
+
+	return nil
+}
+
+// DeleteScheduled deletes a user whose scheduled deletion is due at now,
+// reporting whether it did. With DeletionModeAnonymize their articles and
+// comments are first handed to the DeletedUsername account. Users whose
+// deletion was cancelled or is not due yet are left alone.
+func (r *UserRepository) DeleteScheduled(id int, now time.Time) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	dialect := r.db.Dialect()
+	query := `
+		SELECT deletion_mode FROM users
+		WHERE id = ? AND deletion_scheduled_at IS NOT NULL
+			AND ` + timeExpr(dialect, "deletion_scheduled_at") + ` <= ` + timeExpr(dialect, "?")
+
+	var mode string
+	if err := tx.QueryRow(query, id, now.UTC()).Scan(&mode); err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get scheduled deletion: %w", err)
+	}
+
+	if mode == model.DeletionModeAnonymize {
+		deletedID, err := deletedUserID(tx)
+		if err != nil {
+			return false, err
+		}
+		if _, err := tx.Exec(`UPDATE articles SET author_id = ? WHERE author_id = ?`, deletedID, id); err != nil {
+			return false, fmt.Errorf("failed to anonymize articles: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE comments SET author_id = ? WHERE author_id = ?`, deletedID, id); err != nil {
+			return false, fmt.Errorf("failed to anonymize comments: %w", err)
+		}
+	}
+
+	if err := deleteUser(tx, id); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+
+	return true, nil
+}
+
+// deleteUser deletes a user, lowering the favorite counts of the articles
+// they favorited first
+func deleteUser(tx *db.Tx, id int) error {
+	query := `
+		UPDATE articles SET favorites_count = favorites_count - 1
+		WHERE id IN (SELECT article_id FROM favorites WHERE user_id = ?) AND favorites_count > 0
+	`
+	if _, err := tx.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to update favorite counts: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM users WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+// deletedUserID returns the id of the DeletedUsername account, creating it
+// the first time. It has no email and an unusable password, so no one can log
+// in as it.
+func deletedUserID(tx *db.Tx) (int, error) {
+	var id int
+	err := tx.QueryRow(`SELECT id FROM users WHERE username = ?`, model.DeletedUsername).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to get deleted user: %w", err)
+	}
+
+	query := `INSERT INTO users (email, username, password_hash, bio, image, role) VALUES (?, ?, ?, ?, ?, ?)`
+	newID, err := tx.InsertReturningID(query, "", model.DeletedUsername, model.UnusablePasswordHash, "", "", model.RoleUser)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create deleted user: %w", err)
+	}
+
+	return int(newID), nil
+}
+
+// List retrieves users newest first with their total, keeping those whose
+// username or email contains search when it is set
+func (r *UserRepository) List(search string, limit, offset int) ([]model.User, int, error) {
+	where := ""
+	args := []interface{}{}
+	if search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(search)) + "%"
+		where = `WHERE LOWER(username) LIKE ? ESCAPE '\' OR LOWER(email) LIKE ? ESCAPE '\'`
+		args = append(args, pattern, pattern)
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM users `+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := `
+		SELECT id, email, username, password_hash, bio, image, email_verified, pending_email, role, created_at, updated_at,
+			suspended_at, suspension_reason, deletion_scheduled_at, deletion_mode
+		FROM users ` + where + `
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`
+
+	rows, err := r.db.Query(query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []model.User{}
+	for rows.Next() {
+		var user model.User
+		err := rows.Scan(
+			&user.ID, &user.Email, &user.Username, &user.PasswordHash, &user.Bio, &user.Image,
+			&user.EmailVerified, &user.PendingEmail, &user.Role, &user.CreatedAt, &user.UpdatedAt,
+			&user.SuspendedAt, &user.SuspensionReason, &user.DeletionScheduledAt, &user.DeletionMode,
+		)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern with a backslash
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UpdatePasswordHash replaces the password hash of a user with an upgraded
+// hash of the same password. Nothing changes if the password was changed
+// since oldHash was read.
+func (r *UserRepository) UpdatePasswordHash(id int, oldHash, newHash string) error {
+	query := `UPDATE users SET password_hash = ? WHERE id = ? AND password_hash = ?`
+
+	if _, err := r.db.Exec(query, newHash, id, oldHash); err != nil {
+		return fmt.Errorf("failed to update password hash: %w", err)
+	}
 
 	return nil
 }
@@ -213,7 +533,134 @@ func (r *UserRepository) IsFollowing(followerID, followedID int) (bool, error) {
 	return count > 0, nil
 }
 
-// GetProfileByUsername gets a user profile by username with follow status
+// GetFollowedAmong reports which of userIDs the follower follows, in one query
+func (r *UserRepository) GetFollowedAmong(followerID int, userIDs []int) (map[int]bool, error) {
+	following := make(map[int]bool)
+	if followerID == 0 || len(userIDs) == 0 {
+		return following, nil
+	}
+
+	in, args := inClause(userIDs)
+	query := `SELECT followed_id FROM follows WHERE follower_id = ? AND followed_id IN (` + in + `)`
+
+	rows, err := r.db.Query(query, append([]interface{}{followerID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get followed users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan followed user: %w", err)
+		}
+		following[id] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate followed users: %w", err)
+	}
+
+	return following, nil
+}
+
+// BlockUser creates a block relationship. Follows between the two users are
+// removed in both directions.
+func (r *UserRepository) BlockUser(blockerID, blockedID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`INSERT INTO user_blocks (blocker_id, blocked_id) VALUES (?, ?)`, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	if _, err := tx.Exec(`
+		DELETE FROM follows
+		WHERE (follower_id = ? AND followed_id = ?) OR (follower_id = ? AND followed_id = ?)
+	`, blockerID, blockedID, blockedID, blockerID); err != nil {
+		return fmt.Errorf("failed to remove follows: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit block: %w", err)
+	}
+
+	return nil
+}
+
+// UnblockUser removes a block relationship
+func (r *UserRepository) UnblockUser(blockerID, blockedID int) error {
+	return r.deleteRelationship(`DELETE FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?`, blockerID, blockedID, "block")
+}
+
+// IsBlocking checks if a user blocked another user
+func (r *UserRepository) IsBlocking(blockerID, blockedID int) (bool, error) {
+	query := `SELECT COUNT(*) FROM user_blocks WHERE blocker_id = ? AND blocked_id = ?`
+
+	var count int
+	err := r.db.QueryRow(query, blockerID, blockedID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check block status: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// MuteUser creates a mute relationship
+func (r *UserRepository) MuteUser(muterID, mutedID int) error {
+	query := `INSERT INTO user_mutes (muter_id, muted_id) VALUES (?, ?)`
+
+	_, err := r.db.Exec(query, muterID, mutedID)
+	if err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+
+	return nil
+}
+
+// UnmuteUser removes a mute relationship
+func (r *UserRepository) UnmuteUser(muterID, mutedID int) error {
+	return r.deleteRelationship(`DELETE FROM user_mutes WHERE muter_id = ? AND muted_id = ?`, muterID, mutedID, "mute")
+}
+
+// IsMuting checks if a user muted another user
+func (r *UserRepository) IsMuting(muterID, mutedID int) (bool, error) {
+	query := `SELECT COUNT(*) FROM user_mutes WHERE muter_id = ? AND muted_id = ?`
+
+	var count int
+	err := r.db.QueryRow(query, muterID, mutedID).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to check mute status: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// deleteRelationship runs query removing the relationship kind from one user
+// to another, reporting when there was none
+func (r *UserRepository) deleteRelationship(query string, fromID, toID int, kind string) error {
+	result, err := r.db.Exec(query, fromID, toID)
+	if err != nil {
+		return fmt.Errorf("failed to remove %s: %w", kind, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s relationship not found", kind)
+	}
+
+	return nil
+}
+
+// GetProfileByUsername gets a user profile by username with follow, block
+// and mute status
 func (r *UserRepository) GetProfileByUsername(username string, currentUserID *int) (*model.ProfileResponse, error) {
 	user, err := r.GetByUsername(username)
 	if err != nil {
@@ -234,6 +681,13 @@ func (r *UserRepository) GetProfileByUsername(username string, currentUserID *in
 			return nil, fmt.Errorf("failed to check follow status: %w", err)
 		}
 		profile.Following = isFollowing
+
+		if profile.Blocking, err = r.IsBlocking(*currentUserID, user.ID); err != nil {
+			return nil, err
+		}
+		if profile.Muting, err = r.IsMuting(*currentUserID, user.ID); err != nil {
+			return nil, err
+		}
 	}
 
 	return profile, nil
@@ -0,0 +1,491 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestUserRepositoryCreateAndGet(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		first := createTestUser(t, database, "alice")
+		second := createTestUser(t, database, "bob")
+
+		if first.ID == 0 || second.ID == first.ID {
+			t.Fatalf("expected distinct generated IDs, got %d and %d", first.ID, second.ID)
+		}
+
+		byEmail, err := repo.GetByEmail("alice@example.com")
+		if err != nil {
+			t.Fatalf("GetByEmail() error = %v", err)
+		}
+		if byEmail.ID != first.ID {
+			t.Errorf("GetByEmail() ID = %d, want %d", byEmail.ID, first.ID)
+		}
+
+		byUsername, err := repo.GetByUsername("bob")
+		if err != nil {
+			t.Fatalf("GetByUsername() error = %v", err)
+		}
+		if byUsername.Email != "bob@example.com" {
+			t.Errorf("GetByUsername() email = %q, want %q", byUsername.Email, "bob@example.com")
+		}
+
+		if _, err := repo.GetByID(first.ID + second.ID + 100); err == nil || err.Error() != "user not found" {
+			t.Errorf("GetByID() on missing user error = %v, want user not found", err)
+		}
+
+		exists, err := repo.EmailExists("alice@example.com")
+		if err != nil || !exists {
+			t.Errorf("EmailExists() = %v, %v; want true, nil", exists, err)
+		}
+	})
+}
+
+func TestUserRepositoryUpdate(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		user.Bio = "hello"
+		user.Email = "alice@new.example.com"
+		if err := repo.Update(user); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+
+		updated, err := repo.GetByID(user.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if updated.Bio != "hello" || updated.Email != "alice@new.example.com" {
+			t.Errorf("Update() did not persist, got bio %q email %q", updated.Bio, updated.Email)
+		}
+	})
+}
+
+func TestUserRepositoryUpdatePasswordHash(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		if err := repo.UpdatePasswordHash(user.ID, user.PasswordHash, "upgraded"); err != nil {
+			t.Fatalf("UpdatePasswordHash() error = %v", err)
+		}
+		if got, _ := repo.GetByID(user.ID); got.PasswordHash != "upgraded" {
+			t.Errorf("password hash = %q, want upgraded", got.PasswordHash)
+		}
+
+		// A stale old hash means the password changed meanwhile
+		if err := repo.UpdatePasswordHash(user.ID, user.PasswordHash, "stale"); err != nil {
+			t.Fatalf("UpdatePasswordHash() error = %v", err)
+		}
+		if got, _ := repo.GetByID(user.ID); got.PasswordHash != "upgraded" {
+			t.Errorf("password hash after stale update = %q, want upgraded", got.PasswordHash)
+		}
+	})
+}
+
+func TestUserRepositoryUpdateRole(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		if got, _ := repo.GetByID(user.ID); got.Role != model.RoleUser {
+			t.Fatalf("role of new user = %q, want %q", got.Role, model.RoleUser)
+		}
+
+		if err := repo.UpdateRole(user.ID, model.RoleModerator); err != nil {
+			t.Fatalf("UpdateRole() error = %v", err)
+		}
+		if got, _ := repo.GetByEmail(user.Email); got.Role != model.RoleModerator {
+			t.Errorf("role = %q, want %q", got.Role, model.RoleModerator)
+		}
+
+		if err := repo.UpdateRole(user.ID+100, model.RoleAdmin); err == nil || err.Error() != "user not found" {
+			t.Errorf("UpdateRole() on missing user error = %v, want user not found", err)
+		}
+	})
+}
+
+func TestUserRepositorySuspendAndReinstate(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		sessions := NewSessionRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		session := &model.Session{UserID: user.ID}
+		if err := sessions.Create(session, &model.RefreshToken{TokenHash: "alice", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Create() session error = %v", err)
+		}
+
+		if err := repo.Suspend(user.ID, "spam"); err != nil {
+			t.Fatalf("Suspend() error = %v", err)
+		}
+		got, err := repo.GetByID(user.ID)
+		if err != nil || got.SuspendedAt == nil || got.SuspensionReason != "spam" {
+			t.Fatalf("GetByID() after Suspend() = %+v, %v; want suspended for spam", got, err)
+		}
+		if active, _ := sessions.IsActive(session.ID); active {
+			t.Error("session still active after Suspend()")
+		}
+
+		if err := repo.Reinstate(user.ID); err != nil {
+			t.Fatalf("Reinstate() error = %v", err)
+		}
+		if got, _ := repo.GetByID(user.ID); got.SuspendedAt != nil || got.SuspensionReason != "" {
+			t.Errorf("GetByID() after Reinstate() = %+v, want not suspended", got)
+		}
+
+		if err := repo.Suspend(user.ID+100, ""); err == nil || err.Error() != "user not found" {
+			t.Errorf("Suspend() on missing user error = %v, want user not found", err)
+		}
+		if err := repo.Reinstate(user.ID + 100); err == nil || err.Error() != "user not found" {
+			t.Errorf("Reinstate() on missing user error = %v, want user not found", err)
+		}
+	})
+}
+
+func TestUserRepositoryClearPassword(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		sessions := NewSessionRepository(database)
+		user := createTestUser(t, database, "alice")
+
+		session := &model.Session{UserID: user.ID}
+		if err := sessions.Create(session, &model.RefreshToken{TokenHash: "alice", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+			t.Fatalf("Create() session error = %v", err)
+		}
+
+		if err := repo.ClearPassword(user.ID); err != nil {
+			t.Fatalf("ClearPassword() error = %v", err)
+		}
+		if got, _ := repo.GetByID(user.ID); got.PasswordHash != model.UnusablePasswordHash {
+			t.Errorf("password hash = %q, want %q", got.PasswordHash, model.UnusablePasswordHash)
+		}
+		if active, _ := sessions.IsActive(session.ID); active {
+			t.Error("session still active after ClearPassword()")
+		}
+	})
+}
+
+func TestUserRepositoryList(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		createTestUser(t, database, "alice")
+		createTestUser(t, database, "bob")
+		createTestUser(t, database, "al_ice")
+
+		users, total, err := repo.List("", 2, 0)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if total != 3 || len(users) != 2 || users[0].Username != "al_ice" {
+			t.Errorf("List() = %+v (total %d), want the two newest of 3", users, total)
+		}
+
+		if users, total, _ := repo.List("ALI", 10, 0); total != 1 || len(users) != 1 || users[0].Username != "alice" {
+			t.Errorf("List(ALI) = %+v (total %d), want alice", users, total)
+		}
+		if _, total, _ := repo.List("bob@example", 10, 0); total != 1 {
+			t.Errorf("List(bob@example) total = %d, want 1", total)
+		}
+		// Wildcards are matched literally
+		if users, total, _ := repo.List("l_i", 10, 0); total != 1 || users[0].Username != "al_ice" {
+			t.Errorf("List(l_i) = %+v (total %d), want al_ice", users, total)
+		}
+	})
+}
+
+func TestUserRepositoryDelete(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		articles := NewArticleRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		aliceArticle := createTestArticle(t, database, alice.ID, "alice-post")
+		bobArticle := createTestArticle(t, database, bob.ID, "bob-post")
+
+		if err := articles.FavoriteArticle(bob.ID, aliceArticle.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+		if err := articles.FavoriteArticle(alice.ID, bobArticle.ID); err != nil {
+			t.Fatalf("FavoriteArticle() error = %v", err)
+		}
+
+		if err := repo.Delete(bob.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := repo.GetByID(bob.ID); err == nil || err.Error() != "user not found" {
+			t.Errorf("GetByID() after Delete() error = %v, want user not found", err)
+		}
+		if _, err := articles.GetBySlug("bob-post"); err == nil {
+			t.Error("article of deleted user still exists")
+		}
+		if count, _ := articles.GetFavoritesCount(aliceArticle.ID); count != 0 {
+			t.Errorf("favorites count after Delete() = %d, want 0", count)
+		}
+
+		if err := repo.Delete(bob.ID); err == nil || err.Error() != "user not found" {
+			t.Errorf("Delete() of deleted user error = %v, want user not found", err)
+		}
+	})
+}
+
+func TestUserRepositoryDeleteCascadesOnEveryConnection(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		article := createTestArticle(t, database, bob.ID, "bob-post")
+		if _, err := database.Exec(`INSERT INTO comments (body, article_id, author_id) VALUES ('hi', ?, ?)`, article.ID, bob.ID); err != nil {
+			t.Fatalf("failed to create comment: %v", err)
+		}
+		if err := repo.FollowUser(bob.ID, alice.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		// Without idle connections every statement runs on a new connection,
+		// so the delete cannot rely on settings made on an earlier one
+		database.SetMaxIdleConns(0)
+
+		if err := repo.Delete(bob.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		for _, query := range []string{
+			`SELECT COUNT(*) FROM articles WHERE author_id = ?`,
+			`SELECT COUNT(*) FROM comments WHERE author_id = ?`,
+			`SELECT COUNT(*) FROM follows WHERE follower_id = ?`,
+		} {
+			var count int
+			if err := database.QueryRow(query, bob.ID).Scan(&count); err != nil {
+				t.Fatalf("%s: error = %v", query, err)
+			}
+			if count != 0 {
+				t.Errorf("%s = %d after Delete(), want 0", query, count)
+			}
+		}
+	})
+}
+
+func TestUserRepositoryScheduledDeletion(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		user := createTestUser(t, database, "alice")
+		due := time.Now().Add(time.Hour)
+
+		if err := repo.ScheduleDeletion(user.ID, due, model.DeletionModeDelete); err != nil {
+			t.Fatalf("ScheduleDeletion() error = %v", err)
+		}
+		if err := repo.ScheduleDeletion(user.ID, due, model.DeletionModeDelete); err == nil || err.Error() != "account deletion already scheduled" {
+			t.Errorf("second ScheduleDeletion() error = %v, want account deletion already scheduled", err)
+		}
+		if got, _ := repo.GetByID(user.ID); got.DeletionScheduledAt == nil || got.DeletionMode != model.DeletionModeDelete {
+			t.Errorf("GetByID() = %+v, want deletion scheduled", got)
+		}
+
+		if deleted, err := repo.DeleteScheduled(user.ID, time.Now()); err != nil || deleted {
+			t.Errorf("DeleteScheduled() before due = %v, %v; want false, nil", deleted, err)
+		}
+
+		if err := repo.CancelDeletion(user.ID); err != nil {
+			t.Fatalf("CancelDeletion() error = %v", err)
+		}
+		if err := repo.CancelDeletion(user.ID); err == nil || err.Error() != "account deletion not scheduled" {
+			t.Errorf("second CancelDeletion() error = %v, want account deletion not scheduled", err)
+		}
+		if deleted, err := repo.DeleteScheduled(user.ID, due.Add(time.Minute)); err != nil || deleted {
+			t.Errorf("DeleteScheduled() after cancel = %v, %v; want false, nil", deleted, err)
+		}
+
+		if err := repo.ScheduleDeletion(user.ID, due, model.DeletionModeDelete); err != nil {
+			t.Fatalf("ScheduleDeletion() error = %v", err)
+		}
+		if deleted, err := repo.DeleteScheduled(user.ID, due.Add(time.Minute)); err != nil || !deleted {
+			t.Fatalf("DeleteScheduled() when due = %v, %v; want true, nil", deleted, err)
+		}
+		if _, err := repo.GetByID(user.ID); err == nil || err.Error() != "user not found" {
+			t.Errorf("GetByID() after DeleteScheduled() error = %v, want user not found", err)
+		}
+	})
+}
+
+func TestUserRepositoryScheduledDeletionAnonymizes(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		comments := NewCommentRepository(database)
+		due := time.Now().Add(-time.Minute)
+
+		var articles []*model.Article
+		for _, name := range []string{"alice", "bob"} {
+			user := createTestUser(t, database, name)
+			article := createTestArticle(t, database, user.ID, name+"-post")
+			if err := comments.Create(&model.Comment{Body: "hi", AuthorID: user.ID, ArticleID: article.ID}); err != nil {
+				t.Fatalf("Create() comment error = %v", err)
+			}
+			articles = append(articles, article)
+
+			if err := repo.ScheduleDeletion(user.ID, due, model.DeletionModeAnonymize); err != nil {
+				t.Fatalf("ScheduleDeletion() error = %v", err)
+			}
+			if deleted, err := repo.DeleteScheduled(user.ID, time.Now()); err != nil || !deleted {
+				t.Fatalf("DeleteScheduled(%s) = %v, %v; want true, nil", name, deleted, err)
+			}
+		}
+
+		deletedUser, err := repo.GetByUsername(model.DeletedUsername)
+		if err != nil {
+			t.Fatalf("GetByUsername(deleted user) error = %v", err)
+		}
+		for _, article := range articles {
+			got, err := NewArticleRepository(database).GetBySlug(article.Slug)
+			if err != nil || got.AuthorID != deletedUser.ID {
+				t.Errorf("GetBySlug(%s) = %+v, %v; want it kept for the deleted user", article.Slug, got, err)
+			}
+			if list, _ := comments.GetByArticleSlug(article.Slug); len(list) != 1 || list[0].Author.Username != model.DeletedUsername {
+				t.Errorf("comments of %s = %+v, want one by the deleted user", article.Slug, list)
+			}
+		}
+	})
+}
+
+func TestUserRepositoryFollow(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+
+		if err := repo.FollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+
+		following, err := repo.IsFollowing(alice.ID, bob.ID)
+		if err != nil || !following {
+			t.Errorf("IsFollowing() = %v, %v; want true, nil", following, err)
+		}
+
+		profile, err := repo.GetProfileByUsername("bob", &alice.ID)
+		if err != nil {
+			t.Fatalf("GetProfileByUsername() error = %v", err)
+		}
+		if !profile.Following {
+			t.Error("GetProfileByUsername() Following = false, want true")
+		}
+
+		if err := repo.UnfollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("UnfollowUser() error = %v", err)
+		}
+		if err := repo.UnfollowUser(alice.ID, bob.ID); err == nil {
+			t.Error("UnfollowUser() twice expected error, got nil")
+		}
+	})
+}
+
+func TestUserRepositoryBlock(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+
+		for _, pair := range [][2]int{{alice.ID, bob.ID}, {bob.ID, alice.ID}} {
+			if err := repo.FollowUser(pair[0], pair[1]); err != nil {
+				t.Fatalf("FollowUser() error = %v", err)
+			}
+		}
+
+		if err := repo.BlockUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("BlockUser() error = %v", err)
+		}
+		if blocking, err := repo.IsBlocking(alice.ID, bob.ID); err != nil || !blocking {
+			t.Errorf("IsBlocking() = %v, %v; want true, nil", blocking, err)
+		}
+		if blocking, _ := repo.IsBlocking(bob.ID, alice.ID); blocking {
+			t.Error("IsBlocking() the other way = true, want false")
+		}
+
+		// Blocking removes the follows in both directions
+		for _, pair := range [][2]int{{alice.ID, bob.ID}, {bob.ID, alice.ID}} {
+			if following, _ := repo.IsFollowing(pair[0], pair[1]); following {
+				t.Errorf("IsFollowing(%d, %d) after BlockUser() = true, want false", pair[0], pair[1])
+			}
+		}
+
+		profile, err := repo.GetProfileByUsername("bob", &alice.ID)
+		if err != nil || !profile.Blocking || profile.Muting {
+			t.Errorf("GetProfileByUsername() = %+v, %v; want blocking only", profile, err)
+		}
+
+		if err := repo.BlockUser(alice.ID, bob.ID); err == nil {
+			t.Error("BlockUser() twice expected error, got nil")
+		}
+		if err := repo.UnblockUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("UnblockUser() error = %v", err)
+		}
+		if err := repo.UnblockUser(alice.ID, bob.ID); err == nil || err.Error() != "block relationship not found" {
+			t.Errorf("UnblockUser() twice error = %v, want block relationship not found", err)
+		}
+	})
+}
+
+func TestUserRepositoryMute(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+
+		if err := repo.FollowUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("FollowUser() error = %v", err)
+		}
+		if err := repo.MuteUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("MuteUser() error = %v", err)
+		}
+		if muting, err := repo.IsMuting(alice.ID, bob.ID); err != nil || !muting {
+			t.Errorf("IsMuting() = %v, %v; want true, nil", muting, err)
+		}
+
+		// Muting keeps the follow
+		profile, err := repo.GetProfileByUsername("bob", &alice.ID)
+		if err != nil || !profile.Following || !profile.Muting {
+			t.Errorf("GetProfileByUsername() = %+v, %v; want following and muting", profile, err)
+		}
+
+		if err := repo.UnmuteUser(alice.ID, bob.ID); err != nil {
+			t.Fatalf("UnmuteUser() error = %v", err)
+		}
+		if err := repo.UnmuteUser(alice.ID, bob.ID); err == nil || err.Error() != "mute relationship not found" {
+			t.Errorf("UnmuteUser() twice error = %v, want mute relationship not found", err)
+		}
+	})
+}
+
+func TestUserRepositoryGetFollowedAmong(t *testing.T) {
+	forEachEngine(t, func(t *testing.T, database *db.Database) {
+		repo := NewUserRepository(database)
+		viewer := createTestUser(t, database, "viewer")
+		alice := createTestUser(t, database, "alice")
+		bob := createTestUser(t, database, "bob")
+		carol := createTestUser(t, database, "carol")
+
+		for _, followed := range []int{alice.ID, carol.ID} {
+			if err := repo.FollowUser(viewer.ID, followed); err != nil {
+				t.Fatalf("FollowUser() error = %v", err)
+			}
+		}
+
+		following, err := repo.GetFollowedAmong(viewer.ID, []int{alice.ID, bob.ID})
+		if err != nil {
+			t.Fatalf("GetFollowedAmong() error = %v", err)
+		}
+		if len(following) != 1 || !following[alice.ID] {
+			t.Errorf("GetFollowedAmong() = %v, want only alice", following)
+		}
+
+		// Anonymous viewers follow nobody
+		if following, _ := repo.GetFollowedAmong(0, []int{alice.ID}); len(following) != 0 {
+			t.Errorf("GetFollowedAmong() for anonymous = %v, want empty", following)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+)
+
+const (
+	// batchSize is the maximum number of due jobs picked up per poll
+	batchSize = 50
+	// maxAttempts is the number of times a job runs before it is marked failed
+	maxAttempts = 5
+	// baseRetryDelay is the delay before the first retry; it doubles per attempt
+	baseRetryDelay = 30 * time.Second
+)
+
+// HandlerFunc runs a job given its JSON payload
+type HandlerFunc func(payload string) error
+
+// Scheduler runs persisted jobs from the jobs table in-process. Jobs survive
+// restarts because they are only marked done once their handler succeeds.
+type Scheduler struct {
+	jobRepo  *repository.JobRepository
+	interval time.Duration
+	handlers map[string]HandlerFunc
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewScheduler creates a scheduler that polls for due jobs every interval
+func NewScheduler(jobRepo *repository.JobRepository, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		jobRepo:  jobRepo,
+		interval: interval,
+		handlers: make(map[string]HandlerFunc),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Register sets the handler for a job type. It must be called before Start.
+func (s *Scheduler) Register(jobType string, handler HandlerFunc) {
+	s.handlers[jobType] = handler
+}
+
+// Start requeues jobs interrupted by a previous shutdown and begins polling
+// in a background goroutine
+func (s *Scheduler) Start() {
+	requeued, err := s.jobRepo.RequeueRunning()
+	if err != nil {
+		log.Printf("scheduler: %v", err)
+	} else if requeued > 0 {
+		log.Printf("scheduler: requeued %d interrupted jobs", requeued)
+	}
+
+	go s.loop()
+}
+
+// Stop stops polling and waits for the current batch to finish
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+}
+
+// loop runs due jobs immediately and then on every tick until stopped
+func (s *Scheduler) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.RunDue(); err != nil {
+			log.Printf("scheduler: %v", err)
+		}
+
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every job that is due now
+func (s *Scheduler) RunDue() error {
+	jobs, err := s.jobRepo.GetDue(time.Now(), batchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		claimed, err := s.jobRepo.Claim(job.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+		job.Attempts++
+
+		if err := s.run(job); err != nil {
+			log.Printf("scheduler: job %d (%s) attempt %d failed: %v", job.ID, job.Type, job.Attempts, err)
+			if err := s.reschedule(job, err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := s.jobRepo.Complete(job.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// run dispatches a job to its registered handler
+func (s *Scheduler) run(job model.Job) (err error) {
+	handler, ok := s.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %s", job.Type)
+	}
+
+	// A panicking handler must not take down the server
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+
+	return handler(job.Payload)
+}
+
+// reschedule retries a failed job with exponential backoff, or marks it failed
+// once it has used all its attempts
+func (s *Scheduler) reschedule(job model.Job, jobErr error) error {
+	if job.Attempts >= maxAttempts {
+		return s.jobRepo.Fail(job.ID, jobErr.Error())
+	}
+
+	delay := baseRetryDelay << (job.Attempts - 1)
+	return s.jobRepo.Retry(job.ID, time.Now().Add(delay), jobErr.Error())
+}
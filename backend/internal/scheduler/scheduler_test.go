@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+)
+
+func newTestJobRepository(t *testing.T) *repository.JobRepository {
+	t.Helper()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.MigrateFrom("../../migrations"); err != nil {
+		t.Fatalf("failed to migrate sqlite: %v", err)
+	}
+
+	return repository.NewJobRepository(database)
+}
+
+func TestSchedulerRunDue(t *testing.T) {
+	jobRepo := newTestJobRepository(t)
+	s := NewScheduler(jobRepo, time.Hour)
+
+	var payloads []string
+	s.Register("ok", func(payload string) error {
+		payloads = append(payloads, payload)
+		return nil
+	})
+	s.Register("broken", func(payload string) error {
+		return fmt.Errorf("broken")
+	})
+
+	ok := &model.Job{Type: "ok", Payload: `{"id":1}`, RunAt: time.Now().Add(-time.Second)}
+	broken := &model.Job{Type: "broken", RunAt: time.Now().Add(-time.Second)}
+	future := &model.Job{Type: "ok", RunAt: time.Now().Add(time.Hour)}
+	for _, job := range []*model.Job{ok, broken, future} {
+		if err := jobRepo.Enqueue(job); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	if err := s.RunDue(); err != nil {
+		t.Fatalf("RunDue() error = %v", err)
+	}
+
+	if len(payloads) != 1 || payloads[0] != `{"id":1}` {
+		t.Errorf("handler payloads = %v, want one due job", payloads)
+	}
+
+	tests := []struct {
+		job          *model.Job
+		wantStatus   string
+		wantAttempts int
+	}{
+		{ok, model.JobStatusDone, 1},
+		{broken, model.JobStatusPending, 1},
+		{future, model.JobStatusPending, 0},
+	}
+	for _, tt := range tests {
+		got, err := jobRepo.GetByID(tt.job.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.Status != tt.wantStatus || got.Attempts != tt.wantAttempts {
+			t.Errorf("job %s: status %s attempts %d, want %s/%d",
+				tt.job.Type, got.Status, got.Attempts, tt.wantStatus, tt.wantAttempts)
+		}
+	}
+
+	// The failed job is retried later rather than immediately
+	retried, _ := jobRepo.GetByID(broken.ID)
+	if !retried.RunAt.After(time.Now()) || retried.LastError != "broken" {
+		t.Errorf("failed job = %+v, want rescheduled with last error", retried)
+	}
+}
+
+func TestSchedulerFailsAfterMaxAttempts(t *testing.T) {
+	jobRepo := newTestJobRepository(t)
+	s := NewScheduler(jobRepo, time.Hour)
+	s.Register("broken", func(payload string) error {
+		panic("boom")
+	})
+
+	job := &model.Job{Type: "broken", RunAt: time.Now().Add(-time.Second)}
+	if err := jobRepo.Enqueue(job); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	for i := 0; i < maxAttempts; i++ {
+		if err := s.RunDue(); err != nil {
+			t.Fatalf("RunDue() error = %v", err)
+		}
+
+		got, err := jobRepo.GetByID(job.ID)
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		if got.Status == model.JobStatusFailed {
+			break
+		}
+
+		// Make the retry due straight away
+		if err := jobRepo.Retry(job.ID, time.Now().Add(-time.Second), got.LastError); err != nil {
+			t.Fatalf("Retry() error = %v", err)
+		}
+	}
+
+	got, err := jobRepo.GetByID(job.ID)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Status != model.JobStatusFailed || got.Attempts != maxAttempts {
+		t.Errorf("job = %s after %d attempts, want failed after %d", got.Status, got.Attempts, maxAttempts)
+	}
+	if got.LastError != "handler panicked: boom" {
+		t.Errorf("LastError = %q, want the recovered panic", got.LastError)
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// AccountService handles the personal data export of users and the deletion
+// of their accounts
+type AccountService struct {
+	userRepo    *repository.UserRepository
+	exportRepo  *repository.ExportRepository
+	jobRepo     *repository.JobRepository
+	hasher      *utils.PasswordHasher
+	mailer      mailer.Mailer
+	appURL      string
+	gracePeriod time.Duration
+}
+
+// NewAccountService creates a new account service. Accounts are deleted
+// gracePeriod after their user asks, and can be kept until then.
+func NewAccountService(userRepo *repository.UserRepository, exportRepo *repository.ExportRepository, jobRepo *repository.JobRepository, hasher *utils.PasswordHasher, mailer mailer.Mailer, appURL string, gracePeriod time.Duration) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		exportRepo:  exportRepo,
+		jobRepo:     jobRepo,
+		hasher:      hasher,
+		mailer:      mailer,
+		appURL:      appURL,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Export gathers the personal data of a user: their profile, articles,
+// comments, favorites and follows
+func (s *AccountService) Export(userID int) (*model.UserExport, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &model.UserExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: model.ExportedProfile{
+			Email:         user.Email,
+			PendingEmail:  user.PendingEmail,
+			EmailVerified: user.EmailVerified,
+			Username:      user.Username,
+			Bio:           user.Bio,
+			Image:         user.Image,
+			Role:          user.Role,
+			CreatedAt:     user.CreatedAt,
+		},
+	}
+
+	if export.Articles, err = s.exportRepo.Articles(userID); err != nil {
+		return nil, err
+	}
+	if export.Comments, err = s.exportRepo.Comments(userID); err != nil {
+		return nil, err
+	}
+	if export.Favorites, err = s.exportRepo.Favorites(userID); err != nil {
+		return nil, err
+	}
+	if export.Following, err = s.exportRepo.Following(userID); err != nil {
+		return nil, err
+	}
+	if export.Followers, err = s.exportRepo.Followers(userID); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// ScheduleDeletion schedules the deletion of the account of a user after the
+// grace period, once they confirmed it with their password. They are mailed
+// the date, and can cancel the deletion until then.
+func (s *AccountService) ScheduleDeletion(userID int, req model.DeleteAccountRequest) (*model.AccountDeletion, error) {
+	if req.Mode != model.DeletionModeDelete && req.Mode != model.DeletionModeAnonymize {
+		return nil, fmt.Errorf("mode must be %s or %s", model.DeletionModeDelete, model.DeletionModeAnonymize)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users who signed up through an issuer have no password to confirm with
+	if user.PasswordHash != model.UnusablePasswordHash {
+		if req.Password == "" {
+			return nil, fmt.Errorf("password is required")
+		}
+		if valid, _ := s.hasher.Verify(req.Password, user.PasswordHash); !valid {
+			return nil, fmt.Errorf("invalid password")
+		}
+	}
+
+	deletion := &model.AccountDeletion{ScheduledAt: time.Now().Add(s.gracePeriod).UTC().Truncate(time.Second), Mode: req.Mode}
+	if err := s.userRepo.ScheduleDeletion(user.ID, deletion.ScheduledAt, deletion.Mode); err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(model.DeleteAccountPayload{UserID: user.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode account deletion job: %w", err)
+	}
+	job := &model.Job{Type: model.JobTypeDeleteAccount, Payload: string(payload), RunAt: deletion.ScheduledAt}
+	if err := s.jobRepo.Enqueue(job); err != nil {
+		return nil, fmt.Errorf("failed to schedule account deletion: %w", err)
+	}
+
+	content := "Your articles and comments will be deleted with it."
+	if deletion.Mode == model.DeletionModeAnonymize {
+		content = "Your articles and comments will stay, credited to \"" + model.DeletedUsername + "\"."
+	}
+	s.notify(user, "Your RealWorld account will be deleted", fmt.Sprintf("Hi %s,\n\n"+
+		"Your RealWorld account will be deleted on %s. %s\n\n"+
+		"To keep your account, log in at %s and cancel the deletion before then.\n",
+		user.Username, deletion.ScheduledAt.Format("January 2, 2006 at 15:04 MST"), content, s.appURL))
+
+	return deletion, nil
+}
+
+// CancelDeletion cancels the scheduled deletion of the account of a user
+func (s *AccountService) CancelDeletion(userID int) error {
+	return s.userRepo.CancelDeletion(userID)
+}
+
+// DeleteScheduledAccount handles delete_account jobs run by the scheduler
+func (s *AccountService) DeleteScheduledAccount(payload string) error {
+	var p model.DeleteAccountPayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return fmt.Errorf("invalid account deletion job payload: %w", err)
+	}
+
+	user, err := s.userRepo.GetByID(p.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+
+	// The repository only deletes accounts whose deletion is due, so a job
+	// left over from a cancelled deletion does nothing
+	deleted, err := s.userRepo.DeleteScheduled(user.ID, time.Now())
+	if err != nil || !deleted {
+		return err
+	}
+
+	s.notify(user, "Your RealWorld account was deleted", fmt.Sprintf("Hi %s,\n\n"+
+		"As you asked, your RealWorld account and your personal data were deleted.\n", user.Username))
+	return nil
+}
+
+// notify mails a user about their account. Failures are logged, as the
+// change they describe is already made.
+func (s *AccountService) notify(user *model.User, subject, body string) {
+	if err := s.mailer.Send(mailer.Message{To: user.Email, Subject: subject, Body: body}); err != nil {
+		log.Printf("Failed to mail user %d: %v", user.ID, err)
+	}
+}
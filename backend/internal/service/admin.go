@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// maxSuspensionReasonLength caps the reason given for a suspension
+const maxSuspensionReasonLength = 500
+
+// AdminService handles the administration of user accounts. Every change is
+// recorded in the moderation log.
+type AdminService struct {
+	userRepo    *repository.UserRepository
+	userService *UserService
+	moderation  *ModerationService
+}
+
+// NewAdminService creates a new admin service
+func NewAdminService(userRepo *repository.UserRepository, userService *UserService, moderation *ModerationService) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		userService: userService,
+		moderation:  moderation,
+	}
+}
+
+// ListUsers returns users newest first, keeping those whose username or email
+// contains search when it is set
+func (s *AdminService) ListUsers(search string, limit, offset int) (*model.AdminUsersResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	users, total, err := s.userRepo.List(strings.TrimSpace(search), limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.AdminUsersResponse{Users: make([]model.AdminUser, len(users)), UsersCount: total}
+	for i := range users {
+		response.Users[i] = toAdminUser(&users[i])
+	}
+	return response, nil
+}
+
+// GetUser returns a user by ID
+func (s *AdminService) GetUser(id int) (*model.AdminUser, error) {
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	adminUser := toAdminUser(user)
+	return &adminUser, nil
+}
+
+// SuspendUser suspends a user, signing them out everywhere. Suspending a
+// suspended user updates the reason.
+func (s *AdminService) SuspendUser(actor *utils.Claims, id int, reason string) (*model.AdminUser, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) > maxSuspensionReasonLength {
+		return nil, fmt.Errorf("reason must be at most %d characters long", maxSuspensionReasonLength)
+	}
+
+	user, err := s.target(actor, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.record(actor, model.ModerationUserSuspend, user, reason); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Suspend(user.ID, reason); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(user.ID)
+}
+
+// ReinstateUser lifts the suspension of a user. They log in again to get a
+// session.
+func (s *AdminService) ReinstateUser(actor *utils.Claims, id int) (*model.AdminUser, error) {
+	user, err := s.target(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.SuspendedAt == nil {
+		return nil, fmt.Errorf("user is not suspended")
+	}
+
+	if err := s.record(actor, model.ModerationUserReinstate, user, ""); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.Reinstate(user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(user.ID)
+}
+
+// ForcePasswordReset clears the password of a user, signs them out everywhere
+// and mails them a link to choose a new one
+func (s *AdminService) ForcePasswordReset(actor *utils.Claims, id int) error {
+	user, err := s.target(actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.record(actor, model.ModerationUserPasswordReset, user, ""); err != nil {
+		return err
+	}
+	return s.userService.ForcePasswordReset(user.ID)
+}
+
+// ChangeRole gives a user another role. They are signed out everywhere so
+// that the role applies at once.
+func (s *AdminService) ChangeRole(actor *utils.Claims, id int, role string) (*model.AdminUser, error) {
+	if !model.IsValidRole(role) {
+		return nil, fmt.Errorf("invalid role")
+	}
+
+	user, err := s.target(actor, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		adminUser := toAdminUser(user)
+		return &adminUser, nil
+	}
+
+	if err := s.record(actor, model.ModerationUserRole, user, user.Role+" -> "+role); err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.UpdateRole(user.ID, role); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(user.ID)
+}
+
+// DeleteUser deletes a user together with their articles, comments and
+// everything else they own
+func (s *AdminService) DeleteUser(actor *utils.Claims, id int) error {
+	user, err := s.target(actor, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.record(actor, model.ModerationUserDelete, user, user.Email); err != nil {
+		return err
+	}
+	return s.userRepo.Delete(user.ID)
+}
+
+// target returns the user an admin acts on. Admins cannot act on their own
+// account here, so that they cannot lock themselves out, nor on the account
+// keeping the content of deleted users.
+func (s *AdminService) target(actor *utils.Claims, id int) (*model.User, error) {
+	if actor.UserID == id {
+		return nil, fmt.Errorf("cannot administer your own account")
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if user.Username == model.DeletedUsername {
+		return nil, fmt.Errorf("cannot administer the deleted user account")
+	}
+
+	return user, nil
+}
+
+// record logs an admin acting on user. The log keeps the username in the
+// details, as the user may be deleted.
+func (s *AdminService) record(actor *utils.Claims, action string, user *model.User, details string) error {
+	if details != "" {
+		details = user.Username + " (" + details + ")"
+	} else {
+		details = user.Username
+	}
+	return s.moderation.recordOverride(actor, action, "user", user.ID, user.ID, details)
+}
+
+// toAdminUser converts a user to the admin view of it
+func toAdminUser(user *model.User) model.AdminUser {
+	return model.AdminUser{
+		ID:               user.ID,
+		Email:            user.Email,
+		Username:         user.Username,
+		Role:             user.Role,
+		EmailVerified:    user.EmailVerified,
+		PendingEmail:     user.PendingEmail,
+		HasPassword:      user.PasswordHash != model.UnusablePasswordHash,
+		SuspendedAt:      user.SuspendedAt,
+		SuspensionReason: user.SuspensionReason,
+		CreatedAt:        user.CreatedAt,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+const (
+	// apiTokenPrefix marks personal access tokens so that secret scanners
+	// and people can tell them apart from other credentials
+	apiTokenPrefix = "rwpat_"
+	// defaultAPITokenDays is how long a token lives when no expiry is given
+	defaultAPITokenDays = 30
+	// maxAPITokenDays is the longest lifetime a token can be created with
+	maxAPITokenDays = 365
+	// maxAPITokenNameLength is the longest name a token can be given
+	maxAPITokenNameLength = 100
+)
+
+// APITokenService manages the personal access tokens scripts and CI use in
+// place of a password
+type APITokenService struct {
+	apiTokenRepo *repository.APITokenRepository
+	userRepo     *repository.UserRepository
+}
+
+// NewAPITokenService creates a new API token service
+func NewAPITokenService(apiTokenRepo *repository.APITokenRepository, userRepo *repository.UserRepository) *APITokenService {
+	return &APITokenService{
+		apiTokenRepo: apiTokenRepo,
+		userRepo:     userRepo,
+	}
+}
+
+// CreateToken creates a token for a user. The returned token is the only
+// place the token itself appears.
+func (s *APITokenService) CreateToken(userID int, req model.CreateAPITokenRequest) (*model.APIToken, error) {
+	name := strings.TrimSpace(req.Token.Name)
+	if name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if len(name) > maxAPITokenNameLength {
+		return nil, fmt.Errorf("name must be at most %d characters long", maxAPITokenNameLength)
+	}
+
+	scopes, err := validateScopes(req.Token.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	days := req.Token.ExpiresInDays
+	if days == 0 {
+		days = defaultAPITokenDays
+	}
+	if days < 1 || days > maxAPITokenDays {
+		return nil, fmt.Errorf("expiresInDays must be between 1 and %d", maxAPITokenDays)
+	}
+
+	secret, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	value := apiTokenPrefix + secret
+
+	token := &model.APIToken{
+		UserID:    userID,
+		Name:      name,
+		TokenHash: utils.HashOpaqueToken(value),
+		Scopes:    scopes,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	}
+	if err := s.apiTokenRepo.Create(token); err != nil {
+		return nil, err
+	}
+	token.Token = value
+
+	return token, nil
+}
+
+// ListTokens returns the usable tokens of a user
+func (s *APITokenService) ListTokens(userID int) (*model.APITokensResponse, error) {
+	tokens, err := s.apiTokenRepo.ListActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.APITokensResponse{Tokens: tokens}, nil
+}
+
+// RevokeToken revokes one of the tokens of a user
+func (s *APITokenService) RevokeToken(userID, tokenID int) error {
+	return s.apiTokenRepo.RevokeForUser(tokenID, userID)
+}
+
+// AuthenticateAPIToken returns the claims of a request made with an API
+// token, or nil when the token is unknown, revoked or expired. Tokens of
+// suspended users are refused with an "account suspended" error. The last-used
+// time is only written when it is older than lastSeenInterval.
+func (s *APITokenService) AuthenticateAPIToken(value string) (*utils.Claims, error) {
+	if !strings.HasPrefix(value, apiTokenPrefix) {
+		return nil, nil
+	}
+
+	token, err := s.apiTokenRepo.GetByHash(utils.HashOpaqueToken(value))
+	if err != nil {
+		if err.Error() == "API token not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || !token.ExpiresAt.After(now) {
+		return nil, nil
+	}
+
+	user, err := s.userRepo.GetByID(token.UserID)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user.SuspendedAt != nil {
+		return nil, fmt.Errorf("account suspended")
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastSeenInterval {
+		// A failed update only leaves the last-used time stale
+		if err := s.apiTokenRepo.Touch(token.ID, now); err != nil {
+			log.Printf("api token: %v", err)
+		}
+	}
+
+	return &utils.Claims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        user.Role,
+		Permissions: model.PermissionsFor(user.Role),
+		TokenID:     token.ID,
+		Scopes:      token.Scopes,
+	}, nil
+}
+
+// validateScopes checks that scopes are known, dropping duplicates
+func validateScopes(scopes []string) ([]string, error) {
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+
+	known := make(map[string]bool, len(model.APIScopes))
+	for _, scope := range model.APIScopes {
+		known[scope] = true
+	}
+
+	seen := make(map[string]bool, len(scopes))
+	valid := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !known[scope] {
+			return nil, fmt.Errorf("unknown scope: %s", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			valid = append(valid, scope)
+		}
+	}
+
+	return valid, nil
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
@@ -10,17 +14,27 @@ import (
 
 // ArticleService handles article business logic
 type ArticleService struct {
-	articleRepo *repository.ArticleRepository
-	userRepo    *repository.UserRepository
-	tagService  *TagService
+	articleRepo   *repository.ArticleRepository
+	userRepo      *repository.UserRepository
+	jobRepo       *repository.JobRepository
+	revisionRepo  *repository.RevisionRepository
+	articleLoader *repository.ArticleLoader
+	tagService    *TagService
+	verification  *EmailVerificationPolicy
+	moderation    *ModerationService
 }
 
 // NewArticleService creates a new article service
-func NewArticleService(articleRepo *repository.ArticleRepository, userRepo *repository.UserRepository, tagService *TagService) *ArticleService {
+func NewArticleService(articleRepo *repository.ArticleRepository, userRepo *repository.UserRepository, jobRepo *repository.JobRepository, revisionRepo *repository.RevisionRepository, articleLoader *repository.ArticleLoader, tagService *TagService, verification *EmailVerificationPolicy, moderation *ModerationService) *ArticleService {
 	return &ArticleService{
-		articleRepo: articleRepo,
-		userRepo:    userRepo,
-		tagService:  tagService,
+		articleRepo:   articleRepo,
+		userRepo:      userRepo,
+		jobRepo:       jobRepo,
+		revisionRepo:  revisionRepo,
+		articleLoader: articleLoader,
+		tagService:    tagService,
+		verification:  verification,
+		moderation:    moderation,
 	}
 }
 
@@ -37,6 +51,31 @@ func (s *ArticleService) CreateArticle(req model.CreateArticleRequest, authorID
 		return nil, fmt.Errorf("body is required")
 	}
 
+	// Articles are published on creation unless another status is requested
+	status := model.ArticleStatusPublished
+	if req.Article.Status != "" {
+		if err := validateArticleStatus(req.Article.Status); err != nil {
+			return nil, err
+		}
+		status = req.Article.Status
+	}
+
+	// A publish time in the future keeps the article a draft until the
+	// scheduler publishes it; a past publish time publishes it right away
+	var publishAt *time.Time
+	if req.Article.PublishAt != nil {
+		t := req.Article.PublishAt.UTC()
+		publishAt = &t
+		status = publishStatusFor(t)
+	}
+
+	// Scheduling counts as publishing, the job runs without further checks
+	if status != model.ArticleStatusDraft || publishAt != nil {
+		if err := s.verification.Check(authorID, ActionPublish); err != nil {
+			return nil, err
+		}
+	}
+
 	// Generate unique slug
 	slug := utils.GenerateSlug(req.Article.Title)
 
@@ -47,6 +86,8 @@ func (s *ArticleService) CreateArticle(req model.CreateArticleRequest, authorID
 		Description: req.Article.Description,
 		Body:        req.Article.Body,
 		AuthorID:    authorID,
+		Status:      status,
+		PublishAt:   publishAt,
 	}
 
 	err := s.articleRepo.Create(article)
@@ -54,6 +95,17 @@ func (s *ArticleService) CreateArticle(req model.CreateArticleRequest, authorID
 		return nil, fmt.Errorf("failed to create article: %w", err)
 	}
 
+	// The original content is the first revision
+	if err := s.recordRevision(article, authorID); err != nil {
+		return nil, err
+	}
+
+	if publishAt != nil && status == model.ArticleStatusDraft {
+		if err := s.schedulePublish(article.ID, *publishAt); err != nil {
+			return nil, err
+		}
+	}
+
 	// Set tags if provided
 	if len(req.Article.TagList) > 0 {
 		err = s.tagService.CreateTagsForArticle(article.ID, req.Article.TagList)
@@ -66,29 +118,40 @@ func (s *ArticleService) CreateArticle(req model.CreateArticleRequest, authorID
 	return s.buildArticleResponse(article, authorID)
 }
 
-// GetArticleBySlug retrieves an article by slug
+// GetArticleBySlug retrieves an article by its current or a historical slug.
+// The response always carries the current slug.
 func (s *ArticleService) GetArticleBySlug(slug string, currentUserID int) (*model.ArticleResponse, error) {
-	article, err := s.articleRepo.GetBySlug(slug)
+	article, err := s.resolveArticle(slug)
 	if err != nil {
 		return nil, err
 	}
 
+	// Drafts are only visible to their author
+	if !isVisibleTo(article, currentUserID) {
+		return nil, fmt.Errorf("article not found")
+	}
+
 	return s.buildArticleResponse(article, currentUserID)
 }
 
 // UpdateArticle updates an existing article
-func (s *ArticleService) UpdateArticle(slug string, req model.UpdateArticleRequest, currentUserID int) (*model.ArticleResponse, error) {
+func (s *ArticleService) UpdateArticle(slug string, req model.UpdateArticleRequest, actor *utils.Claims) (*model.ArticleResponse, error) {
 	// Get existing article to check ownership
 	article, err := s.articleRepo.GetBySlug(slug)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check if current user is the author
-	if article.AuthorID != currentUserID {
-		return nil, fmt.Errorf("unauthorized: you can only update your own articles")
+	if err := s.authorizeArticleChange(article, actor, model.ModerationArticleUpdate, "", "you can only update your own articles"); err != nil {
+		return nil, err
 	}
 
+	return s.applyArticleUpdate(article, req, actor.UserID)
+}
+
+// applyArticleUpdate makes an authorized update to article on behalf of
+// currentUserID, who may be a moderator editing someone else's article
+func (s *ArticleService) applyArticleUpdate(article *model.Article, req model.UpdateArticleRequest, currentUserID int) (*model.ArticleResponse, error) {
 	// Build update map
 	updates := make(map[string]interface{})
 
@@ -115,12 +178,41 @@ func (s *ArticleService) UpdateArticle(slug string, req model.UpdateArticleReque
 		updates["body"] = *req.Article.Body
 	}
 
-	// Update article
-	updatedArticle, err := s.articleRepo.Update(slug, updates)
+	if req.Article.Status != nil {
+		if err := validateArticleStatus(*req.Article.Status); err != nil {
+			return nil, err
+		}
+		updates["status"] = *req.Article.Status
+		// An explicit status change cancels any pending schedule
+		updates["publish_at"] = nil
+	}
+
+	if req.Article.PublishAt != nil {
+		publishAt := req.Article.PublishAt.UTC()
+		updates["publish_at"] = publishAt
+		updates["status"] = publishStatusFor(publishAt)
+	}
+
+	// Publishing is up to the author's verification, whoever makes the change
+	if status, ok := updates["status"]; ok && (status != model.ArticleStatusDraft || req.Article.PublishAt != nil) {
+		if err := s.verification.Check(article.AuthorID, ActionPublish); err != nil {
+			return nil, err
+		}
+	}
+
+	// Update article, keeping edited content in the revision history
+	updatedArticle, err := s.articleRepo.UpdateWithRevision(article.Slug, updates, currentUserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to update article: %w", err)
 	}
 
+	// Queue the publish job; jobs from an earlier schedule become no-ops
+	if updatedArticle.Status == model.ArticleStatusDraft && updatedArticle.PublishAt != nil && req.Article.PublishAt != nil {
+		if err := s.schedulePublish(updatedArticle.ID, *updatedArticle.PublishAt); err != nil {
+			return nil, err
+		}
+	}
+
 	// Update tags if provided
 	if req.Article.TagList != nil {
 		err = s.tagService.UpdateTagsForArticle(updatedArticle.ID, req.Article.TagList)
@@ -129,31 +221,293 @@ func (s *ArticleService) UpdateArticle(slug string, req model.UpdateArticleReque
 		}
 	}
 
-	// If slug was updated, use the new slug
-	finalSlug := slug
-	if newSlug, ok := updates["slug"]; ok {
-		finalSlug = newSlug.(string)
-	}
-
-	return s.GetArticleBySlug(finalSlug, currentUserID)
+	// The change is authorized, so the response skips the draft visibility
+	// check that would hide a moderator's edit of someone else's draft
+	return s.buildArticleResponse(updatedArticle, currentUserID)
 }
 
 // DeleteArticle deletes an article
-func (s *ArticleService) DeleteArticle(slug string, currentUserID int) error {
+func (s *ArticleService) DeleteArticle(slug string, actor *utils.Claims) error {
 	// Get existing article to check ownership
 	article, err := s.articleRepo.GetBySlug(slug)
 	if err != nil {
 		return err
 	}
 
-	// Check if current user is the author
-	if article.AuthorID != currentUserID {
-		return fmt.Errorf("unauthorized: you can only delete your own articles")
+	if err := s.authorizeArticleChange(article, actor, model.ModerationArticleDelete, "", "you can only delete your own articles"); err != nil {
+		return err
 	}
 
 	return s.articleRepo.Delete(slug)
 }
 
+// PublishArticle makes a draft or unlisted article visible in listings
+func (s *ArticleService) PublishArticle(slug string, actor *utils.Claims) (*model.ArticleResponse, error) {
+	return s.setArticleStatus(slug, model.ArticleStatusPublished, actor)
+}
+
+// UnpublishArticle turns an article back into a draft visible only to its author
+func (s *ArticleService) UnpublishArticle(slug string, actor *utils.Claims) (*model.ArticleResponse, error) {
+	return s.setArticleStatus(slug, model.ArticleStatusDraft, actor)
+}
+
+// setArticleStatus changes the status of an article on behalf of actor
+func (s *ArticleService) setArticleStatus(slug, status string, actor *utils.Claims) (*model.ArticleResponse, error) {
+	currentUserID := actor.UserID
+
+	// Get existing article to check ownership
+	article, err := s.articleRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeArticleChange(article, actor, model.ModerationArticleStatus, "status: "+status, "you can only publish your own articles"); err != nil {
+		return nil, err
+	}
+
+	if status != model.ArticleStatusDraft {
+		if err := s.verification.Check(article.AuthorID, ActionPublish); err != nil {
+			return nil, err
+		}
+	}
+
+	// Publishing or unpublishing by hand cancels any pending schedule
+	updatedArticle, err := s.articleRepo.Update(slug, map[string]interface{}{
+		"status":     status,
+		"publish_at": nil,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update article status: %w", err)
+	}
+
+	return s.buildArticleResponse(updatedArticle, currentUserID)
+}
+
+// PublishScheduledArticle handles publish_article jobs run by the scheduler
+func (s *ArticleService) PublishScheduledArticle(payload string) error {
+	var p model.PublishArticlePayload
+	if err := json.Unmarshal([]byte(payload), &p); err != nil {
+		return fmt.Errorf("invalid publish job payload: %w", err)
+	}
+
+	// The repository only publishes drafts whose publish time has passed, so a
+	// job left over from a cancelled or moved schedule does nothing
+	if _, err := s.articleRepo.PublishScheduled(p.ArticleID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// schedulePublish queues a job that publishes the article at publishAt
+func (s *ArticleService) schedulePublish(articleID int, publishAt time.Time) error {
+	payload, err := json.Marshal(model.PublishArticlePayload{ArticleID: articleID})
+	if err != nil {
+		return fmt.Errorf("failed to encode publish job: %w", err)
+	}
+
+	job := &model.Job{
+		Type:    model.JobTypePublishArticle,
+		Payload: string(payload),
+		RunAt:   publishAt,
+	}
+	if err := s.jobRepo.Enqueue(job); err != nil {
+		return fmt.Errorf("failed to schedule article: %w", err)
+	}
+
+	return nil
+}
+
+// GetRevisions retrieves an article's revision history, newest first
+func (s *ArticleService) GetRevisions(slug string, currentUserID int) (*model.ArticleRevisionsResponse, error) {
+	article, err := s.getVisibleArticle(slug, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := s.revisionRepo.GetByArticle(article.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get revisions: %w", err)
+	}
+
+	summaries := make([]model.ArticleRevisionSummary, 0, len(revisions))
+	for _, revision := range revisions {
+		summaries = append(summaries, model.ArticleRevisionSummary{
+			Revision:  revision.Revision,
+			Title:     revision.Title,
+			Editor:    revision.EditorUsername,
+			CreatedAt: revision.CreatedAt,
+		})
+	}
+
+	return &model.ArticleRevisionsResponse{
+		Revisions:      summaries,
+		RevisionsCount: len(summaries),
+	}, nil
+}
+
+// GetRevision retrieves revision n of an article
+func (s *ArticleService) GetRevision(slug string, n int, currentUserID int) (*model.ArticleRevisionResponse, error) {
+	article, err := s.getVisibleArticle(slug, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := s.revisionRepo.GetByNumber(article.ID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ArticleRevisionResponse{
+		Revision:    revision.Revision,
+		Title:       revision.Title,
+		Description: revision.Description,
+		Body:        revision.Body,
+		Editor:      revision.EditorUsername,
+		CreatedAt:   revision.CreatedAt,
+	}, nil
+}
+
+// DiffRevisions computes a line-level diff from revision from to revision to.
+// A zero to means the latest revision and a zero from means the one before to.
+func (s *ArticleService) DiffRevisions(slug string, from, to int, currentUserID int) (*model.ArticleRevisionDiff, error) {
+	article, err := s.getVisibleArticle(slug, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if to == 0 {
+		to, err = s.revisionRepo.GetLatestNumber(article.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if from == 0 {
+		from = to - 1
+	}
+
+	fromRevision, err := s.revisionRepo.GetByNumber(article.ID, from)
+	if err != nil {
+		return nil, err
+	}
+	toRevision, err := s.revisionRepo.GetByNumber(article.ID, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ArticleRevisionDiff{
+		From:        from,
+		To:          to,
+		Title:       diffLines(fromRevision.Title, toRevision.Title),
+		Description: diffLines(fromRevision.Description, toRevision.Description),
+		Body:        diffLines(fromRevision.Body, toRevision.Body),
+	}, nil
+}
+
+// RestoreRevision brings back the content of revision n. A restore that
+// changes the content is itself recorded as a new revision, so it can be
+// undone.
+func (s *ArticleService) RestoreRevision(slug string, n int, actor *utils.Claims) (*model.ArticleResponse, error) {
+	article, err := s.articleRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the revision exists before a moderator's restore gets recorded
+	if !isVisibleTo(article, actor.UserID) {
+		return nil, fmt.Errorf("article not found")
+	}
+	revision, err := s.revisionRepo.GetByNumber(article.ID, n)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorizeArticleChange(article, actor, model.ModerationArticleRestore, fmt.Sprintf("revision: %d", n), "you can only restore your own articles"); err != nil {
+		return nil, err
+	}
+
+	var req model.UpdateArticleRequest
+	// Only send the title when it differs, since a new title means a new slug
+	if revision.Title != article.Title {
+		req.Article.Title = &revision.Title
+	}
+	req.Article.Description = &revision.Description
+	req.Article.Body = &revision.Body
+
+	return s.applyArticleUpdate(article, req, actor.UserID)
+}
+
+// authorizeArticleChange lets authors change their own articles and users
+// allowed to moderate articles change the visible articles of others, logging
+// each such override with details. Others get an unauthorized error with the
+// denied reason, or not found for drafts.
+func (s *ArticleService) authorizeArticleChange(article *model.Article, actor *utils.Claims, action, details, denied string) error {
+	if article.AuthorID == actor.UserID {
+		return nil
+	}
+
+	// Drafts stay private to their author, but moderators may delete them so
+	// that an article they unpublished can still be removed
+	if !isVisibleTo(article, actor.UserID) && (action != model.ModerationArticleDelete || !actor.HasPermission(model.PermissionArticlesModerate)) {
+		return fmt.Errorf("article not found")
+	}
+	if !actor.HasPermission(model.PermissionArticlesModerate) {
+		return fmt.Errorf("unauthorized: %s", denied)
+	}
+
+	summary := article.Slug
+	if details != "" {
+		summary += " (" + details + ")"
+	}
+	return s.moderation.recordOverride(actor, action, "article", article.ID, article.AuthorID, summary)
+}
+
+// resolveArticle retrieves an article by slug, following the slug history when
+// the article has since been renamed
+func (s *ArticleService) resolveArticle(slug string) (*model.Article, error) {
+	article, err := s.articleRepo.GetBySlug(slug)
+	if err == nil || err.Error() != "article not found" {
+		return article, err
+	}
+
+	_, canonical, err := s.articleRepo.ResolveSlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.articleRepo.GetBySlug(canonical)
+}
+
+// getVisibleArticle retrieves an article by slug if currentUserID may see it
+func (s *ArticleService) getVisibleArticle(slug string, currentUserID int) (*model.Article, error) {
+	article, err := s.articleRepo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isVisibleTo(article, currentUserID) {
+		return nil, fmt.Errorf("article not found")
+	}
+
+	return article, nil
+}
+
+// recordRevision snapshots the article's current content as its next revision
+func (s *ArticleService) recordRevision(article *model.Article, editorID int) error {
+	revision := &model.ArticleRevision{
+		ArticleID:   article.ID,
+		Title:       article.Title,
+		Description: article.Description,
+		Body:        article.Body,
+		EditorID:    editorID,
+	}
+	if err := s.revisionRepo.Create(revision); err != nil {
+		return fmt.Errorf("failed to record revision: %w", err)
+	}
+
+	return nil
+}
+
 // ArticleListParams represents parameters for listing articles
 type ArticleListParams struct {
 	Limit     int
@@ -161,6 +515,9 @@ type ArticleListParams struct {
 	Tag       string
 	Author    string
 	Favorited string
+	Sort      string // recent, top, trending or updated
+	Since     string // RFC 3339 time or a window such as 24h, 7d or 4w
+	Cursor    string // nextCursor of the previous page; replaces Offset
 }
 
 // GetArticles retrieves a list of articles with filtering and pagination
@@ -173,25 +530,65 @@ func (s *ArticleService) GetArticles(params ArticleListParams, currentUserID int
 		params.Limit = 100 // Max limit
 	}
 
-	// Get articles from repository
-	articles, totalCount, err := s.articleRepo.GetArticles(params.Limit, params.Offset, params.Tag, params.Author, params.Favorited)
+	switch params.Sort {
+	case "", model.ArticleSortRecent, model.ArticleSortTop, model.ArticleSortTrending, model.ArticleSortUpdated:
+	default:
+		return nil, fmt.Errorf("invalid sort")
+	}
+
+	since, err := parseSince(params.Since, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get articles: %w", err)
+		return nil, err
 	}
 
-	// Build article responses
-	articleResponses := make([]model.ArticleResponse, 0, len(articles))
-	for _, article := range articles {
-		articleResponse, err := s.buildArticleResponse(&article, currentUserID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build article response: %w", err)
+	// Only the recent ordering is keyed by creation time and id
+	recent := params.Sort == "" || params.Sort == model.ArticleSortRecent
+	before, err := decodeCursor(params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil {
+		if !recent {
+			return nil, fmt.Errorf("cursor requires sort=recent")
 		}
-		articleResponses = append(articleResponses, *articleResponse)
+		params.Offset = 0
+	}
+
+	// Get articles from repository, one extra to detect a next page
+	opts := repository.ArticleListOptions{
+		Limit:     params.Limit,
+		Offset:    params.Offset,
+		Tag:       params.Tag,
+		Author:    params.Author,
+		Favorited: params.Favorited,
+		Sort:      params.Sort,
+		Since:     since,
+		Before:    before,
+		Viewer:    currentUserID,
+	}
+	if recent {
+		opts.Limit++
+	}
+	articles, totalCount, err := s.articleRepo.ListArticles(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get articles: %w", err)
+	}
+
+	var nextCursor string
+	if recent {
+		articles, nextCursor = pageArticles(articles, params.Limit)
+	}
+
+	// Build article responses in bulk
+	articleResponses, err := s.buildArticleResponses(articles, currentUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build article responses: %w", err)
 	}
 
 	return &model.ArticlesResponse{
 		Articles:      articleResponses,
 		ArticlesCount: totalCount,
+		NextCursor:    nextCursor,
 	}, nil
 }
 
@@ -205,83 +602,182 @@ func (s *ArticleService) GetArticlesFeed(params ArticleListParams, currentUserID
 		params.Limit = 100 // Max limit
 	}
 
-	// Get feed articles from repository (articles from followed users)
-	articles, totalCount, err := s.articleRepo.GetFeedArticles(params.Limit, params.Offset, currentUserID)
+	before, err := decodeCursor(params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil {
+		params.Offset = 0
+	}
+
+	// Get feed articles from repository (articles from followed users), one
+	// extra to detect a next page
+	articles, totalCount, err := s.articleRepo.ListFeedArticles(currentUserID, params.Limit+1, params.Offset, before)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get feed articles: %w", err)
 	}
+	articles, nextCursor := pageArticles(articles, params.Limit)
 
-	// Build article responses
-	articleResponses := make([]model.ArticleResponse, 0, len(articles))
-	for _, article := range articles {
-		articleResponse, err := s.buildArticleResponse(&article, currentUserID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build article response: %w", err)
-		}
-		articleResponses = append(articleResponses, *articleResponse)
+	// Build article responses in bulk
+	articleResponses, err := s.buildArticleResponses(articles, currentUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build article responses: %w", err)
 	}
 
 	return &model.ArticlesResponse{
 		Articles:      articleResponses,
 		ArticlesCount: totalCount,
+		NextCursor:    nextCursor,
 	}, nil
 }
 
-// buildArticleResponse builds an article response with author information
-func (s *ArticleService) buildArticleResponse(article *model.Article, currentUserID int) (*model.ArticleResponse, error) {
-	// Get author information
-	author, err := s.userRepo.GetByID(article.AuthorID)
+// GetDrafts retrieves the current user's draft articles
+func (s *ArticleService) GetDrafts(params ArticleListParams, currentUserID int) (*model.ArticlesResponse, error) {
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100 // Max limit
+	}
+
+	articles, totalCount, err := s.articleRepo.GetDraftsByAuthor(currentUserID, params.Limit, params.Offset)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get author: %w", err)
+		return nil, fmt.Errorf("failed to get drafts: %w", err)
 	}
 
-	// Get article tags
-	tags, err := s.tagService.GetTagsForArticle(article.ID)
+	// Build article responses in bulk
+	articleResponses, err := s.buildArticleResponses(articles, currentUserID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get article tags: %w", err)
+		return nil, fmt.Errorf("failed to build article responses: %w", err)
 	}
 
-	// TODO: Implement following check
-	// For now, set to false
-	following := false
+	return &model.ArticlesResponse{
+		Articles:      articleResponses,
+		ArticlesCount: totalCount,
+	}, nil
+}
 
-	// Check if current user has favorited this article
-	favorited := false
-	if currentUserID > 0 {
-		var err error
-		favorited, err = s.articleRepo.IsFavorited(currentUserID, article.ID)
-		if err != nil {
-			// Log error but don't fail the whole response
-			favorited = false
-		}
+// ArticleSearchParams represents parameters for searching articles
+type ArticleSearchParams struct {
+	Query  string
+	Limit  int
+	Offset int
+}
+
+// SearchArticles retrieves articles matching a full-text query, best matches first
+func (s *ArticleService) SearchArticles(params ArticleSearchParams, currentUserID int) (*model.ArticleSearchResultsResponse, error) {
+	params.Query = strings.TrimSpace(params.Query)
+	if params.Query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+
+	// Set default limit
+	if params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100 // Max limit
+	}
+
+	results, totalCount, err := s.articleRepo.SearchArticles(params.Query, params.Limit, params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search articles: %w", err)
 	}
 
-	return &model.ArticleResponse{
-		Slug:           article.Slug,
-		Title:          article.Title,
-		Description:    article.Description,
-		Body:           article.Body,
-		TagList:        tags,
-		CreatedAt:      article.CreatedAt,
-		UpdatedAt:      article.UpdatedAt,
-		Favorited:      favorited,
-		FavoritesCount: article.FavoritesCount,
-		Author: model.AuthorProfile{
-			Username:  author.Username,
-			Bio:       author.Bio,
-			Image:     author.Image,
-			Following: following,
-		},
+	// Build article responses in bulk
+	articles := make([]model.Article, 0, len(results))
+	for _, result := range results {
+		articles = append(articles, result.Article)
+	}
+	responses, err := s.buildArticleResponses(articles, currentUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build article responses: %w", err)
+	}
+
+	articleResponses := make([]model.ArticleSearchResponse, 0, len(results))
+	for i, result := range results {
+		articleResponses = append(articleResponses, model.ArticleSearchResponse{
+			ArticleResponse: responses[i],
+			TitleHighlight:  result.TitleHighlight,
+			Snippet:         result.Snippet,
+		})
+	}
+
+	return &model.ArticleSearchResultsResponse{
+		Articles:      articleResponses,
+		ArticlesCount: totalCount,
 	}, nil
 }
 
+// buildArticleResponse builds an article response with author information
+func (s *ArticleService) buildArticleResponse(article *model.Article, currentUserID int) (*model.ArticleResponse, error) {
+	responses, err := s.buildArticleResponses([]model.Article{*article}, currentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &responses[0], nil
+}
+
+// buildArticleResponses builds responses for a page of articles, loading
+// authors, tags and the viewer's favorited and following flags in bulk
+func (s *ArticleService) buildArticleResponses(articles []model.Article, currentUserID int) ([]model.ArticleResponse, error) {
+	batch, err := s.articleLoader.Load(articles, currentUserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load article data: %w", err)
+	}
+
+	responses := make([]model.ArticleResponse, 0, len(articles))
+	for _, article := range articles {
+		author, ok := batch.Authors[article.AuthorID]
+		if !ok {
+			return nil, fmt.Errorf("failed to get author: user not found")
+		}
+
+		responses = append(responses, model.ArticleResponse{
+			Slug:           article.Slug,
+			Title:          article.Title,
+			Description:    article.Description,
+			Body:           article.Body,
+			TagList:        batch.Tags[article.ID],
+			Status:         article.Status,
+			PublishAt:      article.PublishAt,
+			CreatedAt:      article.CreatedAt,
+			UpdatedAt:      article.UpdatedAt,
+			Favorited:      batch.Favorited[article.ID],
+			FavoritesCount: article.FavoritesCount,
+			Author: model.AuthorProfile{
+				Username:  author.Username,
+				Bio:       author.Bio,
+				Image:     author.Image,
+				Following: batch.Following[article.AuthorID],
+			},
+		})
+	}
+
+	return responses, nil
+}
+
 // FavoriteArticle adds an article to user's favorites
 func (s *ArticleService) FavoriteArticle(slug string, userID int) (*model.ArticleResponse, error) {
-	// Get article by slug
-	article, err := s.articleRepo.GetBySlug(slug)
+	// Get article by current or historical slug
+	article, err := s.resolveArticle(slug)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get article: %w", err)
 	}
+	if !isVisibleTo(article, userID) {
+		return nil, fmt.Errorf("failed to get article: article not found")
+	}
+
+	// Users blocked by the author cannot favorite their articles
+	blocked, err := s.userRepo.IsBlocking(article.AuthorID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, fmt.Errorf("blocked by the author")
+	}
 
 	// Check if already favorited
 	isFavorited, err := s.articleRepo.IsFavorited(userID, article.ID)
@@ -312,11 +808,14 @@ func (s *ArticleService) FavoriteArticle(slug string, userID int) (*model.Articl
 
 // UnfavoriteArticle removes an article from user's favorites
 func (s *ArticleService) UnfavoriteArticle(slug string, userID int) (*model.ArticleResponse, error) {
-	// Get article by slug
-	article, err := s.articleRepo.GetBySlug(slug)
+	// Get article by current or historical slug
+	article, err := s.resolveArticle(slug)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get article: %w", err)
 	}
+	if !isVisibleTo(article, userID) {
+		return nil, fmt.Errorf("failed to get article: article not found")
+	}
 
 	// Remove from favorites
 	err = s.articleRepo.UnfavoriteArticle(userID, article.ID)
@@ -334,3 +833,79 @@ func (s *ArticleService) UnfavoriteArticle(slug string, userID int) (*model.Arti
 	// Build and return article response
 	return s.buildArticleResponse(article, userID)
 }
+
+// parseSince parses the since parameter of article lists, either an RFC 3339
+// time or a window back from now in hours (h), days (d) or weeks (w)
+func parseSince(value string, now time.Time) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	units := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	unit, ok := units[value[len(value)-1]]
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if !ok || err != nil || n <= 0 {
+		return nil, fmt.Errorf("invalid since")
+	}
+
+	t := now.Add(-time.Duration(n) * unit)
+	return &t, nil
+}
+
+// decodeCursor parses an optional cursor parameter
+func decodeCursor(token string) (*utils.Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	return utils.DecodeCursor(token)
+}
+
+// pageArticles trims a page fetched with one extra article and returns the
+// cursor of the next page, or "" when this is the last one
+func pageArticles(articles []model.Article, limit int) ([]model.Article, string) {
+	if len(articles) <= limit {
+		return articles, ""
+	}
+
+	last := articles[limit-1]
+	return articles[:limit], utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+}
+
+// isVisibleTo reports whether an article can be seen by the given user
+func isVisibleTo(article *model.Article, userID int) bool {
+	return article.Status != model.ArticleStatusDraft || article.AuthorID == userID
+}
+
+// diffLines converts a utils line diff into its API form
+func diffLines(a, b string) []model.DiffLine {
+	changes := utils.DiffLines(a, b)
+
+	lines := make([]model.DiffLine, 0, len(changes))
+	for _, change := range changes {
+		lines = append(lines, model.DiffLine{Op: string(change.Op), Text: change.Text})
+	}
+
+	return lines
+}
+
+// publishStatusFor returns the status of an article scheduled for publishAt
+func publishStatusFor(publishAt time.Time) string {
+	if publishAt.After(time.Now()) {
+		return model.ArticleStatusDraft
+	}
+	return model.ArticleStatusPublished
+}
+
+// validateArticleStatus checks that status is a known article status
+func validateArticleStatus(status string) error {
+	switch status {
+	case model.ArticleStatusDraft, model.ArticleStatusPublished, model.ArticleStatusUnlisted:
+		return nil
+	default:
+		return fmt.Errorf("invalid article status")
+	}
+}
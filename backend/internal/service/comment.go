@@ -5,33 +5,82 @@ import (
 
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
 )
 
 type CommentService struct {
-	commentRepo *repository.CommentRepository
-	userRepo    *repository.UserRepository
+	commentRepo  *repository.CommentRepository
+	articleRepo  *repository.ArticleRepository
+	userRepo     *repository.UserRepository
+	verification *EmailVerificationPolicy
+	moderation   *ModerationService
 }
 
-func NewCommentService(commentRepo *repository.CommentRepository, userRepo *repository.UserRepository) *CommentService {
+func NewCommentService(commentRepo *repository.CommentRepository, articleRepo *repository.ArticleRepository, userRepo *repository.UserRepository, verification *EmailVerificationPolicy, moderation *ModerationService) *CommentService {
 	return &CommentService{
-		commentRepo: commentRepo,
-		userRepo:    userRepo,
+		commentRepo:  commentRepo,
+		articleRepo:  articleRepo,
+		userRepo:     userRepo,
+		verification: verification,
+		moderation:   moderation,
 	}
 }
 
-func (s *CommentService) GetCommentsByArticleSlug(slug string, currentUserID int) ([]*model.Comment, error) {
-	comments, err := s.commentRepo.GetByArticleSlug(slug)
+// CommentListParams represents parameters for listing comments. Without a
+// limit or cursor every comment is returned.
+type CommentListParams struct {
+	Limit  int
+	Cursor string // nextCursor of the previous page
+}
+
+// GetCommentsByArticle lists the comments of article, which the caller has
+// resolved with ResolveArticle
+func (s *CommentService) GetCommentsByArticle(article *model.Article, params CommentListParams, currentUserID int) (*model.CommentsResponse, error) {
+	before, err := decodeCursor(params.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	paged := params.Limit > 0 || before != nil
+	if paged && params.Limit <= 0 {
+		params.Limit = 20
+	}
+	if params.Limit > 100 {
+		params.Limit = 100 // Max limit
+	}
+
+	// Fetch one extra comment to detect a next page
+	limit := 0
+	if paged {
+		limit = params.Limit + 1
+	}
+	comments, err := s.commentRepo.ListByArticleSlug(article.Slug, currentUserID, limit, before)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
 
-	// Set following status for each comment author (simplified for now)
-	for _, comment := range comments {
-		// TODO: Implement following functionality when user profile/follow features are added
-		comment.Author.Following = false
+	var nextCursor string
+	if paged && len(comments) > params.Limit {
+		last := comments[params.Limit-1]
+		comments = comments[:params.Limit]
+		nextCursor = utils.EncodeCursor(utils.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	// Set following status for each comment author
+	if err := s.setFollowing(comments, currentUserID); err != nil {
+		return nil, err
 	}
 
-	return comments, nil
+	return &model.CommentsResponse{
+		Comments:   comments,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+// ResolveArticle returns the article that uses or used slug, if currentUserID
+// may see it. The comments of a draft are as private as the draft.
+func (s *CommentService) ResolveArticle(slug string, currentUserID int) (*model.Article, error) {
+	return s.getVisibleArticle(slug, currentUserID)
 }
 
 func (s *CommentService) CreateComment(articleSlug, body string, authorID int) (*model.Comment, error) {
@@ -39,17 +88,31 @@ func (s *CommentService) CreateComment(articleSlug, body string, authorID int) (
 		return nil, fmt.Errorf("comment body cannot be empty")
 	}
 
-	// Get article ID by slug
-	articleID, err := s.commentRepo.GetArticleIDBySlug(articleSlug)
+	if err := s.verification.Check(authorID, ActionComment); err != nil {
+		return nil, err
+	}
+
+	// Get the article by current or historical slug; only its author can
+	// comment on a draft
+	article, err := s.getVisibleArticle(articleSlug, authorID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find article: %w", err)
 	}
 
+	// Users blocked by the author cannot comment on their articles
+	blocked, err := s.userRepo.IsBlocking(article.AuthorID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked {
+		return nil, fmt.Errorf("blocked by the author")
+	}
+
 	// Create comment
 	comment := &model.Comment{
 		Body:      body,
 		AuthorID:  authorID,
-		ArticleID: articleID,
+		ArticleID: article.ID,
 	}
 
 	err = s.commentRepo.Create(comment)
@@ -64,25 +127,35 @@ func (s *CommentService) CreateComment(articleSlug, body string, authorID int) (
 	}
 
 	comment.Author = &model.ProfileResponse{
-		Username:  author.Username,
-		Bio:       author.Bio,
-		Image:     author.Image,
-		Following: false, // Default to false for newly created comment
+		Username: author.Username,
+		Bio:      author.Bio,
+		Image:    author.Image,
+	}
+
+	if err := s.setFollowing([]*model.Comment{comment}, authorID); err != nil {
+		return nil, err
 	}
 
 	return comment, nil
 }
 
-func (s *CommentService) DeleteComment(commentID, authorID int) error {
+func (s *CommentService) DeleteComment(commentID int, actor *utils.Claims) error {
 	// Get comment to verify ownership
 	comment, err := s.commentRepo.GetByID(commentID)
 	if err != nil {
 		return fmt.Errorf("failed to get comment: %w", err)
 	}
 
-	// Check if user is the author of the comment
-	if comment.AuthorID != authorID {
-		return fmt.Errorf("unauthorized: only comment author can delete the comment")
+	// Only the author and users allowed to moderate comments may delete it;
+	// the latter are logged
+	if comment.AuthorID != actor.UserID {
+		if !actor.HasPermission(model.PermissionCommentsModerate) {
+			return fmt.Errorf("unauthorized: only comment author can delete the comment")
+		}
+		details := fmt.Sprintf("article %d: %s", comment.ArticleID, excerpt(comment.Body, 100))
+		if err := s.moderation.recordOverride(actor, model.ModerationCommentDelete, "comment", comment.ID, comment.AuthorID, details); err != nil {
+			return err
+		}
 	}
 
 	// Delete comment
@@ -93,3 +166,55 @@ func (s *CommentService) DeleteComment(commentID, authorID int) error {
 
 	return nil
 }
+
+// getVisibleArticle retrieves an article by current or historical slug if
+// viewerID may see it: drafts are only visible to their author
+func (s *CommentService) getVisibleArticle(slug string, viewerID int) (*model.Article, error) {
+	_, canonical, err := s.articleRepo.ResolveSlug(slug)
+	if err != nil {
+		return nil, err
+	}
+
+	article, err := s.articleRepo.GetBySlug(canonical)
+	if err != nil {
+		return nil, err
+	}
+	if !isVisibleTo(article, viewerID) {
+		return nil, fmt.Errorf("article not found")
+	}
+
+	return article, nil
+}
+
+// setFollowing fills in whether viewerID follows each comment's author, using
+// one query for all comments
+func (s *CommentService) setFollowing(comments []*model.Comment, viewerID int) error {
+	authorIDs := make([]int, 0, len(comments))
+	seen := make(map[int]bool)
+	for _, comment := range comments {
+		if !seen[comment.AuthorID] {
+			seen[comment.AuthorID] = true
+			authorIDs = append(authorIDs, comment.AuthorID)
+		}
+	}
+
+	following, err := s.userRepo.GetFollowedAmong(viewerID, authorIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get following status: %w", err)
+	}
+
+	for _, comment := range comments {
+		comment.Author.Following = following[comment.AuthorID]
+	}
+
+	return nil
+}
+
+// excerpt shortens text to at most n characters
+func excerpt(text string, n int) string {
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	return string(runes[:n]) + "…"
+}
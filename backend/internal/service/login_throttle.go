@@ -0,0 +1,203 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+const (
+	// accountBackoffAfter is how many failed logins an account gets before
+	// each further attempt has to wait, doubling from loginBackoffBase
+	accountBackoffAfter = 3
+	// accountLockoutAfter is how many failed logins lock an account
+	accountLockoutAfter = 10
+	// accountLockoutDuration is how long a locked account stays locked unless
+	// unlocked with the mailed link
+	accountLockoutDuration = 30 * time.Minute
+	// ipWindow is how far back failed logins from an IP address count
+	ipWindow = 15 * time.Minute
+	// ipBackoffAfter is how many failed logins an IP address gets within
+	// ipWindow before each further attempt has to wait
+	ipBackoffAfter = 20
+	// ipLockoutAfter is how many failed logins within ipWindow block an IP
+	// address for the rest of the window
+	ipLockoutAfter = 100
+	// loginBackoffBase is the first wait once backoff starts
+	loginBackoffBase = time.Second
+	// maxLoginBackoff caps the exponential wait
+	maxLoginBackoff = 15 * time.Minute
+	// unlockTokenTTL is how long a mailed unlock link can be used
+	unlockTokenTTL = 24 * time.Hour
+)
+
+// LoginThrottledError is returned for logins attempted before the backoff or
+// lockout of the account or IP address has passed
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return "too many login attempts"
+}
+
+// loginBackoff returns how long to wait after the latest of failures when
+// backoff starts after the given number of failures
+func loginBackoff(failures, after int) time.Duration {
+	if failures < after {
+		return 0
+	}
+
+	backoff := loginBackoffBase
+	for i := after; i < failures; i++ {
+		backoff *= 2
+		if backoff >= maxLoginBackoff {
+			return maxLoginBackoff
+		}
+	}
+
+	return backoff
+}
+
+// checkLoginThrottle returns a LoginThrottledError while logins from ipAddress,
+// or to user when known, have to wait
+func (s *UserService) checkLoginThrottle(user *model.User, ipAddress string, now time.Time) error {
+	var retryAfter time.Duration
+
+	count, latest, err := s.loginAttemptRepo.IPFailuresSince(ipAddress, now.Add(-ipWindow))
+	if err != nil {
+		return err
+	}
+	if latest != nil {
+		wait := loginBackoff(count, ipBackoffAfter)
+		if count >= ipLockoutAfter {
+			wait = ipWindow
+		}
+		retryAfter = latest.Add(wait).Sub(now)
+	}
+
+	if user != nil {
+		state, err := s.loginAttemptRepo.GetAccountState(user.ID)
+		if err != nil {
+			return err
+		}
+		if state.LockedUntil != nil && state.LockedUntil.Sub(now) > retryAfter {
+			retryAfter = state.LockedUntil.Sub(now)
+		}
+		if state.LastFailedAt != nil {
+			if wait := state.LastFailedAt.Add(loginBackoff(state.FailedLogins, accountBackoffAfter)).Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+// recordLoginFailure counts a failed login from ipAddress, and to user when
+// known, locking the account or IP address out once failures pile up.
+// Failures to record are logged so that they do not turn into failed logins.
+func (s *UserService) recordLoginFailure(user *model.User, email, ipAddress string, now time.Time) {
+	if err := s.loginAttemptRepo.RecordIPFailure(ipAddress, email, now, now.Add(-ipWindow)); err != nil {
+		log.Printf("login throttle: %v", err)
+	} else if count, _, err := s.loginAttemptRepo.IPFailuresSince(ipAddress, now.Add(-ipWindow)); err != nil {
+		log.Printf("login throttle: %v", err)
+	} else if count == ipLockoutAfter {
+		event := &model.LockoutEvent{IPAddress: ipAddress, Scope: model.LockoutScopeIP, LockedUntil: now.Add(ipWindow)}
+		if err := s.loginAttemptRepo.RecordLockout(event); err != nil {
+			log.Printf("login throttle: %v", err)
+		}
+		log.Printf("login throttle: blocked logins from %s until %s", ipAddress, event.LockedUntil.Format(time.RFC3339))
+	}
+
+	if user == nil {
+		return
+	}
+
+	failures, err := s.loginAttemptRepo.RecordAccountFailure(user.ID, now)
+	if err != nil {
+		log.Printf("login throttle: %v", err)
+		return
+	}
+	if failures < accountLockoutAfter {
+		return
+	}
+
+	until := now.Add(accountLockoutDuration)
+	if err := s.loginAttemptRepo.LockAccount(user.ID, until); err != nil {
+		log.Printf("login throttle: %v", err)
+		return
+	}
+	event := &model.LockoutEvent{UserID: &user.ID, IPAddress: ipAddress, Scope: model.LockoutScopeAccount, LockedUntil: until}
+	if err := s.loginAttemptRepo.RecordLockout(event); err != nil {
+		log.Printf("login throttle: %v", err)
+	}
+	log.Printf("login throttle: locked account %d until %s", user.ID, until.Format(time.RFC3339))
+
+	if err := s.sendUnlockEmail(user); err != nil {
+		log.Printf("Failed to send unlock email to user %d: %v", user.ID, err)
+	}
+}
+
+// RecordLoginSuccess forgets the failed logins of user. It is called once a
+// session is started, so that a correct password alone does not clear the
+// failures of the second factor.
+func (s *UserService) RecordLoginSuccess(user *model.User) {
+	state, err := s.loginAttemptRepo.GetAccountState(user.ID)
+	if err != nil {
+		log.Printf("login throttle: %v", err)
+		return
+	}
+	if state.FailedLogins == 0 && state.LockedUntil == nil {
+		return
+	}
+
+	if err := s.loginAttemptRepo.ResetAccount(user.ID); err != nil {
+		log.Printf("login throttle: %v", err)
+	}
+}
+
+// UnlockAccount lifts the lockout of the user an unlock token was mailed to
+func (s *UserService) UnlockAccount(token string) error {
+	if token == "" {
+		return fmt.Errorf("unlock token is required")
+	}
+
+	_, err := s.loginAttemptRepo.ConsumeUnlockToken(utils.HashOpaqueToken(token))
+	return err
+}
+
+// sendUnlockEmail tells a user their account was locked and mails them a link
+// to unlock it
+func (s *UserService) sendUnlockEmail(user *model.User) error {
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	unlock := &model.AccountUnlockToken{UserID: user.ID, TokenHash: tokenHash, ExpiresAt: time.Now().Add(unlockTokenTTL)}
+	if err := s.loginAttemptRepo.CreateUnlockToken(unlock); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/unlock-account?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Your RealWorld account was locked",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"After %d failed login attempts your RealWorld account is locked for %d minutes. "+
+			"If it was you, open the link below to unlock it now:\n\n%s\n\n"+
+			"If it was not you, someone may be guessing your password. "+
+			"Consider choosing a new one and enabling two-factor authentication.\n",
+			user.Username, accountLockoutAfter, int(accountLockoutDuration.Minutes()), link),
+	})
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWrongTwoFactorCodesCountAsFailedLogins(t *testing.T) {
+	s := newTestServices(t)
+	user := s.createUser(t, "alice")
+	recoveryCodes := s.enableTwoFactor(t, user)
+	const ip = "203.0.113.7"
+
+	// The password is right every time, the code never is
+	var challenge string
+	for i := 0; i < accountBackoffAfter; i++ {
+		if _, err := s.users.AuthenticateUser(user.Email, "password123", ip); err != nil {
+			t.Fatalf("AuthenticateUser() #%d error = %v", i+1, err)
+		}
+		token, err := s.twoFactor.StartChallenge(user.ID)
+		if err != nil {
+			t.Fatalf("StartChallenge() error = %v", err)
+		}
+		if _, err := s.twoFactor.CompleteChallenge(token, "wrong-code", ip); err == nil || err.Error() != "invalid two-factor code" {
+			t.Fatalf("CompleteChallenge(wrong code) error = %v, want invalid two-factor code", err)
+		}
+		challenge = token
+	}
+
+	// A correct password did not clear the failures of the codes
+	state, err := s.attempts.GetAccountState(user.ID)
+	if err != nil || state.FailedLogins != accountBackoffAfter {
+		t.Fatalf("GetAccountState() = %+v, %v; want %d failed logins", state, err, accountBackoffAfter)
+	}
+	if count, _, err := s.attempts.IPFailuresSince(ip, state.LastFailedAt.Add(-ipWindow)); err != nil || count != accountBackoffAfter {
+		t.Errorf("IPFailuresSince() = %d, %v; want %d", count, err, accountBackoffAfter)
+	}
+
+	// Both the password and the code now have to wait
+	var throttled *LoginThrottledError
+	if _, err := s.users.AuthenticateUser(user.Email, "password123", ip); !errors.As(err, &throttled) {
+		t.Errorf("AuthenticateUser() during backoff error = %v, want LoginThrottledError", err)
+	}
+	if _, err := s.twoFactor.CompleteChallenge(challenge, recoveryCodes[0], ip); !errors.As(err, &throttled) {
+		t.Errorf("CompleteChallenge() during backoff error = %v, want LoginThrottledError", err)
+	}
+
+	// Completing the login clears them
+	s.waitOutBackoff(t, user)
+	if got, err := s.twoFactor.CompleteChallenge(challenge, recoveryCodes[0], ip); err != nil || got.ID != user.ID {
+		t.Fatalf("CompleteChallenge() = %+v, %v; want alice", got, err)
+	}
+	if state, err := s.attempts.GetAccountState(user.ID); err != nil || state.FailedLogins != 0 {
+		t.Errorf("GetAccountState() after login = %+v, %v; want no failed logins", state, err)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// ModerationService keeps the log of moderators acting on content of other
+// users and of admins acting on accounts
+type ModerationService struct {
+	moderationRepo *repository.ModerationRepository
+}
+
+// NewModerationService creates a new moderation service
+func NewModerationService(moderationRepo *repository.ModerationRepository) *ModerationService {
+	return &ModerationService{moderationRepo: moderationRepo}
+}
+
+// ListActions returns the moderation log, newest first
+func (s *ModerationService) ListActions(limit, offset int) (*model.ModerationActionsResponse, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	actions, err := s.moderationRepo.List(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ModerationActionsResponse{Actions: actions}, nil
+}
+
+// recordOverride logs actor acting on content owned by ownerID. It is called
+// before the change is made so that no override goes unrecorded.
+func (s *ModerationService) recordOverride(actor *utils.Claims, action, targetType string, targetID, ownerID int, details string) error {
+	return s.moderationRepo.Record(&model.ModerationAction{
+		ActorID:       &actor.UserID,
+		Action:        action,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		TargetOwnerID: &ownerID,
+		Details:       details,
+	})
+}
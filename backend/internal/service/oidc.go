@@ -0,0 +1,250 @@
+package service
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/oidc"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// OIDCStateTTL is how long a user has to log in at the issuer
+const OIDCStateTTL = 10 * time.Minute
+
+const (
+	// oidcLoginCodeTTL is how long the frontend has to exchange a login code
+	oidcLoginCodeTTL = time.Minute
+	// maxUsernameAttempts is how many numbered variants of a username are
+	// tried before giving up
+	maxUsernameAttempts = 100
+)
+
+// OIDCService handles logging in through an OpenID Connect issuer, linking
+// its identities to users and signing up new ones
+type OIDCService struct {
+	provider    *oidc.Provider
+	oidcRepo    *repository.OIDCRepository
+	userRepo    *repository.UserRepository
+	userService *UserService
+}
+
+// NewOIDCService creates a new OIDC service
+func NewOIDCService(provider *oidc.Provider, oidcRepo *repository.OIDCRepository, userRepo *repository.UserRepository, userService *UserService) *OIDCService {
+	return &OIDCService{
+		provider:    provider,
+		oidcRepo:    oidcRepo,
+		userRepo:    userRepo,
+		userService: userService,
+	}
+}
+
+// StartLogin stores a new authorization request and returns the issuer URL to
+// send the browser to, with the state the browser must keep to come back with
+func (s *OIDCService) StartLogin() (string, string, error) {
+	state, stateHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, _, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := s.provider.AuthCodeURL(state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return "", "", err
+	}
+
+	request := &model.OIDCState{StateHash: stateHash, CodeVerifier: verifier, Nonce: nonce, ExpiresAt: time.Now().Add(OIDCStateTTL)}
+	if err := s.oidcRepo.CreateState(request); err != nil {
+		return "", "", err
+	}
+
+	return authURL, state, nil
+}
+
+// HandleCallback completes an authorization request with the code the issuer
+// redirected back with. browserState is the state kept by the browser that
+// started the login; it must match state, so that a callback URL cannot be
+// passed to another browser to log it in. It returns a single-use login code
+// for the user the identity belongs to, signing them up on their first login.
+func (s *OIDCService) HandleCallback(state, browserState, code string) (string, error) {
+	if state == "" || code == "" {
+		return "", fmt.Errorf("state and code are required")
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return "", fmt.Errorf("login state mismatch")
+	}
+
+	request, err := s.oidcRepo.ConsumeState(utils.HashOpaqueToken(state))
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := s.provider.Exchange(code, request.CodeVerifier, request.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.resolveUser(claims)
+	if err != nil {
+		return "", err
+	}
+	if user.SuspendedAt != nil {
+		return "", fmt.Errorf("account suspended")
+	}
+
+	loginCode, codeHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.oidcRepo.CreateLoginCode(&model.OIDCLoginCode{UserID: user.ID, CodeHash: codeHash, ExpiresAt: time.Now().Add(oidcLoginCodeTTL)}); err != nil {
+		return "", err
+	}
+
+	return loginCode, nil
+}
+
+// ExchangeLoginCode uses up a login code and returns its user
+func (s *OIDCService) ExchangeLoginCode(code string) (*model.User, error) {
+	if code == "" {
+		return nil, fmt.Errorf("login code is required")
+	}
+
+	userID, err := s.oidcRepo.ConsumeLoginCode(utils.HashOpaqueToken(code))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.GetByID(userID)
+}
+
+// resolveUser returns the user of an identity. Unknown identities are linked
+// to the user with the same email when both the issuer and the user have
+// verified it, and sign up a new user otherwise.
+func (s *OIDCService) resolveUser(claims *oidc.Claims) (*model.User, error) {
+	identity, err := s.oidcRepo.GetIdentity(s.provider.Issuer(), claims.Subject)
+	if err == nil {
+		return s.userRepo.GetByID(identity.UserID)
+	}
+	if err.Error() != "identity not found" {
+		return nil, err
+	}
+
+	if err := s.userService.validateEmail(claims.Email); err != nil {
+		return nil, fmt.Errorf("identity provider did not share a valid email")
+	}
+
+	identity = &model.UserIdentity{Issuer: s.provider.Issuer(), Subject: claims.Subject, Email: claims.Email}
+
+	existing, err := s.userRepo.GetByEmail(claims.Email)
+	if err == nil {
+		// Linking on an email either side has not verified would let whoever
+		// controls that side take over the account
+		if !bool(claims.EmailVerified) || !existing.EmailVerified {
+			return nil, fmt.Errorf("email already exists")
+		}
+		identity.UserID = existing.ID
+		if err := s.oidcRepo.LinkIdentity(identity); err != nil {
+			return nil, err
+		}
+		return existing, nil
+	}
+	if err.Error() != "user not found" {
+		return nil, err
+	}
+
+	username, err := s.provisionUsername(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Email:         claims.Email,
+		Username:      username,
+		PasswordHash:  model.UnusablePasswordHash,
+		EmailVerified: bool(claims.EmailVerified),
+	}
+	if err := s.oidcRepo.CreateUserWithIdentity(user, identity); err != nil {
+		return nil, err
+	}
+
+	if !user.EmailVerified {
+		if err := s.userService.sendVerificationEmail(user, user.Email); err != nil {
+			log.Printf("oidc: %v", err)
+		}
+	}
+
+	return user, nil
+}
+
+// provisionUsername picks a free username for a new user from their preferred
+// username, name or email, numbering it when taken
+func (s *OIDCService) provisionUsername(claims *oidc.Claims) (string, error) {
+	base := "user"
+	localPart, _, _ := strings.Cut(claims.Email, "@")
+	for _, candidate := range []string{claims.PreferredUsername, claims.Name, localPart} {
+		if sanitized := sanitizeUsername(candidate); len(sanitized) >= 3 {
+			base = sanitized
+			break
+		}
+	}
+
+	for i := 1; i <= maxUsernameAttempts; i++ {
+		username := base
+		if i > 1 {
+			suffix := strconv.Itoa(i)
+			username = strings.TrimRight(truncate(base, 20-len(suffix)-1), "_") + "_" + suffix
+		}
+		if err := s.userService.validateUsername(username); err != nil {
+			return "", err
+		}
+
+		exists, err := s.userRepo.UsernameExists(username)
+		if err != nil {
+			return "", fmt.Errorf("failed to check username existence: %w", err)
+		}
+		if !exists {
+			return username, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to find a free username for %q", base)
+}
+
+// sanitizeUsername turns a name into the letters, numbers and underscores a
+// username allows, at most 20 of them
+func sanitizeUsername(name string) string {
+	var b strings.Builder
+	for _, char := range name {
+		switch {
+		case (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || (char >= '0' && char <= '9'):
+			b.WriteRune(char)
+		case char == '_' || char == '.' || char == '-' || char == ' ':
+			// Collapse separators into single underscores
+			if s := b.String(); s != "" && !strings.HasSuffix(s, "_") {
+				b.WriteByte('_')
+			}
+		}
+	}
+
+	return strings.Trim(truncate(b.String(), 20), "_")
+}
+
+// truncate shortens an ASCII string to at most n bytes
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -38,6 +38,22 @@ func (s *ProfileService) FollowUser(followerID int, username string) (*model.Pro
 		return nil, fmt.Errorf("cannot follow yourself")
 	}
 
+	// Blocks keep the two users apart in both directions
+	blocked, err := s.userRepo.IsBlocking(followed.ID, followerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block status: %w", err)
+	}
+	if blocked {
+		return nil, fmt.Errorf("blocked by this user")
+	}
+	blocking, err := s.userRepo.IsBlocking(followerID, followed.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block status: %w", err)
+	}
+	if blocking {
+		return nil, fmt.Errorf("cannot follow a user you blocked")
+	}
+
 	// Check if already following
 	isFollowing, err := s.userRepo.IsFollowing(followerID, followed.ID)
 	if err != nil {
@@ -88,3 +104,85 @@ func (s *ProfileService) UnfollowUser(followerID int, username string) (*model.P
 
 	return profile, nil
 }
+
+// BlockUser blocks a user: they can no longer follow the blocker, nor comment
+// on or favorite their articles. Follows between the two are removed.
+func (s *ProfileService) BlockUser(blockerID int, username string) (*model.ProfileResponse, error) {
+	blocked, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if blockerID == blocked.ID {
+		return nil, fmt.Errorf("cannot block yourself")
+	}
+
+	isBlocking, err := s.userRepo.IsBlocking(blockerID, blocked.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check block status: %w", err)
+	}
+	if isBlocking {
+		return nil, fmt.Errorf("already blocking this user")
+	}
+
+	if err := s.userRepo.BlockUser(blockerID, blocked.ID); err != nil {
+		return nil, fmt.Errorf("failed to block user: %w", err)
+	}
+
+	return s.GetProfile(username, &blockerID)
+}
+
+// UnblockUser lifts a block
+func (s *ProfileService) UnblockUser(blockerID int, username string) (*model.ProfileResponse, error) {
+	blocked, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.userRepo.UnblockUser(blockerID, blocked.ID); err != nil {
+		return nil, fmt.Errorf("failed to unblock user: %w", err)
+	}
+
+	return s.GetProfile(username, &blockerID)
+}
+
+// MuteUser mutes a user: their articles and comments are left out of the
+// article list, feed and comments of the muter
+func (s *ProfileService) MuteUser(muterID int, username string) (*model.ProfileResponse, error) {
+	muted, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if muterID == muted.ID {
+		return nil, fmt.Errorf("cannot mute yourself")
+	}
+
+	isMuting, err := s.userRepo.IsMuting(muterID, muted.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check mute status: %w", err)
+	}
+	if isMuting {
+		return nil, fmt.Errorf("already muting this user")
+	}
+
+	if err := s.userRepo.MuteUser(muterID, muted.ID); err != nil {
+		return nil, fmt.Errorf("failed to mute user: %w", err)
+	}
+
+	return s.GetProfile(username, &muterID)
+}
+
+// UnmuteUser lifts a mute
+func (s *ProfileService) UnmuteUser(muterID int, username string) (*model.ProfileResponse, error) {
+	muted, err := s.userRepo.GetByUsername(username)
+	if err != nil {
+		return nil, fmt.Errorf("user not found: %w", err)
+	}
+
+	if err := s.userRepo.UnmuteUser(muterID, muted.ID); err != nil {
+		return nil, fmt.Errorf("failed to unmute user: %w", err)
+	}
+
+	return s.GetProfile(username, &muterID)
+}
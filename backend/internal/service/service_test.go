@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/db"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// migrationsDir is the migrations root relative to this package
+const migrationsDir = "../../migrations"
+
+// testServices are the account services over a freshly migrated SQLite
+// database
+type testServices struct {
+	db        *db.Database
+	mail      *recordingMailer
+	users     *UserService
+	twoFactor *TwoFactorService
+	attempts  *repository.LoginAttemptRepository
+}
+
+// newTestServices creates the account services with a cheap password hasher
+// and a mailer that records what it sends
+func newTestServices(t *testing.T) *testServices {
+	t.Helper()
+
+	database, err := db.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to open sqlite: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	if err := database.MigrateFrom(migrationsDir); err != nil {
+		t.Fatalf("failed to migrate sqlite: %v", err)
+	}
+
+	hasher, err := utils.NewPasswordHasher(utils.PasswordParams{
+		Algorithm:         utils.PasswordAlgorithmArgon2id,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	})
+	if err != nil {
+		t.Fatalf("NewPasswordHasher() error = %v", err)
+	}
+
+	mail := &recordingMailer{}
+	userRepo := repository.NewUserRepository(database)
+	attempts := repository.NewLoginAttemptRepository(database)
+	users := NewUserService(
+		userRepo,
+		repository.NewPasswordResetRepository(database),
+		repository.NewEmailVerificationRepository(database),
+		attempts,
+		hasher,
+		mail,
+		"http://app.test",
+	)
+
+	return &testServices{
+		db:        database,
+		mail:      mail,
+		users:     users,
+		twoFactor: NewTwoFactorService(repository.NewTwoFactorRepository(database), userRepo, users),
+		attempts:  attempts,
+	}
+}
+
+// createUser signs up a user with the password "password123"
+func (s *testServices) createUser(t *testing.T, username string) *model.User {
+	t.Helper()
+
+	var req model.CreateUserRequest
+	req.User.Username = username
+	req.User.Email = username + "@example.com"
+	req.User.Password = "password123"
+
+	user, err := s.users.CreateUser(req)
+	if err != nil {
+		t.Fatalf("CreateUser(%s) error = %v", username, err)
+	}
+	return user
+}
+
+// enableTwoFactor turns two-factor authentication on for user and returns
+// their recovery codes
+func (s *testServices) enableTwoFactor(t *testing.T, user *model.User) []string {
+	t.Helper()
+
+	setup, err := s.twoFactor.BeginEnrollment(user.ID)
+	if err != nil {
+		t.Fatalf("BeginEnrollment() error = %v", err)
+	}
+	code, err := utils.TOTPCode(setup.Secret, utils.TOTPStep(time.Now()))
+	if err != nil {
+		t.Fatalf("TOTPCode() error = %v", err)
+	}
+	recoveryCodes, err := s.twoFactor.ConfirmEnrollment(user.ID, code)
+	if err != nil {
+		t.Fatalf("ConfirmEnrollment() error = %v", err)
+	}
+	return recoveryCodes
+}
+
+// waitOutBackoff moves the last failed login of user back in time, as if the
+// backoff it started had passed
+func (s *testServices) waitOutBackoff(t *testing.T, user *model.User) {
+	t.Helper()
+
+	if _, err := s.db.Exec("UPDATE account_login_states SET last_failed_at = ? WHERE user_id = ?", time.Now().Add(-time.Hour), user.ID); err != nil {
+		t.Fatalf("failed to rewind last failed login: %v", err)
+	}
+}
+
+// recordingMailer keeps the messages it is asked to send, failing instead
+// while err is set
+type recordingMailer struct {
+	mu       sync.Mutex
+	err      error
+	messages []mailer.Message
+}
+
+func (m *recordingMailer) Send(msg mailer.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// sent returns the messages sent to the given address
+func (m *recordingMailer) sent(to string) []mailer.Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var messages []mailer.Message
+	for _, msg := range m.messages {
+		if msg.To == to {
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// failWith makes every further Send fail
+func (m *recordingMailer) failWith(format string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = fmt.Errorf(format, args...)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+// lastSeenInterval is how stale the last-seen time of a session may get
+// before a request refreshes it, so that most requests do not write
+const lastSeenInterval = time.Minute
+
+// SessionService issues short-lived access tokens and rotating refresh tokens
+// for user sessions
+type SessionService struct {
+	sessionRepo *repository.SessionRepository
+	userRepo    *repository.UserRepository
+	keys        *utils.KeySet
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+}
+
+// NewSessionService creates a new session service
+func NewSessionService(sessionRepo *repository.SessionRepository, userRepo *repository.UserRepository, keys *utils.KeySet, accessTTL, refreshTTL time.Duration) *SessionService {
+	return &SessionService{
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		keys:        keys,
+		accessTTL:   accessTTL,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// StartSession opens a new session for a user who just logged in or signed up
+// from the given client. Suspended users get none.
+func (s *SessionService) StartSession(user *model.User, client model.ClientInfo) (*model.TokenPair, error) {
+	if user.SuspendedAt != nil {
+		return nil, fmt.Errorf("account suspended")
+	}
+
+	refreshToken, refreshHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &model.Session{UserID: user.ID, UserAgent: client.UserAgent, IPAddress: client.IPAddress}
+	token := &model.RefreshToken{TokenHash: refreshHash, ExpiresAt: time.Now().Add(s.refreshTTL)}
+	if err := s.sessionRepo.Create(session, token); err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, model.PermissionsFor(user.Role), session.ID, time.Now().Add(s.accessTTL), s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{AccessToken: accessToken, RefreshToken: refreshToken, SessionID: session.ID}, nil
+}
+
+// Refresh exchanges a refresh token for a new access token and refresh token
+// of the same session
+func (s *SessionService) Refresh(refreshToken string) (*model.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, fmt.Errorf("refresh token is required")
+	}
+
+	nextToken, nextHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	next := &model.RefreshToken{TokenHash: nextHash, ExpiresAt: time.Now().Add(s.refreshTTL)}
+	session, err := s.sessionRepo.Rotate(utils.HashOpaqueToken(refreshToken), next)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("user not found")
+	}
+	if user.SuspendedAt != nil {
+		return nil, fmt.Errorf("account suspended")
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.Role, model.PermissionsFor(user.Role), session.ID, time.Now().Add(s.accessTTL), s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenPair{AccessToken: accessToken, RefreshToken: nextToken, SessionID: session.ID}, nil
+}
+
+// ReissueAccessToken mints an access token reflecting the current user data
+// for an existing one, keeping its session and expiry so it cannot be used to
+// extend the session
+func (s *SessionService) ReissueAccessToken(user *model.User, claims *utils.Claims) (string, error) {
+	return utils.GenerateToken(user.ID, user.Email, user.Role, model.PermissionsFor(user.Role), claims.SessionID, claims.ExpiresAt.Time, s.keys)
+}
+
+// RevokeSession ends a session, logging its client out
+func (s *SessionService) RevokeSession(sessionID int) error {
+	return s.sessionRepo.Revoke(sessionID)
+}
+
+// ListSessions returns the active sessions of a user, flagging the one the
+// request was made from
+func (s *SessionService) ListSessions(userID, currentSessionID int) (*model.SessionsResponse, error) {
+	sessions, err := s.sessionRepo.ListActiveByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return &model.SessionsResponse{Sessions: sessions}, nil
+}
+
+// RevokeUserSession ends one of the sessions of a user, signing that device out
+func (s *SessionService) RevokeUserSession(userID, sessionID int) error {
+	return s.sessionRepo.RevokeForUser(sessionID, userID)
+}
+
+// TouchSession reports whether the session behind an access token may still
+// be used and records that it was seen. The last-seen time is only written
+// when it is older than lastSeenInterval. Suspending a user revokes their
+// sessions, which are then reported with an "account suspended" error.
+func (s *SessionService) TouchSession(sessionID int) (bool, error) {
+	if sessionID <= 0 {
+		return false, nil
+	}
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return false, nil
+		}
+		return false, err
+	}
+	if session.RevokedAt != nil {
+		if user, err := s.userRepo.GetByID(session.UserID); err == nil && user.SuspendedAt != nil {
+			return false, fmt.Errorf("account suspended")
+		}
+		return false, nil
+	}
+
+	now := time.Now()
+	if session.LastSeenAt == nil || now.Sub(*session.LastSeenAt) >= lastSeenInterval {
+		// A failed update only leaves the last-seen time stale
+		if err := s.sessionRepo.Touch(sessionID, now); err != nil {
+			log.Printf("session: %v", err)
+		}
+	}
+
+	return true, nil
+}
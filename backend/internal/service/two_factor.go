@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps
+	totpIssuer = "RealWorld"
+	// recoveryCodeCount is how many recovery codes a user gets at a time
+	recoveryCodeCount = 10
+	// challengeTTL is how long a user has to enter their code after their password
+	challengeTTL = 5 * time.Minute
+	// maxChallengeAttempts is how many wrong codes a login challenge allows
+	maxChallengeAttempts = 5
+	// maxOpenChallenges is how many login challenges a user can have open at
+	// once; starting another uses up the oldest
+	maxOpenChallenges = 3
+)
+
+// TwoFactorService handles TOTP enrollment, recovery codes and the second
+// step of logging in
+type TwoFactorService struct {
+	twoFactorRepo *repository.TwoFactorRepository
+	userRepo      *repository.UserRepository
+	userService   *UserService
+}
+
+// NewTwoFactorService creates a new two-factor service. Wrong login codes
+// count against the login throttle of userService.
+func NewTwoFactorService(twoFactorRepo *repository.TwoFactorRepository, userRepo *repository.UserRepository, userService *UserService) *TwoFactorService {
+	return &TwoFactorService{
+		twoFactorRepo: twoFactorRepo,
+		userRepo:      userRepo,
+		userService:   userService,
+	}
+}
+
+// BeginEnrollment creates a new TOTP secret for a user. It takes effect once
+// confirmed with a code from the authenticator app.
+func (s *TwoFactorService) BeginEnrollment(userID int) (*model.TwoFactorSetupResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.SaveSecret(userID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.TOTPURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication for a user who entered
+// a valid code for their new secret and returns their recovery codes
+func (s *TwoFactorService) ConfirmEnrollment(userID int, code string) ([]string, error) {
+	credential, err := s.twoFactorRepo.GetTOTP(userID)
+	if err != nil {
+		return nil, err
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, fmt.Errorf("two-factor authentication already enabled")
+	}
+
+	step, ok := utils.ValidateTOTP(credential.Secret, code, time.Now())
+	if !ok {
+		return nil, fmt.Errorf("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.Enable(userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off for a user who proves they
+// still have a second factor
+func (s *TwoFactorService) Disable(userID int, code string) error {
+	if err := s.verifyCode(userID, code); err != nil {
+		return err
+	}
+
+	return s.twoFactorRepo.Disable(userID)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user who proves
+// they still have a second factor
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID int, code string) ([]string, error) {
+	if err := s.verifyCode(userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// IsEnabled reports whether a user must enter a code to log in
+func (s *TwoFactorService) IsEnabled(userID int) (bool, error) {
+	return s.twoFactorRepo.IsEnabled(userID)
+}
+
+// StartChallenge issues the token a user who entered their password exchanges,
+// together with a code, for a session. Only the maxOpenChallenges newest
+// challenges of a user stay open, so logging in again and again does not pile
+// up challenges to guess codes for.
+func (s *TwoFactorService) StartChallenge(userID int) (string, error) {
+	token, hash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	challenge := &model.TwoFactorChallenge{UserID: userID, TokenHash: hash, ExpiresAt: time.Now().Add(challengeTTL)}
+	if err := s.twoFactorRepo.CreateChallenge(challenge); err != nil {
+		return "", err
+	}
+	if err := s.twoFactorRepo.RetireChallenges(userID, maxOpenChallenges); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CompleteChallenge checks the code entered from ipAddress for a login
+// challenge and returns the user logging in. Too many wrong codes use the
+// challenge up. Wrong codes also count as failed logins of the account and
+// the address, so they are throttled like wrong passwords, and a throttled
+// login is answered with a LoginThrottledError without checking the code.
+func (s *TwoFactorService) CompleteChallenge(token, code, ipAddress string) (*model.User, error) {
+	if token == "" {
+		return nil, fmt.Errorf("challenge token is required")
+	}
+
+	challenge, err := s.twoFactorRepo.GetChallenge(utils.HashOpaqueToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := s.userService.checkLoginThrottle(user, ipAddress, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.verifyCode(challenge.UserID, code); err != nil {
+		if err.Error() == "invalid two-factor code" {
+			if recordErr := s.twoFactorRepo.RecordFailedAttempt(challenge.ID, maxChallengeAttempts); recordErr != nil {
+				log.Printf("Failed to record login attempt for challenge %d: %v", challenge.ID, recordErr)
+			}
+			s.userService.recordLoginFailure(user, user.Email, ipAddress, now)
+		}
+		return nil, err
+	}
+
+	if err := s.twoFactorRepo.ConsumeChallenge(challenge.ID); err != nil {
+		return nil, err
+	}
+
+	s.userService.RecordLoginSuccess(user)
+	return user, nil
+}
+
+// verifyCode checks a TOTP or recovery code of a user with two-factor
+// authentication enabled, using it up
+func (s *TwoFactorService) verifyCode(userID int, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return fmt.Errorf("two-factor code is required")
+	}
+
+	credential, err := s.twoFactorRepo.GetTOTP(userID)
+	if err != nil || credential.ConfirmedAt == nil {
+		if err != nil && err.Error() != "two-factor authentication not set up" {
+			return err
+		}
+		return fmt.Errorf("two-factor authentication not enabled")
+	}
+
+	if step, ok := utils.ValidateTOTP(credential.Secret, code, time.Now()); ok {
+		return s.twoFactorRepo.UseStep(userID, step)
+	}
+
+	return s.twoFactorRepo.UseRecoveryCode(userID, utils.HashOpaqueToken(utils.NormalizeRecoveryCode(code)))
+}
+
+// generateRecoveryCodes returns new recovery codes and the hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashOpaqueToken(utils.NormalizeRecoveryCode(code))
+	}
+
+	return codes, hashes, nil
+}
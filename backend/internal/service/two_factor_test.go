@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRepeatedLoginsWithWrongCodesLockTheAccount(t *testing.T) {
+	s := newTestServices(t)
+	user := s.createUser(t, "alice")
+	s.enableTwoFactor(t, user)
+	const ip = "203.0.113.7"
+
+	// Someone who knows the password keeps logging in for fresh challenges,
+	// waiting out every backoff, until the account locks
+	var throttled *LoginThrottledError
+	locked := false
+	for i := 0; i < 2*accountLockoutAfter && !locked; i++ {
+		if _, err := s.users.AuthenticateUser(user.Email, "password123", ip); err != nil {
+			if !errors.As(err, &throttled) {
+				t.Fatalf("AuthenticateUser() error = %v", err)
+			}
+			state, err := s.attempts.GetAccountState(user.ID)
+			if err != nil {
+				t.Fatalf("GetAccountState() error = %v", err)
+			}
+			if locked = state.LockedUntil != nil; !locked {
+				s.waitOutBackoff(t, user)
+			}
+			continue
+		}
+
+		token, err := s.twoFactor.StartChallenge(user.ID)
+		if err != nil {
+			t.Fatalf("StartChallenge() error = %v", err)
+		}
+		if _, err := s.twoFactor.CompleteChallenge(token, "wrong-code", ip); err == nil || err.Error() != "invalid two-factor code" {
+			t.Fatalf("CompleteChallenge(wrong code) error = %v, want invalid two-factor code", err)
+		}
+	}
+	if !locked {
+		t.Fatalf("account not locked after %d logins with wrong codes", 2*accountLockoutAfter)
+	}
+
+	// The lockout outlasts any backoff and the owner is told about it
+	if _, err := s.users.AuthenticateUser(user.Email, "password123", ip); !errors.As(err, &throttled) || throttled.RetryAfter < accountLockoutDuration-time.Minute {
+		t.Errorf("AuthenticateUser() while locked error = %v, want the lockout", err)
+	}
+	var unlockMails int
+	for _, msg := range s.mail.sent(user.Email) {
+		if strings.Contains(msg.Subject, "locked") {
+			unlockMails++
+		}
+	}
+	if unlockMails != 1 {
+		t.Errorf("sent %d unlock emails, want 1", unlockMails)
+	}
+}
+
+func TestStartChallengeKeepsFewChallengesOpen(t *testing.T) {
+	s := newTestServices(t)
+	user := s.createUser(t, "alice")
+	recoveryCodes := s.enableTwoFactor(t, user)
+
+	var tokens []string
+	for i := 0; i <= maxOpenChallenges; i++ {
+		token, err := s.twoFactor.StartChallenge(user.ID)
+		if err != nil {
+			t.Fatalf("StartChallenge() error = %v", err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	if _, err := s.twoFactor.CompleteChallenge(tokens[0], recoveryCodes[0], "203.0.113.7"); err == nil || err.Error() != "invalid challenge token" {
+		t.Errorf("CompleteChallenge(oldest) error = %v, want invalid challenge token", err)
+	}
+	if got, err := s.twoFactor.CompleteChallenge(tokens[1], recoveryCodes[0], "203.0.113.7"); err != nil || got.ID != user.ID {
+		t.Errorf("CompleteChallenge(open) = %+v, %v; want alice", got, err)
+	}
+}
@@ -2,23 +2,46 @@ package service
 
 import (
 	"fmt"
+	"log"
 	"net/mail"
+	"net/url"
+	"time"
 
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/mailer"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
 	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/utils"
-	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// passwordResetTTL is how long a mailed password reset link can be used
+	passwordResetTTL = time.Hour
+	// emailVerificationTTL is how long a mailed verification link can be used
+	emailVerificationTTL = 48 * time.Hour
 )
 
 // UserService handles user business logic
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo              *repository.UserRepository
+	passwordResetRepo     *repository.PasswordResetRepository
+	emailVerificationRepo *repository.EmailVerificationRepository
+	loginAttemptRepo      *repository.LoginAttemptRepository
+	hasher                *utils.PasswordHasher
+	mailer                mailer.Mailer
+	appURL                string
 }
 
-// NewUserService creates a new user service
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+// NewUserService creates a new user service. Links in account emails point
+// to the frontend at appURL.
+func NewUserService(userRepo *repository.UserRepository, passwordResetRepo *repository.PasswordResetRepository, emailVerificationRepo *repository.EmailVerificationRepository, loginAttemptRepo *repository.LoginAttemptRepository, hasher *utils.PasswordHasher, mailer mailer.Mailer, appURL string) *UserService {
 	return &UserService{
-		userRepo: userRepo,
+		userRepo:              userRepo,
+		passwordResetRepo:     passwordResetRepo,
+		emailVerificationRepo: emailVerificationRepo,
+		loginAttemptRepo:      loginAttemptRepo,
+		hasher:                hasher,
+		mailer:                mailer,
+		appURL:                appURL,
 	}
 }
 
@@ -48,7 +71,7 @@ func (s *UserService) CreateUser(req model.CreateUserRequest) (*model.User, erro
 	}
 
 	// Hash password
-	hashedPassword, err := utils.HashPassword(req.User.Password)
+	hashedPassword, err := s.hasher.Hash(req.User.Password)
 	if err != nil {
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
@@ -67,6 +90,12 @@ func (s *UserService) CreateUser(req model.CreateUserRequest) (*model.User, erro
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// The account works without verification, so a mail failure must not fail
+	// the registration; the user can ask for another email
+	if err := s.sendVerificationEmail(user, user.Email); err != nil {
+		log.Printf("user: %v", err)
+	}
+
 	return user, nil
 }
 
@@ -85,22 +114,65 @@ func (s *UserService) GetUserByUsername(username string) (*model.User, error) {
 	return s.userRepo.GetByUsername(username)
 }
 
-// AuthenticateUser authenticates a user with email and password
-func (s *UserService) AuthenticateUser(email, password string) (*model.User, error) {
+// AuthenticateUser authenticates a user with email and password from the
+// given IP address. Repeated failures for the account or from the address
+// back off exponentially and end in a temporary lockout, reported as a
+// LoginThrottledError without checking the password. The failures are only
+// forgotten with RecordLoginSuccess once the login is complete.
+func (s *UserService) AuthenticateUser(email, password, ipAddress string) (*model.User, error) {
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(email)
 	if err != nil {
-		return nil, fmt.Errorf("invalid email or password")
+		if err.Error() != "user not found" {
+			return nil, err
+		}
+		user = nil
+	}
+
+	now := time.Now()
+	if err := s.checkLoginThrottle(user, ipAddress, now); err != nil {
+		return nil, err
 	}
 
 	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+	var valid, outdated bool
+	if user != nil {
+		valid, outdated = s.hasher.Verify(password, user.PasswordHash)
+	}
+	if !valid {
+		s.recordLoginFailure(user, email, ipAddress, now)
 		return nil, fmt.Errorf("invalid email or password")
 	}
 
+	// Only told once the password checks out, so that suspensions are not
+	// revealed to whoever guesses an email
+	if user.SuspendedAt != nil {
+		return nil, fmt.Errorf("account suspended")
+	}
+
+	if outdated {
+		s.upgradePasswordHash(user, password)
+	}
 	return user, nil
 }
 
+// upgradePasswordHash rehashes the password of a user whose hash was made with
+// an outdated algorithm or parameters. Failures are logged; the old hash keeps
+// working until the next login.
+func (s *UserService) upgradePasswordHash(user *model.User, password string) {
+	hash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Printf("Failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+
+	if err := s.userRepo.UpdatePasswordHash(user.ID, user.PasswordHash, hash); err != nil {
+		log.Printf("Failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+	user.PasswordHash = hash
+}
+
 // UpdateUser updates user information
 func (s *UserService) UpdateUser(userID int, req model.UpdateUserRequest) (*model.User, error) {
 	// Get existing user
@@ -109,7 +181,9 @@ func (s *UserService) UpdateUser(userID int, req model.UpdateUserRequest) (*mode
 		return nil, fmt.Errorf("user not found")
 	}
 
-	// Update fields if provided
+	// Update fields if provided. A new email only replaces the current one
+	// once it is verified.
+	emailChanged := false
 	if req.User.Email != nil {
 		if err := s.validateEmail(*req.User.Email); err != nil {
 			return nil, err
@@ -123,8 +197,12 @@ func (s *UserService) UpdateUser(userID int, req model.UpdateUserRequest) (*mode
 			if emailExists {
 				return nil, fmt.Errorf("email already exists")
 			}
+			emailChanged = *req.User.Email != user.PendingEmail
+			user.PendingEmail = *req.User.Email
+		} else {
+			// Going back to the current email drops a pending change
+			user.PendingEmail = ""
 		}
-		user.Email = *req.User.Email
 	}
 
 	if req.User.Username != nil {
@@ -148,7 +226,7 @@ func (s *UserService) UpdateUser(userID int, req model.UpdateUserRequest) (*mode
 		if err := s.validatePassword(*req.User.Password); err != nil {
 			return nil, err
 		}
-		hashedPassword, err := utils.HashPassword(*req.User.Password)
+		hashedPassword, err := s.hasher.Hash(*req.User.Password)
 		if err != nil {
 			return nil, fmt.Errorf("failed to hash password: %w", err)
 		}
@@ -168,9 +246,153 @@ func (s *UserService) UpdateUser(userID int, req model.UpdateUserRequest) (*mode
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	// The update is saved, so a mail failure must not fail it; the user can
+	// ask for another email
+	if emailChanged {
+		if err := s.sendVerificationEmail(user, user.PendingEmail); err != nil {
+			log.Printf("user: %v", err)
+		}
+	}
+
 	return user, nil
 }
 
+// RequestPasswordReset mails a password reset link to the user with the given
+// email. Unknown emails are ignored so that the response does not reveal
+// which emails are registered.
+func (s *UserService) RequestPasswordReset(email string) error {
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil
+		}
+		return err
+	}
+
+	return s.sendPasswordReset(user, "Someone asked to reset the password of your RealWorld account. ",
+		"If it was not you, ignore this email and your password stays the same.\n")
+}
+
+// ForcePasswordReset makes the password of a user unusable, signs them out
+// everywhere and mails them a link to choose a new one
+func (s *UserService) ForcePasswordReset(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.ClearPassword(user.ID); err != nil {
+		return err
+	}
+
+	return s.sendPasswordReset(user, "An administrator has reset the password of your RealWorld account "+
+		"and signed you out everywhere. ", "")
+}
+
+// sendPasswordReset mails a user a password reset link, explained by intro
+// and followed by outro
+func (s *UserService) sendPasswordReset(user *model.User, intro, outro string) error {
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	reset := &model.PasswordResetToken{UserID: user.ID, TokenHash: tokenHash, ExpiresAt: time.Now().Add(passwordResetTTL)}
+	if err := s.passwordResetRepo.Create(reset); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/reset-password?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your RealWorld password",
+		Body: fmt.Sprintf("Hi %s,\n\n%s"+
+			"Open the link below within %d minutes to choose a new one:\n\n%s\n\n%s",
+			user.Username, intro, int(passwordResetTTL.Minutes()), link, outro),
+	})
+}
+
+// ResetPassword sets a new password with a mailed reset token and signs the
+// user out everywhere
+func (s *UserService) ResetPassword(token, password string) error {
+	if token == "" {
+		return fmt.Errorf("reset token is required")
+	}
+
+	if err := s.validatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	_, err = s.passwordResetRepo.Consume(utils.HashOpaqueToken(token), hashedPassword)
+	return err
+}
+
+// ResendVerificationEmail mails a new verification link for the pending email
+// of a user, or for their current one while it is unverified
+func (s *UserService) ResendVerificationEmail(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case user.PendingEmail != "":
+		return s.sendVerificationEmail(user, user.PendingEmail)
+	case !user.EmailVerified:
+		return s.sendVerificationEmail(user, user.Email)
+	default:
+		return fmt.Errorf("email already verified")
+	}
+}
+
+// VerifyEmail marks the address a verification token was mailed to as
+// verified, making a pending email the email of the user
+func (s *UserService) VerifyEmail(token string) error {
+	if token == "" {
+		return fmt.Errorf("verification token is required")
+	}
+
+	_, err := s.emailVerificationRepo.Consume(utils.HashOpaqueToken(token))
+	return err
+}
+
+// sendVerificationEmail mails a verification link for email to the user
+func (s *UserService) sendVerificationEmail(user *model.User, email string) error {
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	verification := &model.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}
+	if err := s.emailVerificationRepo.Create(verification); err != nil {
+		return err
+	}
+
+	link := s.appURL + "/verify-email?token=" + url.QueryEscape(token)
+	return s.mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Verify your RealWorld email",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"Open the link below within %d hours to confirm %s as the email of your RealWorld account:\n\n%s\n\n"+
+			"If you did not ask for this, ignore this email.\n",
+			user.Username, int(emailVerificationTTL.Hours()), email, link),
+	})
+}
+
 // validateCreateUserRequest validates the create user request
 func (s *UserService) validateCreateUserRequest(req model.CreateUserRequest) error {
 	if err := s.validateEmail(req.User.Email); err != nil {
@@ -271,5 +493,11 @@ func (s *UserService) ToUserResponse(user *model.User, token string) model.UserR
 		Username: user.Username,
 		Bio:      user.Bio,
 		Image:    user.Image,
+
+		EmailVerified: user.EmailVerified,
+		PendingEmail:  user.PendingEmail,
+		Role:          user.Role,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
 	}
 }
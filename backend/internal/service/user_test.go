@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/model"
+)
+
+func TestUpdateUserEmailChange(t *testing.T) {
+	s := newTestServices(t)
+	user := s.createUser(t, "alice")
+
+	changeEmail := func(email string) (*model.User, error) {
+		var req model.UpdateUserRequest
+		req.User.Email = &email
+		return s.users.UpdateUser(user.ID, req)
+	}
+
+	// The new email is pending until verified through the mailed link
+	updated, err := changeEmail("alice@new.example.com")
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.Email != user.Email || updated.PendingEmail != "alice@new.example.com" {
+		t.Errorf("UpdateUser() = %+v, want the new email pending", updated)
+	}
+	if sent := s.mail.sent("alice@new.example.com"); len(sent) != 1 {
+		t.Errorf("sent %d verification emails to the new address, want 1", len(sent))
+	}
+
+	// A mail failure does not fail the saved change
+	s.mail.failWith("smtp: connection refused")
+	updated, err = changeEmail("alice@other.example.com")
+	if err != nil {
+		t.Fatalf("UpdateUser() with a failing mailer error = %v, want the change saved", err)
+	}
+	stored, err := s.users.GetUserByID(user.ID)
+	if err != nil || stored.PendingEmail != "alice@other.example.com" || updated.PendingEmail != stored.PendingEmail {
+		t.Errorf("GetUserByID() = %+v, %v; want alice@other.example.com pending", stored, err)
+	}
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/hands-on-vibe-coding/realworld-vibe-coding/backend/internal/repository"
+)
+
+// Actions that can be restricted to users with a verified email
+const (
+	ActionPublish = "publish"
+	ActionComment = "comment"
+)
+
+// EmailVerificationPolicy decides which actions need a verified email
+type EmailVerificationPolicy struct {
+	userRepo *repository.UserRepository
+	required map[string]bool
+}
+
+// NewEmailVerificationPolicy creates a policy requiring a verified email for
+// the given actions
+func NewEmailVerificationPolicy(userRepo *repository.UserRepository, actions []string) *EmailVerificationPolicy {
+	required := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		required[action] = true
+	}
+
+	return &EmailVerificationPolicy{
+		userRepo: userRepo,
+		required: required,
+	}
+}
+
+// Check returns an error when action needs a verified email and the user has
+// not verified theirs
+func (p *EmailVerificationPolicy) Check(userID int, action string) error {
+	if !p.required[action] {
+		return nil
+	}
+
+	user, err := p.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if !user.EmailVerified {
+		return fmt.Errorf("email verification required")
+	}
+
+	return nil
+}
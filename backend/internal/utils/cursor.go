@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor marks a position in a list ordered newest first by creation time,
+// with the row id breaking ties
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int
+}
+
+// cursorPayload is the JSON form of a cursor inside its token
+type cursorPayload struct {
+	CreatedAt string `json:"t"`
+	ID        int    `json:"id"`
+}
+
+// EncodeCursor returns an opaque token for the cursor
+func EncodeCursor(c Cursor) string {
+	payload, _ := json.Marshal(cursorPayload{
+		CreatedAt: c.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        c.ID,
+	})
+	return base64.RawURLEncoding.EncodeToString(payload)
+}
+
+// DecodeCursor parses a token made by EncodeCursor
+func DecodeCursor(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var payload cursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.ID <= 0 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: payload.ID}, nil
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := Cursor{CreatedAt: time.Date(2024, 3, 1, 12, 30, 0, 123456789, time.FixedZone("KST", 9*3600)), ID: 42}
+
+	got, err := DecodeCursor(EncodeCursor(want))
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("DecodeCursor() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		"not base64!",
+		EncodeCursor(Cursor{CreatedAt: time.Now()}), // no id
+		"eyJ0IjoieWVzdGVyZGF5IiwiaWQiOjF9",          // {"t":"yesterday","id":1}
+	}
+
+	for _, token := range tests {
+		if _, err := DecodeCursor(token); err == nil || err.Error() != "invalid cursor" {
+			t.Errorf("DecodeCursor(%q) error = %v, want invalid cursor", token, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import "strings"
+
+// DiffOp identifies how a line changed between two texts
+type DiffOp string
+
+// Diff operations
+const (
+	DiffEqual  DiffOp = "equal"
+	DiffInsert DiffOp = "insert"
+	DiffDelete DiffOp = "delete"
+)
+
+// maxDiffCells bounds the size of the LCS table. Changed regions larger than
+// this are reported as a block delete followed by a block insert.
+const maxDiffCells = 4_000_000
+
+// LineChange is one line of a line-level diff
+type LineChange struct {
+	Op   DiffOp
+	Text string
+}
+
+// DiffLines computes a line-level diff that turns a into b, based on the
+// longest common subsequence of their lines
+func DiffLines(a, b string) []LineChange {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// Common leading and trailing lines never need the LCS table
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	changes := make([]LineChange, 0, len(x)+len(y))
+	for _, line := range x[:prefix] {
+		changes = append(changes, LineChange{Op: DiffEqual, Text: line})
+	}
+	changes = append(changes, diffMiddle(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, line := range x[len(x)-suffix:] {
+		changes = append(changes, LineChange{Op: DiffEqual, Text: line})
+	}
+
+	return changes
+}
+
+// diffMiddle diffs the changed region between the common prefix and suffix
+func diffMiddle(x, y []string) []LineChange {
+	n, m := len(x), len(y)
+	changes := make([]LineChange, 0, n+m)
+
+	if (n+1)*(m+1) > maxDiffCells {
+		for _, line := range x {
+			changes = append(changes, LineChange{Op: DiffDelete, Text: line})
+		}
+		for _, line := range y {
+			changes = append(changes, LineChange{Op: DiffInsert, Text: line})
+		}
+		return changes
+	}
+
+	// lcs[i][j] is the LCS length of x[i:] and y[j:]
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case x[i] == y[j]:
+			changes = append(changes, LineChange{Op: DiffEqual, Text: x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			changes = append(changes, LineChange{Op: DiffDelete, Text: x[i]})
+			i++
+		default:
+			changes = append(changes, LineChange{Op: DiffInsert, Text: y[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		changes = append(changes, LineChange{Op: DiffDelete, Text: x[i]})
+	}
+	for ; j < m; j++ {
+		changes = append(changes, LineChange{Op: DiffInsert, Text: y[j]})
+	}
+
+	return changes
+}
+
+// splitLines splits text into lines, treating CRLF as LF
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+}
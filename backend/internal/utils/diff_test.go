@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected []LineChange
+	}{
+		{
+			name:     "identical",
+			a:        "one\ntwo",
+			b:        "one\ntwo",
+			expected: []LineChange{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+		{
+			name:     "empty to text",
+			a:        "",
+			b:        "one",
+			expected: []LineChange{{DiffInsert, "one"}},
+		},
+		{
+			name:     "text to empty",
+			a:        "one",
+			b:        "",
+			expected: []LineChange{{DiffDelete, "one"}},
+		},
+		{
+			name: "changed middle line",
+			a:    "one\ntwo\nthree",
+			b:    "one\n2\nthree",
+			expected: []LineChange{
+				{DiffEqual, "one"}, {DiffDelete, "two"}, {DiffInsert, "2"}, {DiffEqual, "three"},
+			},
+		},
+		{
+			name: "insert and delete",
+			a:    "a\nb\nc\nd",
+			b:    "a\nc\nd\ne",
+			expected: []LineChange{
+				{DiffEqual, "a"}, {DiffDelete, "b"}, {DiffEqual, "c"}, {DiffEqual, "d"}, {DiffInsert, "e"},
+			},
+		},
+		{
+			name:     "crlf matches lf",
+			a:        "one\r\ntwo",
+			b:        "one\ntwo",
+			expected: []LineChange{{DiffEqual, "one"}, {DiffEqual, "two"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := DiffLines(tt.a, tt.b)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("DiffLines() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDiffLinesLargeChange(t *testing.T) {
+	a := strings.Repeat("old\n", 3000)
+	b := strings.Repeat("new\n", 3000)
+
+	result := DiffLines(a, b)
+
+	// Applying the diff must still reproduce both sides
+	var from, to []string
+	for _, change := range result {
+		if change.Op != DiffInsert {
+			from = append(from, change.Text)
+		}
+		if change.Op != DiffDelete {
+			to = append(to, change.Text)
+		}
+	}
+	if strings.Join(from, "\n") != a || strings.Join(to, "\n") != b {
+		t.Error("DiffLines() does not reproduce its inputs")
+	}
+}
@@ -7,21 +7,56 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Claims represents the JWT claims
+// Claims represents the JWT claims. Requests authenticated with an API
+// token instead carry the token id and its scopes, which never appear in a JWT.
 type Claims struct {
-	UserID int    `json:"user_id"`
-	Email  string `json:"email"`
+	UserID      int      `json:"user_id"`
+	Email       string   `json:"email"`
+	Role        string   `json:"role,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+	SessionID   int      `json:"sid"`
+	TokenID     int      `json:"-"`
+	Scopes      []string `json:"-"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a new JWT token for the given user
-func GenerateToken(userID int, email string, secretKey string) (string, error) {
+// HasPermission reports whether the role of the user grants permission
+func (c *Claims) HasPermission(permission string) bool {
+	for _, granted := range c.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the request may act within scope. Sessions are not
+// limited to scopes; API tokens only have the scopes they were granted.
+func (c *Claims) HasScope(scope string) bool {
+	if c.TokenID == 0 {
+		return true
+	}
+	for _, granted := range c.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateToken generates a new access token for the given user, with the
+// role and permissions they have, and session that expires at expiresAt,
+// signed with the signing key of keys
+func GenerateToken(userID int, email, role string, permissions []string, sessionID int, expiresAt time.Time, keys *KeySet) (string, error) {
 	// Create claims with user data and expiration time
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:      userID,
+		Email:       email,
+		Role:        role,
+		Permissions: permissions,
+		SessionID:   sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // Token expires in 24 hours
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "realworld-backend",
@@ -29,11 +64,12 @@ func GenerateToken(userID int, email string, secretKey string) (string, error) {
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Create token with claims, naming the key so verifiers can pick it
+	token := jwt.NewWithClaims(keys.signing.Method, claims)
+	token.Header["kid"] = keys.signing.ID
 
-	// Sign token with secret key
-	tokenString, err := token.SignedString([]byte(secretKey))
+	// Sign token with the private key
+	tokenString, err := token.SignedString(keys.signing.Private)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -41,15 +77,22 @@ func GenerateToken(userID int, email string, secretKey string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims
-func ValidateToken(tokenString string, secretKey string) (*Claims, error) {
+// ValidateToken validates a JWT token against the key named by its kid header
+// and returns the claims
+func ValidateToken(tokenString string, keys *KeySet) (*Claims, error) {
 	// Parse token with claims
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Key(kid)
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %q", kid)
+		}
+		// Validate signing method against the key so that the algorithm
+		// cannot be swapped
+		if token.Method.Alg() != key.Method.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return []byte(secretKey), nil
+		return key.Public, nil
 	})
 
 	if err != nil {
@@ -69,15 +112,3 @@ func ValidateToken(tokenString string, secretKey string) (*Claims, error) {
 
 	return claims, nil
 }
-
-// RefreshToken generates a new token for an existing valid token
-func RefreshToken(tokenString string, secretKey string) (string, error) {
-	// Validate the existing token
-	claims, err := ValidateToken(tokenString, secretKey)
-	if err != nil {
-		return "", fmt.Errorf("cannot refresh invalid token: %w", err)
-	}
-
-	// Generate a new token with the same user data
-	return GenerateToken(claims.UserID, claims.Email, secretKey)
-}
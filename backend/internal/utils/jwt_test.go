@@ -5,12 +5,21 @@ import (
 	"time"
 )
 
+func newTestKeySet(t *testing.T) *KeySet {
+	t.Helper()
+	keys, err := NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("Failed to create key set: %v", err)
+	}
+	return keys
+}
+
 func TestGenerateToken(t *testing.T) {
-	secretKey := "test-secret-key"
+	keys := newTestKeySet(t)
 	userID := 123
 	email := "test@example.com"
 
-	token, err := GenerateToken(userID, email, secretKey)
+	token, err := GenerateToken(userID, email, "user", nil, 7, time.Now().Add(time.Hour), keys)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -21,18 +30,18 @@ func TestGenerateToken(t *testing.T) {
 }
 
 func TestValidateToken(t *testing.T) {
-	secretKey := "test-secret-key"
+	keys := newTestKeySet(t)
 	userID := 123
 	email := "test@example.com"
 
 	// Generate a token
-	token, err := GenerateToken(userID, email, secretKey)
+	token, err := GenerateToken(userID, email, "user", nil, 7, time.Now().Add(time.Hour), keys)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
 
 	// Validate the token
-	claims, err := ValidateToken(token, secretKey)
+	claims, err := ValidateToken(token, keys)
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
@@ -47,72 +56,107 @@ func TestValidateToken(t *testing.T) {
 	}
 }
 
-func TestValidateTokenWithWrongSecret(t *testing.T) {
-	secretKey := "test-secret-key"
-	wrongSecret := "wrong-secret-key"
+func TestTokenCarriesRoleAndPermissions(t *testing.T) {
+	keys := newTestKeySet(t)
+
+	token, err := GenerateToken(123, "mod@example.com", "moderator", []string{"articles:moderate", "comments:moderate"}, 7, time.Now().Add(time.Hour), keys)
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims, err := ValidateToken(token, keys)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if claims.Role != "moderator" {
+		t.Errorf("Expected Role moderator, got %q", claims.Role)
+	}
+	if !claims.HasPermission("comments:moderate") {
+		t.Error("Expected comments:moderate permission")
+	}
+	if claims.HasPermission("users:manage") {
+		t.Error("Expected no users:manage permission")
+	}
+}
+
+func TestValidateTokenWithWrongKey(t *testing.T) {
+	keys := newTestKeySet(t)
+	wrongKeys := newTestKeySet(t)
 	userID := 123
 	email := "test@example.com"
 
-	// Generate a token with correct secret
-	token, err := GenerateToken(userID, email, secretKey)
+	// Generate a token with the correct key
+	token, err := GenerateToken(userID, email, "user", nil, 7, time.Now().Add(time.Hour), keys)
 	if err != nil {
 		t.Fatalf("Failed to generate token: %v", err)
 	}
 
-	// Try to validate with wrong secret
-	_, err = ValidateToken(token, wrongSecret)
+	// Try to validate with a different key of the same id
+	_, err = ValidateToken(token, wrongKeys)
 	if err == nil {
-		t.Fatal("Expected error when validating with wrong secret, got nil")
+		t.Fatal("Expected error when validating with wrong key, got nil")
 	}
 }
 
 func TestValidateInvalidToken(t *testing.T) {
-	secretKey := "test-secret-key"
+	keys := newTestKeySet(t)
 	invalidToken := "invalid.token.here"
 
-	_, err := ValidateToken(invalidToken, secretKey)
+	_, err := ValidateToken(invalidToken, keys)
 	if err == nil {
 		t.Fatal("Expected error when validating invalid token, got nil")
 	}
 }
 
-func TestRefreshToken(t *testing.T) {
-	secretKey := "test-secret-key"
-	userID := 123
-	email := "test@example.com"
+func TestValidateTokenCarriesSession(t *testing.T) {
+	keys := newTestKeySet(t)
 
-	// Generate original token
-	originalToken, err := GenerateToken(userID, email, secretKey)
+	token, err := GenerateToken(123, "test@example.com", "user", nil, 7, time.Now().Add(time.Hour), keys)
 	if err != nil {
-		t.Fatalf("Failed to generate original token: %v", err)
+		t.Fatalf("Failed to generate token: %v", err)
 	}
 
-	// Wait a moment to ensure different issued times
-	time.Sleep(time.Second * 1)
-
-	// Refresh the token
-	newToken, err := RefreshToken(originalToken, secretKey)
+	claims, err := ValidateToken(token, keys)
 	if err != nil {
-		t.Fatalf("Failed to refresh token: %v", err)
+		t.Fatalf("Expected no error, got %v", err)
 	}
+	if claims.SessionID != 7 {
+		t.Errorf("Expected SessionID 7, got %d", claims.SessionID)
+	}
+}
 
-	// Validate the new token
-	claims, err := ValidateToken(newToken, secretKey)
+func TestValidateExpiredToken(t *testing.T) {
+	keys := newTestKeySet(t)
+
+	token, err := GenerateToken(123, "test@example.com", "user", nil, 7, time.Now().Add(-time.Minute), keys)
 	if err != nil {
-		t.Fatalf("Failed to validate refreshed token: %v", err)
+		t.Fatalf("Failed to generate token: %v", err)
 	}
 
-	// Check that user data is preserved
-	if claims.UserID != userID {
-		t.Errorf("Expected UserID %d, got %d", userID, claims.UserID)
+	if _, err := ValidateToken(token, keys); err == nil {
+		t.Fatal("Expected error when validating expired token, got nil")
+	}
+}
+
+func TestGenerateOpaqueToken(t *testing.T) {
+	token, hash, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	if claims.Email != email {
-		t.Errorf("Expected Email %s, got %s", email, claims.Email)
+	if hash != HashOpaqueToken(token) {
+		t.Error("Expected hash to match HashOpaqueToken of the token")
+	}
+	if hash == token {
+		t.Error("Expected hash to differ from the token")
 	}
 
-	// Tokens should be different
-	if originalToken == newToken {
-		t.Error("Expected refreshed token to be different from original")
+	other, _, err := GenerateOpaqueToken()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if other == token {
+		t.Error("Expected tokens to be unique")
 	}
 }
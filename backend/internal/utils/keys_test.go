@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writeKey generates a key for algorithm into dir
+func writeKey(t *testing.T, dir, kid, algorithm string) {
+	t.Helper()
+	data, err := GeneratePrivateKey(algorithm)
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey(%s) error = %v", algorithm, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, kid+".pem"), data, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+}
+
+func TestLoadKeySetRotation(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "old", "RS256")
+
+	oldKeys, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	token, err := GenerateToken(123, "test@example.com", "user", nil, 7, time.Now().Add(time.Hour), oldKeys)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	// Add a new key; with two private keys the signing key must be named
+	writeKey(t, dir, "new", "EdDSA")
+	if _, err := LoadKeySet(dir, ""); err == nil {
+		t.Fatal("LoadKeySet() with two private keys and no signing key id, want error")
+	}
+
+	keys, err := LoadKeySet(dir, "new")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	if keys.SigningKeyID() != "new" {
+		t.Errorf("SigningKeyID() = %q, want new", keys.SigningKeyID())
+	}
+
+	// Tokens signed with the old key keep working after the switch
+	if claims, err := ValidateToken(token, keys); err != nil || claims.UserID != 123 {
+		t.Fatalf("ValidateToken(old token) = %+v, %v; want user 123", claims, err)
+	}
+
+	token, err = GenerateToken(123, "test@example.com", "user", nil, 7, time.Now().Add(time.Hour), keys)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	if parsed.Header["kid"] != "new" || parsed.Header["alg"] != "EdDSA" {
+		t.Errorf("token header = %v, want kid new and alg EdDSA", parsed.Header)
+	}
+	if _, err := ValidateToken(token, oldKeys); err == nil {
+		t.Error("ValidateToken() with a set missing the signing key, want error")
+	}
+}
+
+func TestLoadKeySetVerificationOnlyKey(t *testing.T) {
+	dir := t.TempDir()
+	writeKey(t, dir, "signer", "EdDSA")
+
+	// Publish only the public half of another key, as when a key is retired
+	data, err := GeneratePrivateKey("RS256")
+	if err != nil {
+		t.Fatalf("GeneratePrivateKey() error = %v", err)
+	}
+	retired, err := ParseSigningKey("retired", data)
+	if err != nil {
+		t.Fatalf("ParseSigningKey() error = %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(retired.Public)
+	if err != nil {
+		t.Fatalf("Failed to encode public key: %v", err)
+	}
+	public := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, "retired.pem"), public, 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	if _, err := LoadKeySet(dir, "retired"); err == nil {
+		t.Error("LoadKeySet() signing with a public key, want error")
+	}
+
+	keys, err := LoadKeySet(dir, "")
+	if err != nil {
+		t.Fatalf("LoadKeySet() error = %v", err)
+	}
+	if keys.SigningKeyID() != "signer" {
+		t.Errorf("SigningKeyID() = %q, want signer", keys.SigningKeyID())
+	}
+
+	// A token of the retired key still validates
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, Claims{
+		UserID:           5,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "retired"
+	signed, err := token.SignedString(retired.Private)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	if claims, err := ValidateToken(signed, keys); err != nil || claims.UserID != 5 {
+		t.Errorf("ValidateToken(retired key) = %+v, %v; want user 5", claims, err)
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("JWKS() = %+v, want two keys", jwks)
+	}
+	if k := jwks.Keys[0]; k.KeyID != "retired" || k.KeyType != "RSA" || k.Algorithm != "RS256" || k.N == "" || k.E != "AQAB" {
+		t.Errorf("JWKS().Keys[0] = %+v, want the retired RSA key", k)
+	}
+	if k := jwks.Keys[1]; k.KeyID != "signer" || k.KeyType != "OKP" || k.Curve != "Ed25519" || k.X == "" {
+		t.Errorf("JWKS().Keys[1] = %+v, want the Ed25519 signer", k)
+	}
+}
+
+func TestValidateTokenRejectsForeignKeys(t *testing.T) {
+	keys := newTestKeySet(t)
+	claims := Claims{
+		UserID:           1,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	}
+
+	// An HMAC token under a known kid must not validate
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	hmac.Header["kid"] = keys.SigningKeyID()
+	signed, err := hmac.SignedString([]byte("your-secret-key"))
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := ValidateToken(signed, keys); err == nil {
+		t.Error("ValidateToken(HS256) = nil error, want error")
+	}
+
+	// Neither must a token without a kid
+	token, err := GenerateToken(1, "test@example.com", "user", nil, 7, time.Now().Add(time.Hour), keys)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified() error = %v", err)
+	}
+	delete(parsed.Header, "kid")
+	unnamed, err := parsed.SignedString(keys.signing.Private)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	if _, err := ValidateToken(unnamed, keys); err == nil {
+		t.Error("ValidateToken(no kid) = nil error, want error")
+	}
+}
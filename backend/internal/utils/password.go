@@ -1,17 +1,157 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashes a password using bcrypt
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+// Password hashing algorithms
+const (
+	PasswordAlgorithmArgon2id = "argon2id"
+	PasswordAlgorithmBcrypt   = "bcrypt"
+)
+
+const (
+	// argon2SaltLength is the size of the random salt of an Argon2id hash
+	argon2SaltLength = 16
+	// argon2KeyLength is the size of an Argon2id hash
+	argon2KeyLength = 32
+)
+
+// PasswordParams configures how new password hashes are made
+type PasswordParams struct {
+	// Algorithm is PasswordAlgorithmArgon2id or PasswordAlgorithmBcrypt
+	Algorithm string
+	// BcryptCost is the bcrypt work factor
+	BcryptCost int
+	// Argon2Memory is the Argon2id memory cost in KiB
+	Argon2Memory uint32
+	// Argon2Iterations is the Argon2id time cost
+	Argon2Iterations uint32
+	// Argon2Parallelism is the number of Argon2id lanes
+	Argon2Parallelism uint8
+}
+
+// DefaultPasswordParams are the OWASP recommended Argon2id parameters, with
+// the bcrypt cost hashes used before Argon2id was supported
+func DefaultPasswordParams() PasswordParams {
+	return PasswordParams{
+		Algorithm:         PasswordAlgorithmArgon2id,
+		BcryptCost:        bcrypt.DefaultCost,
+		Argon2Memory:      19 * 1024,
+		Argon2Iterations:  2,
+		Argon2Parallelism: 1,
+	}
+}
+
+// PasswordHasher hashes passwords with the configured algorithm and verifies
+// them against hashes of any supported algorithm. Hashes are self-describing:
+// Argon2id hashes use the PHC string format and bcrypt hashes their modular
+// crypt format, so each carries its algorithm, version and parameters.
+type PasswordHasher struct {
+	params PasswordParams
+}
+
+// NewPasswordHasher creates a hasher making new hashes with params
+func NewPasswordHasher(params PasswordParams) (*PasswordHasher, error) {
+	switch params.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		if params.BcryptCost < bcrypt.MinCost || params.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if params.Argon2Memory < 8*uint32(params.Argon2Parallelism) || params.Argon2Iterations < 1 || params.Argon2Parallelism < 1 {
+			return nil, fmt.Errorf("invalid argon2id parameters")
+		}
+	default:
+		return nil, fmt.Errorf("unknown password hashing algorithm: %q", params.Algorithm)
+	}
+
+	return &PasswordHasher{params: params}, nil
 }
 
-// CheckPassword compares a password with its hash
-func CheckPassword(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+// Hash hashes a password with the configured algorithm
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.params.Algorithm == PasswordAlgorithmBcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.params.BcryptCost)
+		return string(hash), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Argon2Iterations, p.Argon2Memory, p.Argon2Parallelism, argon2KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Argon2Memory, p.Argon2Iterations, p.Argon2Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify reports whether password matches hash and, if so, whether hash
+// should be replaced because it was made with another algorithm or other
+// parameters than the configured ones
+func (h *PasswordHasher) Verify(password, hash string) (bool, bool) {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return h.verifyArgon2id(password, hash)
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return false, false
+	}
+	if h.params.Algorithm != PasswordAlgorithmBcrypt {
+		return true, true
+	}
+	cost, err := bcrypt.Cost([]byte(hash))
+	return true, err != nil || cost != h.params.BcryptCost
+}
+
+// verifyArgon2id checks password against a PHC formatted Argon2id hash
+func (h *PasswordHasher) verifyArgon2id(password, hash string) (bool, bool) {
+	// $argon2id$v=19$m=...,t=...,p=...$salt$key splits into 6 parts
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false
+	}
+
+	var memory, iterations uint32
+	var parallelism uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return false, false
+	}
+	if iterations < 1 || parallelism < 1 {
+		return false, false
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, iterations, memory, parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(candidate, key) != 1 {
+		return false, false
+	}
+
+	p := h.params
+	outdated := p.Algorithm != PasswordAlgorithmArgon2id ||
+		memory != p.Argon2Memory || iterations != p.Argon2Iterations || parallelism != p.Argon2Parallelism ||
+		len(salt) != argon2SaltLength || len(key) != argon2KeyLength
+	return true, outdated
 }
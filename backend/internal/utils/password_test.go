@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastPasswordParams keeps hashing cheap in tests
+func fastPasswordParams(algorithm string) PasswordParams {
+	return PasswordParams{
+		Algorithm:         algorithm,
+		BcryptCost:        bcrypt.MinCost,
+		Argon2Memory:      64,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	}
+}
+
+func newTestHasher(t *testing.T, params PasswordParams) *PasswordHasher {
+	t.Helper()
+	hasher, err := NewPasswordHasher(params)
+	if err != nil {
+		t.Fatalf("NewPasswordHasher() error = %v", err)
+	}
+	return hasher
+}
+
+func TestPasswordHasherArgon2id(t *testing.T) {
+	hasher := newTestHasher(t, fastPasswordParams(PasswordAlgorithmArgon2id))
+
+	hash, err := hasher.Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Errorf("Hash() = %s, want a PHC argon2id string", hash)
+	}
+
+	if valid, outdated := hasher.Verify("password123", hash); !valid || outdated {
+		t.Errorf("Verify() = %v, %v; want valid and current", valid, outdated)
+	}
+	if valid, _ := hasher.Verify("wrong", hash); valid {
+		t.Error("Verify(wrong password) = true, want false")
+	}
+	if valid, _ := hasher.Verify("password123", "$argon2id$v=19$garbage"); valid {
+		t.Error("Verify(malformed hash) = true, want false")
+	}
+
+	// Raising a cost makes existing hashes outdated but still valid
+	stronger := fastPasswordParams(PasswordAlgorithmArgon2id)
+	stronger.Argon2Iterations = 2
+	if valid, outdated := newTestHasher(t, stronger).Verify("password123", hash); !valid || !outdated {
+		t.Errorf("Verify() with new parameters = %v, %v; want valid and outdated", valid, outdated)
+	}
+}
+
+func TestPasswordHasherUpgradesBcrypt(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	argon := newTestHasher(t, fastPasswordParams(PasswordAlgorithmArgon2id))
+	if valid, outdated := argon.Verify("password123", string(legacy)); !valid || !outdated {
+		t.Errorf("Verify(bcrypt) = %v, %v; want valid and outdated", valid, outdated)
+	}
+	if valid, _ := argon.Verify("wrong", string(legacy)); valid {
+		t.Error("Verify(bcrypt, wrong password) = true, want false")
+	}
+
+	bcryptHasher := newTestHasher(t, fastPasswordParams(PasswordAlgorithmBcrypt))
+	if valid, outdated := bcryptHasher.Verify("password123", string(legacy)); !valid || outdated {
+		t.Errorf("Verify(bcrypt) with bcrypt configured = %v, %v; want valid and current", valid, outdated)
+	}
+
+	// Going back to bcrypt outdates argon2id hashes
+	hash, err := argon.Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+	if valid, outdated := bcryptHasher.Verify("password123", hash); !valid || !outdated {
+		t.Errorf("Verify(argon2id) with bcrypt configured = %v, %v; want valid and outdated", valid, outdated)
+	}
+}
+
+func TestNewPasswordHasherRejectsInvalidParams(t *testing.T) {
+	unknown := fastPasswordParams("md5")
+	cheap := fastPasswordParams(PasswordAlgorithmBcrypt)
+	cheap.BcryptCost = 2
+	noLanes := fastPasswordParams(PasswordAlgorithmArgon2id)
+	noLanes.Argon2Parallelism = 0
+
+	for _, params := range []PasswordParams{unknown, cheap, noLanes} {
+		if _, err := NewPasswordHasher(params); err == nil {
+			t.Errorf("NewPasswordHasher(%+v) = nil error, want error", params)
+		}
+	}
+}
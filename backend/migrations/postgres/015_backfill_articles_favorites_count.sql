@@ -0,0 +1,11 @@
+-- Backfill favorites_count and stop counter updates from touching updated_at
+-- Migration: 015_backfill_articles_favorites_count.sql
+
+UPDATE articles
+SET favorites_count = (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id);
+
+-- Only content changes bump updated_at; favoriting an article does not
+DROP TRIGGER IF EXISTS update_articles_updated_at ON articles;
+CREATE TRIGGER update_articles_updated_at
+    BEFORE UPDATE OF slug, title, description, body, status, publish_at ON articles
+    FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,8 @@
+-- Add client details and last activity to sessions table
+-- Migration: 017_add_client_info_to_sessions.sql
+
+-- Shown to users in their list of active sessions so they can tell their
+-- devices apart. last_seen_at is refreshed at most once a minute.
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS ip_address TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
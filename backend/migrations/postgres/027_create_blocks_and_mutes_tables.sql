@@ -0,0 +1,30 @@
+-- Create blocks and mutes tables (user relationships)
+-- Migration: 027_create_blocks_and_mutes_tables.sql
+
+-- Blocked users cannot follow the blocker, nor comment on or favorite their
+-- articles
+CREATE TABLE IF NOT EXISTS user_blocks (
+    id SERIAL PRIMARY KEY,
+    blocker_id INTEGER NOT NULL,
+    blocked_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (blocker_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (blocked_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(blocker_id, blocked_id),
+    CHECK(blocker_id != blocked_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_blocks_blocked_id ON user_blocks(blocked_id);
+
+-- The articles and comments of muted users are left out of the lists of the
+-- muter
+CREATE TABLE IF NOT EXISTS user_mutes (
+    id SERIAL PRIMARY KEY,
+    muter_id INTEGER NOT NULL,
+    muted_id INTEGER NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (muter_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (muted_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE(muter_id, muted_id),
+    CHECK(muter_id != muted_id)
+);
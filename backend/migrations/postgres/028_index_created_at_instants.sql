@@ -0,0 +1,8 @@
+-- Index article and comment creation times with their ids
+-- Migration: 028_index_created_at_instants.sql
+
+-- Newest-first lists and their cursors order by created_at, then id. These
+-- indexes match that ordering, letting a page be read straight from the index
+-- from the cursor on.
+CREATE INDEX IF NOT EXISTS idx_articles_status_created_instant ON articles(status, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_article_created_instant ON comments(article_id, created_at DESC, id DESC);
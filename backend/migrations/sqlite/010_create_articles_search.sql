@@ -0,0 +1,40 @@
+-- Create full-text search index for articles
+-- Migration: 010_create_articles_search.sql
+
+-- FTS5 needs the sqlite_fts5 build tag of go-sqlite3. The index reads its
+-- content from the articles table; the rowid of each row is the article id.
+CREATE VIRTUAL TABLE IF NOT EXISTS articles_fts USING fts5(
+    title,
+    description,
+    body,
+    content='articles',
+    content_rowid='id',
+    tokenize='unicode61'
+);
+
+-- Index existing articles
+INSERT INTO articles_fts (articles_fts) VALUES ('rebuild');
+
+-- Keep the index in sync with the articles table
+CREATE TRIGGER IF NOT EXISTS articles_fts_after_insert
+    AFTER INSERT ON articles
+BEGIN
+    INSERT INTO articles_fts (rowid, title, description, body)
+    VALUES (NEW.id, NEW.title, NEW.description, NEW.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS articles_fts_after_update
+    AFTER UPDATE OF title, description, body ON articles
+BEGIN
+    INSERT INTO articles_fts (articles_fts, rowid, title, description, body)
+    VALUES ('delete', OLD.id, OLD.title, OLD.description, OLD.body);
+    INSERT INTO articles_fts (rowid, title, description, body)
+    VALUES (NEW.id, NEW.title, NEW.description, NEW.body);
+END;
+
+CREATE TRIGGER IF NOT EXISTS articles_fts_after_delete
+    AFTER DELETE ON articles
+BEGIN
+    INSERT INTO articles_fts (articles_fts, rowid, title, description, body)
+    VALUES ('delete', OLD.id, OLD.title, OLD.description, OLD.body);
+END;
@@ -0,0 +1,10 @@
+-- Add publication status to articles table
+-- Migration: 011_add_status_to_articles.sql
+
+-- Existing articles were published on creation
+ALTER TABLE articles ADD COLUMN status TEXT NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'published', 'unlisted'));
+
+-- Listings filter on status and drafts are looked up per author
+CREATE INDEX IF NOT EXISTS idx_articles_status_created ON articles(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_articles_author_status ON articles(author_id, status);
@@ -0,0 +1,21 @@
+-- Create article_revisions table
+-- Migration: 013_create_article_revisions_table.sql
+
+-- Immutable snapshots of an article's content, numbered per article from 1
+CREATE TABLE IF NOT EXISTS article_revisions (
+    id INTEGER PRIMARY KEY,
+    article_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL,
+    body TEXT NOT NULL,
+    editor_id INTEGER,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL,
+    UNIQUE(article_id, revision)
+);
+
+-- Existing articles start their history with their current content
+INSERT INTO article_revisions (article_id, revision, title, description, body, editor_id, created_at)
+SELECT id, 1, title, description, body, author_id, updated_at FROM articles;
@@ -0,0 +1,13 @@
+-- Create article_slug_history table
+-- Migration: 014_create_article_slug_history_table.sql
+
+-- Slugs an article used before a title change, kept so old links keep working
+CREATE TABLE IF NOT EXISTS article_slug_history (
+    id INTEGER PRIMARY KEY,
+    slug TEXT NOT NULL UNIQUE,
+    article_id INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_slug_history_article_id ON article_slug_history(article_id);
@@ -0,0 +1,16 @@
+-- Create password_reset_tokens table
+-- Migration: 018_create_password_reset_tokens_table.sql
+
+-- Single-use tokens mailed to users who forgot their password, stored as
+-- SHA-256 hashes. Requesting a new token uses up the older ones.
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
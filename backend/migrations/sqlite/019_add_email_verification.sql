@@ -0,0 +1,22 @@
+-- Add email verification to users
+-- Migration: 019_add_email_verification.sql
+
+-- A changed email is kept in pending_email until the new address is verified;
+-- the user keeps logging in with the old one until then
+ALTER TABLE users ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN pending_email TEXT NOT NULL DEFAULT '';
+
+-- Single-use tokens mailed to verify an address, stored as SHA-256 hashes.
+-- Sending a new token uses up the older ones of the user.
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    email TEXT NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens(user_id);
@@ -0,0 +1,52 @@
+-- Create login throttling tables
+-- Migration: 022_create_login_throttling_tables.sql
+
+-- Failed password logins of an account since its last successful login or
+-- lockout. Logins back off once failures pile up and stop while locked_until
+-- is in the future.
+CREATE TABLE IF NOT EXISTS account_login_states (
+    user_id INTEGER PRIMARY KEY,
+    failed_logins INTEGER NOT NULL DEFAULT 0,
+    last_failed_at DATETIME,
+    locked_until DATETIME,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Recent failed logins by client IP, including unknown emails. Rows older
+-- than the throttling window are pruned as new ones are added.
+CREATE TABLE IF NOT EXISTS login_failures (
+    id INTEGER PRIMARY KEY,
+    ip_address TEXT NOT NULL,
+    email TEXT NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_failures_ip_address_created_at ON login_failures(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_failures_created_at ON login_failures(created_at);
+
+-- Audit trail of account and IP lockouts
+CREATE TABLE IF NOT EXISTS lockout_events (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER,
+    ip_address TEXT NOT NULL DEFAULT '',
+    scope TEXT NOT NULL,
+    locked_until DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_lockout_events_user_id ON lockout_events(user_id);
+
+-- Single-use tokens mailed to locked out users to unlock their account early,
+-- stored as SHA-256 hashes
+CREATE TABLE IF NOT EXISTS account_unlock_tokens (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_unlock_tokens_user_id ON account_unlock_tokens(user_id);
@@ -0,0 +1,40 @@
+-- Create OpenID Connect login tables
+-- Migration: 023_create_oidc_tables.sql
+
+-- External identities linked to users. The subject is only unique per issuer.
+CREATE TABLE IF NOT EXISTS user_identities (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    issuer TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (issuer, subject),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
+
+-- Pending authorization requests, keyed by the SHA-256 hash of their state
+-- and holding the PKCE verifier and nonce until the issuer redirects back
+CREATE TABLE IF NOT EXISTS oidc_states (
+    id INTEGER PRIMARY KEY,
+    state_hash TEXT NOT NULL UNIQUE,
+    code_verifier TEXT NOT NULL,
+    nonce TEXT NOT NULL,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Single-use codes handed to the frontend after a successful callback, to be
+-- exchanged for tokens. Stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS oidc_login_codes (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    code_hash TEXT NOT NULL UNIQUE,
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+-- Let admins suspend users
+-- Migration: 025_add_user_suspension.sql
+
+-- Suspended users cannot log in and their content is hidden from listings
+ALTER TABLE users ADD COLUMN suspended_at DATETIME;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT NOT NULL DEFAULT '';
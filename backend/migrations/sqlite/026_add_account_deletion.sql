@@ -0,0 +1,7 @@
+-- Let users delete their account after a grace period
+-- Migration: 026_add_account_deletion.sql
+
+-- When the account is due to be deleted, and whether its articles and
+-- comments are deleted with it or kept as those of "deleted user"
+ALTER TABLE users ADD COLUMN deletion_scheduled_at DATETIME;
+ALTER TABLE users ADD COLUMN deletion_mode TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+-- Index article and comment creation instants
+-- Migration: 028_index_created_at_instants.sql
+
+-- Timestamps are stored as text with the offset of whoever wrote them, so
+-- newest-first lists and their cursors order by julianday(created_at), then
+-- id. These indexes match that ordering, letting a page be read straight from
+-- the index from the cursor on instead of sorting every matching row.
+CREATE INDEX IF NOT EXISTS idx_articles_status_created_instant ON articles(status, julianday(created_at) DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_comments_article_created_instant ON comments(article_id, julianday(created_at) DESC, id DESC);
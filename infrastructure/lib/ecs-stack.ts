@@ -102,6 +102,36 @@ export class EcsStack extends cdk.Stack {
       family: `realworld-backend-${environment}`,
     })
 
+    // JWT signing key: the PEM written by `go run ./cmd/jwt-keygen`, stored
+    // in Secrets Manager under realworld/${environment}/jwt-signing-key
+    const jwtSigningKeyId = this.node.tryGetContext('jwtSigningKeyId') || 'realworld'
+    const jwtSigningKey = secretsmanager.Secret.fromSecretNameV2(this, 'JwtSigningKey', `realworld/${environment}/jwt-signing-key`)
+
+    // Fargate cannot mount secrets as files, so an init container writes the
+    // key into a task volume that the backend reads as JWT_KEYS_DIR
+    taskDefinition.addVolume({ name: 'jwt-keys' })
+    const jwtKeysContainer = taskDefinition.addContainer('jwt-keys', {
+      image: ecs.ContainerImage.fromRegistry('public.ecr.aws/docker/library/busybox:stable'),
+      essential: false,
+      environment: {
+        JWT_SIGNING_KEY_ID: jwtSigningKeyId,
+      },
+      secrets: {
+        JWT_SIGNING_KEY: ecs.Secret.fromSecretsManager(jwtSigningKey),
+      },
+      // The backend image runs as appuser (uid 1001)
+      command: ['sh', '-c', 'umask 077 && printf "%s\\n" "$JWT_SIGNING_KEY" > "/keys/$JWT_SIGNING_KEY_ID.pem" && chown -R 1001:1001 /keys'],
+      logging: ecs.LogDrivers.awsLogs({
+        streamPrefix: 'jwt-keys',
+        logGroup,
+      }),
+    })
+    jwtKeysContainer.addMountPoints({
+      containerPath: '/keys',
+      sourceVolume: 'jwt-keys',
+      readOnly: false,
+    })
+
     // Environment variables for the backend container
     const environment_vars = {
       NODE_ENV: environment === 'production' ? 'production' : 'development',
@@ -110,7 +140,9 @@ export class EcsStack extends cdk.Stack {
       DATABASE_PORT: database.instanceEndpoint.port.toString(),
       DATABASE_NAME: 'realworld',
       DATABASE_USER: 'postgres',
-      JWT_SECRET: 'temporary-jwt-secret-for-dev', // TODO: Move to proper secret management for production
+      JWT_KEYS_DIR: '/keys',
+      JWT_SIGNING_KEY_ID: jwtSigningKeyId,
+      TRUSTED_PROXIES: vpc.vpcCidrBlock, // The load balancer's X-Forwarded-For
       ENVIRONMENT: environment,
     }
 
@@ -142,6 +174,17 @@ export class EcsStack extends cdk.Stack {
       protocol: ecs.Protocol.TCP,
     })
 
+    // Read the signing key once the init container has written it
+    backendContainer.addMountPoints({
+      containerPath: '/keys',
+      sourceVolume: 'jwt-keys',
+      readOnly: true,
+    })
+    backendContainer.addContainerDependencies({
+      container: jwtKeysContainer,
+      condition: ecs.ContainerDependencyCondition.SUCCESS,
+    })
+
     // ECS Service
     this.backendService = new ecs.FargateService(this, 'BackendService', {
       cluster: this.cluster,
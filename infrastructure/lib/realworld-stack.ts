@@ -5,6 +5,7 @@ import * as ecr from 'aws-cdk-lib/aws-ecr'
 import * as elbv2 from 'aws-cdk-lib/aws-elasticloadbalancingv2'
 import * as iam from 'aws-cdk-lib/aws-iam'
 import * as logs from 'aws-cdk-lib/aws-logs'
+import * as secretsmanager from 'aws-cdk-lib/aws-secretsmanager'
 import * as cloudfront from 'aws-cdk-lib/aws-cloudfront'
 import * as cloudfrontOrigins from 'aws-cdk-lib/aws-cloudfront-origins'
 import { Construct } from 'constructs'
@@ -116,6 +117,36 @@ export class RealWorldStack extends cdk.Stack {
       // No task role needed for SQLite-based app
     })
 
+    // JWT signing key: the PEM written by `go run ./cmd/jwt-keygen`, stored
+    // in Secrets Manager under realworld/jwt-signing-key
+    const jwtSigningKeyId = this.node.tryGetContext('jwtSigningKeyId') || 'realworld'
+    const jwtSigningKey = secretsmanager.Secret.fromSecretNameV2(this, 'JwtSigningKey', 'realworld/jwt-signing-key')
+
+    // Fargate cannot mount secrets as files, so an init container writes the
+    // key into a task volume that the backend reads as JWT_KEYS_DIR
+    taskDefinition.addVolume({ name: 'jwt-keys' })
+    const jwtKeysContainer = taskDefinition.addContainer('jwt-keys', {
+      image: ecs.ContainerImage.fromRegistry('public.ecr.aws/docker/library/busybox:stable'),
+      essential: false,
+      environment: {
+        JWT_SIGNING_KEY_ID: jwtSigningKeyId,
+      },
+      secrets: {
+        JWT_SIGNING_KEY: ecs.Secret.fromSecretsManager(jwtSigningKey),
+      },
+      // The backend image runs as appuser (uid 1001)
+      command: ['sh', '-c', 'umask 077 && printf "%s\\n" "$JWT_SIGNING_KEY" > "/keys/$JWT_SIGNING_KEY_ID.pem" && chown -R 1001:1001 /keys'],
+      logging: ecs.LogDrivers.awsLogs({
+        streamPrefix: 'jwt-keys',
+        logGroup,
+      }),
+    })
+    jwtKeysContainer.addMountPoints({
+      containerPath: '/keys',
+      sourceVolume: 'jwt-keys',
+      readOnly: false,
+    })
+
     // Backend Container
     const backendContainer = taskDefinition.addContainer('backend', {
       image: ecs.ContainerImage.fromEcrRepository(this.backendRepository, 'latest'),
@@ -124,7 +155,9 @@ export class RealWorldStack extends cdk.Stack {
       environment: {
         PORT: '8080',
         DATABASE_URL: '/data/realworld.db', // SQLite file path
-        JWT_SECRET: 'educational-jwt-secret-change-in-prod', // Simple secret for education
+        JWT_KEYS_DIR: '/keys',
+        JWT_SIGNING_KEY_ID: jwtSigningKeyId,
+        TRUSTED_PROXIES: vpc.vpcCidrBlock, // The load balancer's X-Forwarded-For
         ENVIRONMENT: 'production',
       },
       logging: ecs.LogDrivers.awsLogs({
@@ -146,6 +179,17 @@ export class RealWorldStack extends cdk.Stack {
       protocol: ecs.Protocol.TCP,
     })
 
+    // Read the signing key once the init container has written it
+    backendContainer.addMountPoints({
+      containerPath: '/keys',
+      sourceVolume: 'jwt-keys',
+      readOnly: true,
+    })
+    backendContainer.addContainerDependencies({
+      container: jwtKeysContainer,
+      condition: ecs.ContainerDependencyCondition.SUCCESS,
+    })
+
     // Application Load Balancer
     this.loadBalancer = new elbv2.ApplicationLoadBalancer(this, 'LoadBalancer', {
       vpc,
@@ -150,7 +150,7 @@ run_tests() {
     # Run backend tests
     log_info "Running backend tests..."
     cd backend
-    go test ./... || {
+    go test -tags sqlite_fts5 ./... || {
         log_error "Backend tests failed!"
         exit 1
     }
@@ -69,31 +69,24 @@ docker push ${ECR_REGISTRY}/${ECR_REPO}:initial
 docker push ${ECR_REGISTRY}/${ECR_REPO}:latest
 echo -e "${GREEN}✅ 초기 이미지 푸시 완료${NC}"
 
-# 5. Secrets Manager 업데이트
-echo -e "\n${YELLOW}5. Secrets Manager 업데이트${NC}"
-SECRET_NAME="${ENVIRONMENT}/realworld/database"
+# 5. JWT 서명 키 시크릿 확인
+echo -e "\n${YELLOW}5. JWT 서명 키 시크릿 확인${NC}"
+JWT_KEY_SECRET_NAME="realworld/${ENVIRONMENT}/jwt-signing-key"
 
-# 현재 시크릿 값 가져오기
-CURRENT_SECRET=$(aws secretsmanager get-secret-value --secret-id ${SECRET_NAME} --region ${REGION} --query SecretString --output text)
-
-# JWT_SECRET 추가
-if echo "$CURRENT_SECRET" | jq -e '.jwt_secret' >/dev/null 2>&1; then
-    echo -e "${GREEN}✅ JWT_SECRET이 이미 존재합니다${NC}"
+if aws secretsmanager describe-secret --secret-id ${JWT_KEY_SECRET_NAME} --region ${REGION} >/dev/null 2>&1; then
+    echo -e "${GREEN}✅ JWT 서명 키가 이미 존재합니다${NC}"
 else
-    echo -e "${YELLOW}🔐 JWT_SECRET 추가 중...${NC}"
-    # 임시 JWT 시크릿 생성
-    JWT_SECRET=$(openssl rand -base64 32)
-    
-    # 기존 시크릿에 jwt_secret 추가
-    UPDATED_SECRET=$(echo "$CURRENT_SECRET" | jq --arg jwt "$JWT_SECRET" '. + {jwt_secret: $jwt}')
-    
-    # 시크릿 업데이트
-    aws secretsmanager update-secret \
-        --secret-id ${SECRET_NAME} \
-        --secret-string "$UPDATED_SECRET" \
+    echo -e "${YELLOW}🔐 JWT 서명 키 생성 중...${NC}"
+    KEY_DIR=$(mktemp -d)
+    (cd backend && go run ./cmd/jwt-keygen -dir "${KEY_DIR}/keys" -kid realworld)
+
+    aws secretsmanager create-secret \
+        --name ${JWT_KEY_SECRET_NAME} \
+        --secret-string "file://${KEY_DIR}/keys/realworld.pem" \
         --region ${REGION}
-    
-    echo -e "${GREEN}✅ JWT_SECRET 추가 완료${NC}"
+    rm -rf "${KEY_DIR}"
+
+    echo -e "${GREEN}✅ JWT 서명 키 생성 완료${NC}"
 fi
 
 # 6. 현재 서비스 상태 확인